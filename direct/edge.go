@@ -0,0 +1,58 @@
+package direct
+
+type LWWEdge interface {
+	// the tail of the edge
+	GetFrom() LWWVertex
+	// the head of the edge
+	GetTo() LWWVertex
+	GetTimestamp() int64
+	SetTimestamp(int64) int64
+	GetReplica() ReplicaID
+}
+
+type LWWEdgeImpl struct {
+	from      LWWVertex
+	to        LWWVertex
+	timestamp int64
+	replica   ReplicaID
+}
+
+func NewLWWEdgeImpl(from, to LWWVertex, clock Clock, replica ReplicaID) LWWEdge {
+	return &LWWEdgeImpl{
+		from:      from,
+		to:        to,
+		timestamp: clock.Now().UnixNano(),
+		replica:   replica,
+	}
+}
+
+func (edge *LWWEdgeImpl) GetFrom() LWWVertex {
+	return edge.from
+}
+
+func (edge *LWWEdgeImpl) GetTo() LWWVertex {
+	return edge.to
+}
+
+func (edge *LWWEdgeImpl) GetTimestamp() int64 {
+	return edge.timestamp
+}
+
+func (edge *LWWEdgeImpl) SetTimestamp(t int64) int64 {
+	edge.timestamp = t
+	return edge.timestamp
+}
+
+func (edge *LWWEdgeImpl) GetReplica() ReplicaID {
+	return edge.replica
+}
+
+// copyEdge return a copy of the record of the edge along with the vertices
+func copyEdge(edge LWWEdge) LWWEdge {
+	return &LWWEdgeImpl{
+		from:      copyVertex(edge.GetFrom()),
+		to:        copyVertex(edge.GetTo()),
+		timestamp: edge.GetTimestamp(),
+		replica:   edge.GetReplica(),
+	}
+}
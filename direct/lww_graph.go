@@ -0,0 +1,473 @@
+package direct
+
+import (
+	"sort"
+
+	"github.com/harrisin2037/lww_graph/undirect"
+)
+
+type LWWGraph interface {
+
+	// The function check if the vertex is exist in the graph or not.
+	// vertex exist when the vertex is:
+	//   - in the vertices list
+	//   - in vertices list and not in tombstone vertices list
+	//   - in both list but the record from vertices list is after
+	//   - in both list and no difference in terms of order but with adds bias
+	IsVertexExist(value VertexValue) bool
+	// AddVertex check if the record exist, if existed then will udpate the timestamp
+	// of existing record to prevent lost of the relations of the edges.
+	AddVertex(value VertexValue) LWWVertex
+	// It get the vertex when it exist in terms of LWW aspect. Related to IsVertexExist
+	GetVertex(value VertexValue) LWWVertex
+	// It remove all of the edges from and to the vertex and the vertex itself if it exist
+	RemoveVertex(value VertexValue)
+
+	// It add the edge from the tail to the head when the vertices are not the same vertex,
+	// the vertices will be added when they are not exist
+	AddEdge(from, to LWWVertex) LWWEdge
+	// It return the edge from the tail to the head when it exist
+	GetEdge(from, to VertexValue) LWWEdge
+	// It return the edges that start from the provided vertex
+	GetOutEdges(value VertexValue) []LWWEdge
+	// It return the edges that end at the provided vertex
+	GetInEdges(value VertexValue) []LWWEdge
+	// It return the vertices that the provided vertex has edges to
+	GetSuccessors(value VertexValue) []LWWVertex
+	// It return the vertices that have edges to the provided vertex
+	GetPredecessors(value VertexValue) []LWWVertex
+	// it search through the matrix by the DFS function and get all of the paths
+	// from start to end following the direction of the edges
+	GetPaths(start, end VertexValue) [][]VertexValue
+	// it update the tombstone of the edge from the tail to the head if the vertices exist
+	RemoveEdgeByVertices(from, to VertexValue)
+
+	// it merge the other graph when the component is after the local one
+	Merge(other LWWGraph)
+	// get the successors of every vertex
+	GetAdjacencyVerticesList() map[VertexValue][]VertexValue
+	// get the predecessors of every vertex
+	GetReverseAdjacencyVerticesList() map[VertexValue][]VertexValue
+	// The function check if the component is exist in the graph or not logically by
+	// the order of the records, which is the timestamp then the replica id
+	IsComponentExist(add, remove Component) bool
+	// retrieve the graph bias
+	GetBias() Bias
+	// retrieve the graph clock
+	GetClock() Clock
+	// retrieve the id of the replica
+	GetReplica() ReplicaID
+	// retrieve the graph vertices
+	GetVertices() map[VertexValue]LWWVertex
+	// retrieve the graph tombstone vertices list
+	GetTombstoneVertices() map[VertexValue]LWWVertex
+	// retrieve the graph edge matrix, the rows are the tails and the columns are the heads
+	GetEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge
+	// retrieve the graph edge tombstone matrix
+	GetTombstoneEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge
+}
+
+type LWWGraphImpl struct {
+	clock                Clock
+	bias                 Bias
+	replica              ReplicaID
+	vertices             map[VertexValue]LWWVertex
+	tombstoneVertices    map[VertexValue]LWWVertex
+	edgesMatrix          map[VertexValue]map[VertexValue]LWWEdge
+	tombstoneEdgesMatrix map[VertexValue]map[VertexValue]LWWEdge
+}
+
+// NewLWWGraph return the directed graph of the replica, the replica id is stamped
+// on every record written by the graph, so the replicas should have different ids
+func NewLWWGraph(bias Bias, clockImpl Clock, replica ReplicaID) LWWGraph {
+	if bias != Adds && bias != Removal {
+		bias = Adds
+	}
+	if clockImpl == nil {
+		clockImpl = &clock{}
+	}
+	return &LWWGraphImpl{
+		clock:                clockImpl,
+		bias:                 bias,
+		replica:              replica,
+		vertices:             make(map[VertexValue]LWWVertex),
+		tombstoneVertices:    make(map[VertexValue]LWWVertex),
+		edgesMatrix:          make(map[VertexValue]map[VertexValue]LWWEdge),
+		tombstoneEdgesMatrix: make(map[VertexValue]map[VertexValue]LWWEdge),
+	}
+}
+
+func (graph *LWWGraphImpl) AddVertex(value VertexValue) LWWVertex {
+
+	vertex := NewLWWVertex(value, graph.clock, graph.replica)
+
+	if graph.IsVertexExist(value) {
+		existing := graph.vertices[value]
+		// the existing record might be merged from the replica of which the clock is ahead,
+		// it is kept then, as moving it back might put it before the tombstone
+		if CompareComponents(existing, vertex) < 0 {
+			existing.SetTimestamp(vertex.GetTimestamp())
+			existing.SetReplica(vertex.GetReplica())
+		}
+		return existing
+	}
+
+	graph.vertices[value] = vertex
+
+	return vertex
+}
+
+func (graph *LWWGraphImpl) IsVertexExist(value VertexValue) bool {
+
+	v, ok := graph.vertices[value]
+	if !ok || v == nil {
+		return false
+	}
+
+	tv, ok := graph.tombstoneVertices[value]
+	if !ok || tv == nil {
+		return true
+	}
+
+	return graph.IsComponentExist(v, tv)
+}
+
+func (graph *LWWGraphImpl) IsComponentExist(add, remove Component) bool {
+
+	order := CompareComponents(add, remove)
+
+	switch graph.bias {
+	case Removal:
+		// when adds record is after removal record, it exists
+		return order > 0
+	default: // Adds
+		// when adds record is after or the same as removal record, it exists
+		return order >= 0
+	}
+}
+
+func (graph *LWWGraphImpl) isEdgeExist(from, to VertexValue) bool {
+
+	if !graph.IsVertexExist(from) || !graph.IsVertexExist(to) {
+		return false
+	}
+
+	edge, ok := graph.edgesMatrix[from][to]
+	if !ok || edge == nil {
+		return false
+	}
+
+	tombstoneEdge, ok := graph.tombstoneEdgesMatrix[from][to]
+	if !ok || tombstoneEdge == nil {
+		return true
+	}
+
+	return graph.IsComponentExist(edge, tombstoneEdge)
+}
+
+func (graph *LWWGraphImpl) GetVertex(value VertexValue) LWWVertex {
+
+	if graph.IsVertexExist(value) {
+		return graph.vertices[value]
+	}
+
+	return nil
+}
+
+func (graph *LWWGraphImpl) RemoveVertex(value VertexValue) {
+
+	// same as the undirected graph, the vertex is only removed when it exist locally,
+	// and the edges from and to the vertex are removed along with it
+	if !graph.IsVertexExist(value) {
+		return
+	}
+
+	out := graph.GetOutEdges(value)
+	in := graph.GetInEdges(value)
+
+	graph.tombstoneVertices[value] = NewLWWVertex(value, graph.clock, graph.replica)
+
+	for _, edge := range append(out, in...) {
+		from, to := edge.GetFrom(), edge.GetTo()
+		setEdge(graph.tombstoneEdgesMatrix, from.GetValue(), to.GetValue(), NewLWWEdgeImpl(from, to, graph.clock, graph.replica))
+	}
+}
+
+func (graph *LWWGraphImpl) AddEdge(from, to LWWVertex) LWWEdge {
+
+	if from.GetValue().IsEqual(to.GetValue()) {
+		return nil
+	}
+
+	if !graph.IsVertexExist(from.GetValue()) {
+		from = graph.AddVertex(from.GetValue())
+	}
+
+	if !graph.IsVertexExist(to.GetValue()) {
+		to = graph.AddVertex(to.GetValue())
+	}
+
+	edge := NewLWWEdgeImpl(from, to, graph.clock, graph.replica)
+
+	setEdge(graph.edgesMatrix, from.GetValue(), to.GetValue(), edge)
+	delete(graph.tombstoneEdgesMatrix[from.GetValue()], to.GetValue())
+
+	return edge
+}
+
+func (graph *LWWGraphImpl) GetEdge(from, to VertexValue) LWWEdge {
+
+	if !graph.isEdgeExist(from, to) {
+		return nil
+	}
+
+	return graph.edgesMatrix[from][to]
+}
+
+func (graph *LWWGraphImpl) GetOutEdges(value VertexValue) []LWWEdge {
+
+	if !graph.IsVertexExist(value) {
+		return nil
+	}
+
+	successors := graph.GetAdjacencyVerticesList()[value]
+	if len(successors) == 0 {
+		return nil
+	}
+
+	edges := []LWWEdge{}
+	for _, to := range successors {
+		edges = append(edges, graph.edgesMatrix[value][to])
+	}
+
+	return edges
+}
+
+func (graph *LWWGraphImpl) GetInEdges(value VertexValue) []LWWEdge {
+
+	if !graph.IsVertexExist(value) {
+		return nil
+	}
+
+	predecessors := graph.GetReverseAdjacencyVerticesList()[value]
+	if len(predecessors) == 0 {
+		return nil
+	}
+
+	edges := []LWWEdge{}
+	for _, from := range predecessors {
+		edges = append(edges, graph.edgesMatrix[from][value])
+	}
+
+	return edges
+}
+
+func (graph *LWWGraphImpl) GetSuccessors(value VertexValue) []LWWVertex {
+
+	if !graph.IsVertexExist(value) {
+		return nil
+	}
+
+	vertices := []LWWVertex{}
+	for _, v := range graph.GetAdjacencyVerticesList()[value] {
+		vertices = append(vertices, graph.GetVertex(v))
+	}
+
+	return vertices
+}
+
+func (graph *LWWGraphImpl) GetPredecessors(value VertexValue) []LWWVertex {
+
+	if !graph.IsVertexExist(value) {
+		return nil
+	}
+
+	vertices := []LWWVertex{}
+	for _, v := range graph.GetReverseAdjacencyVerticesList()[value] {
+		vertices = append(vertices, graph.GetVertex(v))
+	}
+
+	return vertices
+}
+
+func (graph *LWWGraphImpl) GetPaths(start, end VertexValue) [][]VertexValue {
+	dfs := graph.NewDFS(start, end)
+	return dfs.Search()
+}
+
+func (graph *LWWGraphImpl) RemoveEdgeByVertices(from, to VertexValue) {
+
+	if from.IsEqual(to) {
+		return
+	}
+
+	vertexFrom := graph.GetVertex(from)
+	vertexTo := graph.GetVertex(to)
+
+	if vertexFrom == nil || vertexTo == nil {
+		return
+	}
+
+	// the tombstone is written even when the edge has not been added, as the edge might be
+	// added by the other replica before the removal, and the record is not merged yet
+	edge := NewLWWEdgeImpl(vertexFrom, vertexTo, graph.clock, graph.replica)
+
+	if te, ok := graph.tombstoneEdgesMatrix[from][to]; !ok || te == nil || CompareComponents(te, edge) < 0 {
+		setEdge(graph.tombstoneEdgesMatrix, from, to, edge)
+	}
+}
+
+func (graph *LWWGraphImpl) Merge(other LWWGraph) {
+	// let the clock know the latest write of the other replica, so that the
+	// following local writes are ordered after the merged state
+	if clock, ok := graph.clock.(undirect.ObservingClock); ok {
+		clock.Observe(latestTimestamp(other))
+	}
+	graph.vertices = mergeVertices(graph.vertices, other.GetVertices())
+	graph.tombstoneVertices = mergeVertices(graph.tombstoneVertices, other.GetTombstoneVertices())
+	graph.edgesMatrix = mergeEdgesMatrix(graph.edgesMatrix, other.GetEdgesMatrix())
+	graph.tombstoneEdgesMatrix = mergeEdgesMatrix(graph.tombstoneEdgesMatrix, other.GetTombstoneEdgesMatrix())
+}
+
+// latestTimestamp return the greatest timestamp of the components of the graph
+func latestTimestamp(graph LWWGraph) int64 {
+
+	var latest int64
+
+	for _, vertices := range []map[VertexValue]LWWVertex{graph.GetVertices(), graph.GetTombstoneVertices()} {
+		for _, v := range vertices {
+			if v != nil && v.GetTimestamp() > latest {
+				latest = v.GetTimestamp()
+			}
+		}
+	}
+
+	for _, matrix := range []map[VertexValue]map[VertexValue]LWWEdge{graph.GetEdgesMatrix(), graph.GetTombstoneEdgesMatrix()} {
+		for m := range matrix {
+			for _, e := range matrix[m] {
+				if e != nil && e.GetTimestamp() > latest {
+					latest = e.GetTimestamp()
+				}
+			}
+		}
+	}
+
+	return latest
+}
+
+// mergeVertices merge the copies of the records into source when they are after the
+// records of source, as the records of the graph are updated in place by AddVertex
+func mergeVertices(source, mergeWith map[VertexValue]LWWVertex) map[VertexValue]LWWVertex {
+
+	for k, v := range mergeWith {
+		if v == nil {
+			continue
+		}
+		if current, ok := source[k]; !ok || current == nil || CompareComponents(current, v) < 0 {
+			source[k] = copyVertex(v)
+		}
+	}
+
+	return source
+}
+
+// mergeEdgesMatrix merge the copies of the records into source when they are after
+// the records of source
+func mergeEdgesMatrix(source, mergeWith map[VertexValue]map[VertexValue]LWWEdge) map[VertexValue]map[VertexValue]LWWEdge {
+
+	for m := range mergeWith {
+		for n, e := range mergeWith[m] {
+			if e == nil {
+				continue
+			}
+			if current, ok := source[m][n]; !ok || current == nil || CompareComponents(current, e) < 0 {
+				setEdge(source, m, n, copyEdge(e))
+			}
+		}
+	}
+
+	return source
+}
+
+// setEdge set the edge of the matrix cell and create the row when it is not exist
+func setEdge(matrix map[VertexValue]map[VertexValue]LWWEdge, from, to VertexValue, edge LWWEdge) {
+	if _, ok := matrix[from]; !ok || matrix[from] == nil {
+		matrix[from] = make(map[VertexValue]LWWEdge)
+	}
+	matrix[from][to] = edge
+}
+
+func (graph *LWWGraphImpl) GetAdjacencyVerticesList() map[VertexValue][]VertexValue {
+	return graph.adjacencyVerticesList(false)
+}
+
+func (graph *LWWGraphImpl) GetReverseAdjacencyVerticesList() map[VertexValue][]VertexValue {
+	return graph.adjacencyVerticesList(true)
+}
+
+// adjacencyVerticesList return the successors of every existing vertex,
+// or the predecessors when it is reversed
+func (graph *LWWGraphImpl) adjacencyVerticesList(reverse bool) map[VertexValue][]VertexValue {
+
+	dict := make(map[VertexValue][]VertexValue)
+
+	for k := range graph.vertices {
+		if !graph.IsVertexExist(k) {
+			continue
+		}
+		dict[k] = []VertexValue{}
+	}
+
+	if len(dict) == 0 {
+		return nil
+	}
+
+	for from := range graph.edgesMatrix {
+		for to := range graph.edgesMatrix[from] {
+			if !graph.isEdgeExist(from, to) {
+				continue
+			}
+			if reverse {
+				dict[to] = append(dict[to], from)
+			} else {
+				dict[from] = append(dict[from], to)
+			}
+		}
+	}
+
+	for k := range dict {
+		sort.Slice(dict[k], func(i, j int) bool {
+			return string(dict[k][i]) < string(dict[k][j])
+		})
+	}
+
+	return dict
+}
+
+func (graph *LWWGraphImpl) GetBias() Bias {
+	return graph.bias
+}
+
+func (graph *LWWGraphImpl) GetClock() Clock {
+	return graph.clock
+}
+
+func (graph *LWWGraphImpl) GetReplica() ReplicaID {
+	return graph.replica
+}
+
+func (graph *LWWGraphImpl) GetVertices() map[VertexValue]LWWVertex {
+	return graph.vertices
+}
+
+func (graph *LWWGraphImpl) GetTombstoneVertices() map[VertexValue]LWWVertex {
+	return graph.tombstoneVertices
+}
+
+func (graph *LWWGraphImpl) GetEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
+	return graph.edgesMatrix
+}
+
+func (graph *LWWGraphImpl) GetTombstoneEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
+	return graph.tombstoneEdgesMatrix
+}
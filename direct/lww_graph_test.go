@@ -0,0 +1,540 @@
+package direct
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// test clock use for mock the time for unit test and return a unix time that start with same timestamp
+type testClock struct {
+	elapsed time.Duration
+}
+
+func (tc *testClock) Now() time.Time { return time.Unix(0, 0).Add(tc.elapsed) }
+
+type mockFields struct {
+	bias            Bias
+	clock           Clock
+	verticesPaths   [][]VertexValue
+	removedVertices []VertexValue
+}
+
+// NewMockGraph add the edges along every path, from the first vertex to the last one
+func NewMockGraph(fields mockFields) LWWGraph {
+
+	graph := NewLWWGraph(fields.bias, fields.clock, "")
+
+	for i := 0; i < len(fields.verticesPaths); i++ {
+		path := fields.verticesPaths[i]
+		if len(path) == 1 {
+			graph.AddVertex(path[0])
+			continue
+		}
+		for j := 1; j < len(path); j++ {
+			graph.AddEdge(NewLWWVertex(path[j-1], graph.GetClock(), ""), NewLWWVertex(path[j], graph.GetClock(), ""))
+		}
+	}
+
+	for i := 0; i < len(fields.removedVertices); i++ {
+		graph.RemoveVertex(fields.removedVertices[i])
+	}
+
+	return graph
+}
+
+func values(vertices []LWWVertex) []VertexValue {
+	got := []VertexValue{}
+	for i := 0; i < len(vertices); i++ {
+		got = append(got, vertices[i].GetValue())
+	}
+	return got
+}
+
+func edgeValues(edges []LWWEdge) [][]VertexValue {
+	got := [][]VertexValue{}
+	for i := 0; i < len(edges); i++ {
+		got = append(got, []VertexValue{edges[i].GetFrom().GetValue(), edges[i].GetTo().GetValue()})
+	}
+	return got
+}
+
+// Check is the graph associative for merge
+func TestLWWGraphImpl_Merge_Check_Associative(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+		A -> B
+		A -> C
+	*/
+	graphXMock := mockFields{bias: Adds, verticesPaths: [][]VertexValue{{A, B}, {A, C}}, clock: &testClock{}}
+	/*
+		D -> C
+		E -> D
+	*/
+	graphYMock := mockFields{bias: Adds, verticesPaths: [][]VertexValue{{D, C}, {E, D}}, clock: &testClock{}}
+	/*
+		B -> C -> B
+		B -> D
+	*/
+	graphZMock := mockFields{bias: Adds, verticesPaths: [][]VertexValue{{B, C, B}, {B, D}}, clock: &testClock{}, removedVertices: []VertexValue{E}}
+
+	graphXLeft := NewMockGraph(graphXMock)
+	graphYLeft := NewMockGraph(graphYMock)
+	graphZLeft := NewMockGraph(graphZMock)
+
+	graphXRight := NewMockGraph(graphXMock)
+	graphYRight := NewMockGraph(graphYMock)
+	graphZRight := NewMockGraph(graphZMock)
+
+	// (X U Y) U Z
+	graphXLeft.Merge(graphYLeft)
+	graphXLeft.Merge(graphZLeft)
+
+	// X U (Y U Z)
+	graphYRight.Merge(graphZRight)
+	graphXRight.Merge(graphYRight)
+
+	gotLeft := graphXLeft.GetAdjacencyVerticesList()
+	gotRight := graphXRight.GetAdjacencyVerticesList()
+
+	if !reflect.DeepEqual(gotLeft, gotRight) {
+		t.Errorf("LWWGraphImpl.TestLWWGraphImpl_Merge_Check_Associative(): got %v, want %v, diff: %v", gotLeft, gotRight, deep.Equal(gotLeft, gotRight))
+	}
+}
+
+// Check is the graph commutative for merge
+func TestLWWGraphImpl_Merge_Check_Commutative(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	/*
+		A -> B
+		A -> C
+	*/
+	graphXMock := mockFields{bias: Adds, verticesPaths: [][]VertexValue{{A, B}, {A, C}}, clock: &testClock{}}
+	/*
+		B -> A
+		D -> C
+	*/
+	graphYMock := mockFields{bias: Adds, verticesPaths: [][]VertexValue{{B, A}, {D, C}}, clock: &testClock{}}
+
+	graphXLeft := NewMockGraph(graphXMock)
+	graphYLeft := NewMockGraph(graphYMock)
+
+	graphXRight := NewMockGraph(graphXMock)
+	graphYRight := NewMockGraph(graphYMock)
+
+	// X U Y
+	graphXLeft.Merge(graphYLeft)
+	// Y U X
+	graphYRight.Merge(graphXRight)
+
+	gotLeft := graphXLeft.GetAdjacencyVerticesList()
+	gotRight := graphYRight.GetAdjacencyVerticesList()
+
+	if !reflect.DeepEqual(gotLeft, gotRight) {
+		t.Errorf("LWWGraphImpl.TestLWWGraphImpl_Merge_Check_Commutative(): got %v, want %v, diff: %v", gotLeft, gotRight, deep.Equal(gotLeft, gotRight))
+	}
+}
+
+// Check is the graph Idempotent for merge
+func TestLWWGraphImpl_Merge_Check_Idempotent(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	/*
+		A -> B -> C
+	*/
+	graphXMock := mockFields{bias: Adds, verticesPaths: [][]VertexValue{{A, B, C}}, clock: &testClock{}}
+
+	graphX := NewMockGraph(graphXMock)
+
+	graphX1 := NewMockGraph(graphXMock)
+	graphX2 := NewMockGraph(graphXMock)
+
+	// X U X
+	graphX1.Merge(graphX2)
+
+	gotLeft := graphX.GetAdjacencyVerticesList()
+	gotRight := graphX1.GetAdjacencyVerticesList()
+
+	if !reflect.DeepEqual(gotLeft, gotRight) {
+		t.Errorf("LWWGraphImpl.TestLWWGraphImpl_Merge_Check_Idempotent(): got %v, want %v, diff: %v", gotLeft, gotRight, deep.Equal(gotLeft, gotRight))
+	}
+}
+
+func TestLWWGraphImpl_AddEdge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	graph := NewLWWGraph(Adds, nil, "")
+
+	if got := graph.AddEdge(NewLWWVertex(A, graph.GetClock(), ""), NewLWWVertex(A, graph.GetClock(), "")); got != nil {
+		t.Errorf("LWWGraphImpl.AddEdge() = %v, want nil for the same vertex", got)
+	}
+
+	edge := graph.AddEdge(NewLWWVertex(A, graph.GetClock(), ""), NewLWWVertex(B, graph.GetClock(), ""))
+	if edge == nil || edge.GetFrom().GetValue() != A || edge.GetTo().GetValue() != B {
+		t.Fatalf("LWWGraphImpl.AddEdge() = %v, want edge from %v to %v", edge, A, B)
+	}
+
+	if got := graph.GetEdge(A, B); got != edge {
+		t.Errorf("LWWGraphImpl.GetEdge() = %v, want %v", got, edge)
+	}
+
+	// the edge is asymmetric
+	if got := graph.GetEdge(B, A); got != nil {
+		t.Errorf("LWWGraphImpl.GetEdge() = %v, want nil for the reverse direction", got)
+	}
+
+	want := map[VertexValue][]VertexValue{A: {B}, B: {}}
+	if got := graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v", got, want)
+	}
+
+	want = map[VertexValue][]VertexValue{A: {}, B: {A}}
+	if got := graph.GetReverseAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetReverseAdjacencyVerticesList() = %v, want %v", got, want)
+	}
+}
+
+func TestLWWGraphImpl_GetEdges_And_Neighbours(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+		A -> B -> D -> E
+		|    |    ^
+		v    v    |
+		C ------- +
+	*/
+	paths := [][]VertexValue{{A, B, D, E}, {A, C, D}, {B, C}}
+
+	tests := []struct {
+		name             string
+		fields           mockFields
+		value            VertexValue
+		wantOut          [][]VertexValue
+		wantIn           [][]VertexValue
+		wantSuccessors   []VertexValue
+		wantPredecessors []VertexValue
+	}{
+		{
+			name:             "test source vertex",
+			fields:           mockFields{verticesPaths: paths, bias: Adds},
+			value:            A,
+			wantOut:          [][]VertexValue{{A, B}, {A, C}},
+			wantIn:           [][]VertexValue{},
+			wantSuccessors:   []VertexValue{B, C},
+			wantPredecessors: []VertexValue{},
+		},
+		{
+			name:             "test inner vertex",
+			fields:           mockFields{verticesPaths: paths, bias: Adds},
+			value:            D,
+			wantOut:          [][]VertexValue{{D, E}},
+			wantIn:           [][]VertexValue{{B, D}, {C, D}},
+			wantSuccessors:   []VertexValue{E},
+			wantPredecessors: []VertexValue{B, C},
+		},
+		{
+			name:             "test inner vertex with removed predecessor",
+			fields:           mockFields{verticesPaths: paths, bias: Adds, removedVertices: []VertexValue{B}},
+			value:            D,
+			wantOut:          [][]VertexValue{{D, E}},
+			wantIn:           [][]VertexValue{{C, D}},
+			wantSuccessors:   []VertexValue{E},
+			wantPredecessors: []VertexValue{C},
+		},
+		{
+			name:             "test removed vertex",
+			fields:           mockFields{verticesPaths: paths, bias: Adds, removedVertices: []VertexValue{D}},
+			value:            D,
+			wantOut:          [][]VertexValue{},
+			wantIn:           [][]VertexValue{},
+			wantSuccessors:   []VertexValue{},
+			wantPredecessors: []VertexValue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph := NewMockGraph(tt.fields)
+
+			if got := edgeValues(graph.GetOutEdges(tt.value)); !reflect.DeepEqual(got, tt.wantOut) {
+				t.Errorf("LWWGraphImpl.GetOutEdges() = %v, want %v", got, tt.wantOut)
+			}
+			if got := edgeValues(graph.GetInEdges(tt.value)); !reflect.DeepEqual(got, tt.wantIn) {
+				t.Errorf("LWWGraphImpl.GetInEdges() = %v, want %v", got, tt.wantIn)
+			}
+			if got := values(graph.GetSuccessors(tt.value)); !reflect.DeepEqual(got, tt.wantSuccessors) {
+				t.Errorf("LWWGraphImpl.GetSuccessors() = %v, want %v", got, tt.wantSuccessors)
+			}
+			if got := values(graph.GetPredecessors(tt.value)); !reflect.DeepEqual(got, tt.wantPredecessors) {
+				t.Errorf("LWWGraphImpl.GetPredecessors() = %v, want %v", got, tt.wantPredecessors)
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_GetPaths(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+		A -> B -> D -> E
+		|    |    ^
+		v    v    |
+		C ------- +
+	*/
+	paths := [][]VertexValue{{A, B, D, E}, {A, C, D}, {B, C}}
+
+	tests := []struct {
+		name   string
+		fields mockFields
+		start  VertexValue
+		end    VertexValue
+		want   [][]VertexValue
+	}{
+		{
+			name:   "test get path without edges",
+			fields: mockFields{verticesPaths: [][]VertexValue{{A}, {E}}, bias: Adds},
+			start:  A,
+			end:    E,
+			want:   [][]VertexValue{},
+		},
+		{
+			name:   "test get path following the direction",
+			fields: mockFields{verticesPaths: paths, bias: Adds},
+			start:  A,
+			end:    E,
+			want: [][]VertexValue{
+				{A, B, C, D, E},
+				{A, B, D, E},
+				{A, C, D, E},
+			},
+		},
+		{
+			name:   "test get path against the direction",
+			fields: mockFields{verticesPaths: paths, bias: Adds},
+			start:  E,
+			end:    A,
+			want:   [][]VertexValue{},
+		},
+		{
+			name:   "test get path with removed vertex",
+			fields: mockFields{verticesPaths: paths, bias: Adds, removedVertices: []VertexValue{C}},
+			start:  A,
+			end:    E,
+			want: [][]VertexValue{
+				{A, B, D, E},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := NewMockGraph(tt.fields)
+			if got := graph.GetPaths(tt.start, tt.end); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.GetPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_RemoveEdgeByVertices(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	/*
+		A <-> B
+	*/
+	graph := NewMockGraph(mockFields{verticesPaths: [][]VertexValue{{A, B, A}}, bias: Adds})
+
+	graph.RemoveEdgeByVertices(A, B)
+
+	want := map[VertexValue][]VertexValue{A: {}, B: {A}}
+	if got := graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+}
+
+func TestLWWGraphImpl_Merge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	type operation func(graph LWWGraph, clock *testClock)
+
+	at := func(d time.Duration, op func(graph LWWGraph)) operation {
+		return func(graph LWWGraph, clock *testClock) {
+			clock.elapsed = d
+			op(graph)
+		}
+	}
+	addEdge := func(from, to VertexValue) func(graph LWWGraph) {
+		return func(graph LWWGraph) {
+			graph.AddEdge(NewLWWVertex(from, graph.GetClock(), ""), NewLWWVertex(to, graph.GetClock(), ""))
+		}
+	}
+	removeEdge := func(from, to VertexValue) func(graph LWWGraph) {
+		return func(graph LWWGraph) { graph.RemoveEdgeByVertices(from, to) }
+	}
+	addVertex := func(v VertexValue) func(graph LWWGraph) {
+		return func(graph LWWGraph) { graph.AddVertex(v) }
+	}
+	removeVertex := func(v VertexValue) func(graph LWWGraph) {
+		return func(graph LWWGraph) { graph.RemoveVertex(v) }
+	}
+
+	tests := []struct {
+		name string
+		bias Bias
+		x    []operation
+		y    []operation
+		want map[VertexValue][]VertexValue
+	}{
+		{
+			name: "merge edges of both directions",
+			bias: Adds,
+			x:    []operation{at(time.Minute, addEdge(A, B))},
+			y:    []operation{at(time.Minute, addEdge(B, A))},
+			want: map[VertexValue][]VertexValue{A: {B}, B: {A}},
+		},
+		{
+			name: "merge with later removed edge",
+			bias: Adds,
+			x:    []operation{at(time.Minute, addEdge(A, B))},
+			y:    []operation{at(time.Minute, addEdge(A, B)), at(2*time.Minute, removeEdge(A, B))},
+			want: map[VertexValue][]VertexValue{A: {}, B: {}},
+		},
+		{
+			name: "merge with removed edge of the other direction",
+			bias: Adds,
+			x:    []operation{at(time.Minute, addEdge(A, B))},
+			y:    []operation{at(time.Minute, addEdge(B, A)), at(2*time.Minute, removeEdge(B, A))},
+			want: map[VertexValue][]VertexValue{A: {B}, B: {}},
+		},
+		{
+			name: "merge with removed edge at same timestamp with adds bias",
+			bias: Adds,
+			x:    []operation{at(time.Minute, addEdge(A, B))},
+			y:    []operation{at(time.Minute, addEdge(A, B)), at(time.Minute, removeEdge(A, B))},
+			want: map[VertexValue][]VertexValue{A: {B}, B: {}},
+		},
+		{
+			name: "merge with removed edge at same timestamp with removal bias",
+			bias: Removal,
+			x:    []operation{at(time.Minute, addEdge(A, B))},
+			y:    []operation{at(time.Minute, addEdge(A, B)), at(time.Minute, removeEdge(A, B))},
+			want: map[VertexValue][]VertexValue{A: {}, B: {}},
+		},
+		{
+			name: "merge with removed vertex",
+			bias: Adds,
+			x:    []operation{at(time.Minute, addEdge(A, B))},
+			y:    []operation{at(time.Minute, addEdge(A, B)), at(2*time.Minute, removeVertex(B))},
+			want: map[VertexValue][]VertexValue{A: {}},
+		},
+		{
+			name: "merge with removed edge that is not merged yet",
+			bias: Adds,
+			x:    []operation{at(0, addVertex(A)), at(0, addVertex(B)), at(2*time.Minute, removeEdge(A, B))},
+			y:    []operation{at(0, addVertex(A)), at(0, addVertex(B)), at(time.Minute, addEdge(A, B))},
+			want: map[VertexValue][]VertexValue{A: {}, B: {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			xClock, yClock := &testClock{}, &testClock{}
+			xGraph := NewLWWGraph(tt.bias, xClock, "")
+			yGraph := NewLWWGraph(tt.bias, yClock, "")
+
+			for _, op := range tt.x {
+				op(xGraph, xClock)
+			}
+			for _, op := range tt.y {
+				op(yGraph, yClock)
+			}
+
+			xGraph.Merge(yGraph)
+
+			if got := xGraph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, tt.want, deep.Equal(got, tt.want))
+			}
+		})
+	}
+}
+
+// Check the records merged are copied, so the writes of the graph do not change the
+// records of the other graph
+func TestLWWGraphImpl_Merge_Copy_Records(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	xClock, yClock := &testClock{}, &testClock{elapsed: time.Minute}
+	x := NewLWWGraph(Adds, xClock, "x")
+	y := NewLWWGraph(Adds, yClock, "y")
+	y.AddEdge(NewLWWVertex(A, yClock, "y"), NewLWWVertex(B, yClock, "y"))
+
+	x.Merge(y)
+	xClock.elapsed = 2 * time.Minute
+	x.AddVertex(A)
+
+	vertex := y.GetVertices()[A]
+	if vertex.GetTimestamp() != yClock.Now().UnixNano() || vertex.GetReplica() != "y" {
+		t.Errorf("LWWGraphImpl.Merge() record of y = %v, %v, want %v, %v", vertex.GetTimestamp(), vertex.GetReplica(), yClock.Now().UnixNano(), "y")
+	}
+	if x.GetVertices()[A] == vertex || x.GetEdgesMatrix()[A][B] == y.GetEdgesMatrix()[A][B] {
+		t.Errorf("LWWGraphImpl.Merge() records are shared with the other graph")
+	}
+}
+
+// Check adding the vertex again does not move the record merged from the replica of
+// which the clock is ahead back before the tombstone
+func TestLWWGraphImpl_AddVertex_After_Merge_From_Clock_Ahead(t *testing.T) {
+
+	A := NewVertexValue("A")
+
+	xClock, yClock := &testClock{}, &testClock{elapsed: 3 * time.Minute}
+
+	x := NewLWWGraph(Adds, xClock, "x")
+	xClock.elapsed = 4 * time.Minute
+	x.AddVertex(A)
+	xClock.elapsed = 5 * time.Minute
+	x.RemoveVertex(A)
+	xClock.elapsed = 10 * time.Minute
+	x.AddVertex(A)
+
+	y := NewLWWGraph(Adds, yClock, "y")
+	y.Merge(x)
+	y.AddVertex(A)
+
+	if got := y.IsVertexExist(A); !got {
+		t.Errorf("LWWGraphImpl.IsVertexExist() = %v, want %v", got, true)
+	}
+}
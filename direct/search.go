@@ -0,0 +1,61 @@
+package direct
+
+type DFS struct {
+	start, end VertexValue
+	marked     map[VertexValue]bool
+	dict       map[VertexValue][]VertexValue
+}
+
+// NewDFS return the search that follows the direction of the edges, from the tail to the head
+func (graph *LWWGraphImpl) NewDFS(start, end VertexValue) *DFS {
+	return &DFS{
+		start, end,
+		make(map[VertexValue]bool),
+		graph.GetAdjacencyVerticesList(),
+	}
+}
+
+func (dfs *DFS) Search() [][]VertexValue {
+
+	var (
+		current = []VertexValue{dfs.start}
+		result  = [][]VertexValue{}
+	)
+
+	if _, ok := dfs.dict[dfs.start]; !ok {
+		return result
+	}
+
+	dfs.marked[dfs.start] = true
+
+	return *dfs.search(current, &result)
+}
+
+func (dfs *DFS) search(current []VertexValue, result *[][]VertexValue) *[][]VertexValue {
+
+	last := current[len(current)-1]
+
+	if last.IsEqual(dfs.end) {
+		path := make([]VertexValue, len(current))
+		copy(path, current)
+		*result = append(*result, path)
+		return result
+	}
+
+	for i := 0; i < len(dfs.dict[last]); i++ {
+
+		if exist, ok := dfs.marked[dfs.dict[last][i]]; ok && exist {
+			continue
+		}
+
+		current = append(current, dfs.dict[last][i])
+		dfs.marked[dfs.dict[last][i]] = true
+
+		dfs.search(current, result)
+		dfs.marked[dfs.dict[last][i]] = false
+
+		current = current[:len(current)-1]
+	}
+
+	return result
+}
@@ -0,0 +1,44 @@
+package direct
+
+import (
+	"time"
+
+	"github.com/harrisin2037/lww_graph/undirect"
+)
+
+// The vertices, clocks and the order of the records are the same as the undirected
+// graph, only the edges and the graph itself are aware of the direction.
+type (
+	Bias        = undirect.Bias
+	Clock       = undirect.Clock
+	VertexValue = undirect.VertexValue
+	ReplicaID   = undirect.ReplicaID
+	Component   = undirect.Component
+	LWWVertex   = undirect.LWWVertex
+)
+
+const (
+	Adds    = undirect.Adds
+	Removal = undirect.Removal
+)
+
+func NewVertexValue(v string) VertexValue {
+	return undirect.NewVertexValue(v)
+}
+
+func NewLWWVertex(value VertexValue, clock Clock, replica ReplicaID) LWWVertex {
+	return undirect.NewLWWVertex(value, clock, replica)
+}
+
+// copyVertex return a copy of the record of the vertex
+func copyVertex(vertex LWWVertex) LWWVertex {
+	return undirect.CopyVertex(vertex)
+}
+
+func CompareComponents(a, b Component) int {
+	return undirect.CompareComponents(a, b)
+}
+
+type clock struct{}
+
+func (c *clock) Now() time.Time { return time.Now() }
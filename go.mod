@@ -1,5 +1,5 @@
 module github.com/harrisin2037/lww_graph
 
-go 1.14
+go 1.18
 
 require github.com/go-test/deep v1.0.7
@@ -0,0 +1,129 @@
+package undirect
+
+import (
+	"sort"
+)
+
+// Bridges return the edges of which the removal disconnects the component of them, every
+// edge is the pair of the vertices of which the first is the lesser, and the edges are sorted
+func (graph *LWWGraphImplOf[ID]) Bridges() [][2]ID {
+
+	bridges := [][2]ID{}
+
+	graph.lowLink(func(parent, child ID, low, order int) {
+		if low > order {
+			bridges = append(bridges, graph.pair(parent, child))
+		}
+	}, nil)
+
+	sort.Slice(bridges, func(i, j int) bool {
+		return graph.lessPair(bridges[i], bridges[j])
+	})
+
+	return bridges
+}
+
+// ArticulationPoints return the sorted vertices of which the removal disconnects the
+// component of them
+func (graph *LWWGraphImplOf[ID]) ArticulationPoints() []ID {
+
+	points := map[ID]bool{}
+
+	graph.lowLink(func(parent, child ID, low, order int) {
+		if low >= order {
+			points[parent] = true
+		}
+	}, func(root ID, children int) {
+		// the root is the articulation point only when it has more than one child,
+		// every child of the root is reported by the low link of it
+		if children < 2 {
+			delete(points, root)
+		}
+	})
+
+	arr := make([]ID, 0, len(points))
+	for v := range points {
+		arr = append(arr, v)
+	}
+
+	sort.Slice(arr, func(i, j int) bool {
+		return graph.less(arr[i], arr[j])
+	})
+
+	return arr
+}
+
+// lowLink go through the DFS tree of every component by Tarjan, and call child for every
+// edge of the tree after the subtree of the child is finished, with the lowest order reachable
+// from the subtree of the child by at most one edge not in the tree, and the order of the
+// parent. root is called after the tree of the root is finished with the number of the
+// children of the root. The DFS is iterative, so the deep graphs do not grow the stack.
+func (graph *LWWGraphImplOf[ID]) lowLink(child func(parent, child ID, low, order int), root func(root ID, children int)) {
+
+	adjacency := graph.GetAdjacencyVerticesList()
+
+	order := make(map[ID]int, len(adjacency))
+	low := make(map[ID]int, len(adjacency))
+	parent := make(map[ID]ID, len(adjacency))
+	next := make(map[ID]int, len(adjacency))
+
+	counter := 0
+
+	for _, r := range graph.sortedVertices(adjacency) {
+
+		if _, ok := order[r]; ok {
+			continue
+		}
+
+		order[r], low[r] = counter, counter
+		counter++
+		children := 0
+
+		stack := []ID{r}
+
+		for len(stack) > 0 {
+
+			current := stack[len(stack)-1]
+
+			if next[current] < len(adjacency[current]) {
+
+				n := adjacency[current][next[current]]
+				next[current]++
+
+				if _, ok := order[n]; !ok {
+					parent[n] = current
+					order[n], low[n] = counter, counter
+					counter++
+					if current == r {
+						children++
+					}
+					stack = append(stack, n)
+					continue
+				}
+
+				// the edge to the parent is the edge of the tree, the graph has no parallel edges
+				if current == r || n != parent[current] {
+					if order[n] < low[current] {
+						low[current] = order[n]
+					}
+				}
+				continue
+			}
+
+			stack = stack[:len(stack)-1]
+			if current == r {
+				continue
+			}
+
+			p := parent[current]
+			if low[current] < low[p] {
+				low[p] = low[current]
+			}
+			child(p, current, low[current], order[p])
+		}
+
+		if root != nil {
+			root(r, children)
+		}
+	}
+}
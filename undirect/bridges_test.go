@@ -0,0 +1,251 @@
+package undirect
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLWWGraphImpl_Bridges(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+	F := NewVertexValue("F")
+	G := NewVertexValue("G")
+
+	/*
+	   A - B - E - F   G
+	   |   |    \ /
+	   D - C     -
+	*/
+	edges := []weightedEdge{
+		{A, B, -1},
+		{B, C, -1},
+		{C, D, -1},
+		{D, A, -1},
+		{B, E, -1},
+		{E, F, -1},
+		{F, G, -1},
+	}
+
+	tests := []struct {
+		name                   string
+		fields                 weightedMockFields
+		wantBridges            [][2]VertexValue
+		wantArticulationPoints []VertexValue
+	}{
+		{
+			name:                   "test the path out of the cycle",
+			fields:                 weightedMockFields{bias: Adds, edges: edges},
+			wantBridges:            [][2]VertexValue{{B, E}, {E, F}, {F, G}},
+			wantArticulationPoints: []VertexValue{B, E, F},
+		},
+		{
+			name:                   "test the cycle broken by the removed edge",
+			fields:                 weightedMockFields{bias: Adds, edges: edges, removedEdges: [][2]VertexValue{{D, A}}},
+			wantBridges:            [][2]VertexValue{{A, B}, {B, C}, {B, E}, {C, D}, {E, F}, {F, G}},
+			wantArticulationPoints: []VertexValue{B, C, E, F},
+		},
+		{
+			name:                   "test the removed vertex",
+			fields:                 weightedMockFields{bias: Removal, edges: edges, removedVertices: []VertexValue{B}},
+			wantBridges:            [][2]VertexValue{{A, D}, {C, D}, {E, F}, {F, G}},
+			wantArticulationPoints: []VertexValue{D, F},
+		},
+		{
+			name: "test the components without bridges",
+			fields: weightedMockFields{bias: Adds, edges: []weightedEdge{
+				{A, B, -1},
+				{B, C, -1},
+				{C, A, -1},
+				{D, E, -1},
+				{E, F, -1},
+				{F, D, -1},
+			}},
+			wantBridges:            [][2]VertexValue{},
+			wantArticulationPoints: []VertexValue{},
+		},
+		{
+			name: "test the cycles joined by the vertex",
+			fields: weightedMockFields{bias: Adds, edges: []weightedEdge{
+				{A, B, -1},
+				{B, C, -1},
+				{C, A, -1},
+				{C, D, -1},
+				{D, E, -1},
+				{E, C, -1},
+			}},
+			wantBridges:            [][2]VertexValue{},
+			wantArticulationPoints: []VertexValue{C},
+		},
+		{
+			name:                   "test the graph without edges",
+			fields:                 weightedMockFields{bias: Adds},
+			wantBridges:            [][2]VertexValue{},
+			wantArticulationPoints: []VertexValue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph, _ := NewWeightedMockGraph(tt.fields, "")
+
+			if got := graph.Bridges(); !reflect.DeepEqual(got, tt.wantBridges) {
+				t.Errorf("LWWGraphImpl.Bridges() = %v, want %v", got, tt.wantBridges)
+			}
+			if got := graph.ArticulationPoints(); !reflect.DeepEqual(got, tt.wantArticulationPoints) {
+				t.Errorf("LWWGraphImpl.ArticulationPoints() = %v, want %v", got, tt.wantArticulationPoints)
+			}
+		})
+	}
+}
+
+// Check the bridges are made by the removal merged, and closed by the edge merged
+func TestLWWGraphImpl_Bridges_Merge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	/*
+	   A - B
+	   |   |
+	   D - C
+	*/
+	square := []weightedEdge{{A, B, -1}, {B, C, -1}, {C, D, -1}, {D, A, -1}}
+
+	x, xClock := NewWeightedMockGraph(weightedMockFields{bias: Adds, edges: square}, "x")
+	y, yClock := NewWeightedMockGraph(weightedMockFields{bias: Adds}, "y")
+	y.Merge(x)
+	yClock.Now()
+	yClock.SyncWith(xClock)
+
+	if got, want := y.Bridges(), [][2]VertexValue{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.Bridges() = %v, want %v", got, want)
+	}
+
+	// the removal of the vertex leaves the path, of which every edge is the bridge
+	yClock.AddDuration(time.Second)
+	y.RemoveVertex(D)
+	x.Merge(y)
+
+	if got, want := x.Bridges(), [][2]VertexValue{{A, B}, {B, C}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.Bridges() = %v, want %v", got, want)
+	}
+	if got, want := x.ArticulationPoints(), []VertexValue{B}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.ArticulationPoints() = %v, want %v", got, want)
+	}
+
+	// the edge added on the other replica closes the cycle again
+	xClock.SyncWith(yClock)
+	xClock.AddDuration(time.Second)
+	x.AddEdge(NewLWWVertex(A, xClock, "x"), NewLWWVertex(C, xClock, "x"))
+	y.Merge(x)
+
+	for _, graph := range []LWWGraph{x, y} {
+		if got, want := graph.Bridges(), [][2]VertexValue{}; !reflect.DeepEqual(got, want) {
+			t.Errorf("LWWGraphImpl.Bridges() of %v = %v, want %v", graph.GetReplica(), got, want)
+		}
+		if got, want := graph.ArticulationPoints(), []VertexValue{}; !reflect.DeepEqual(got, want) {
+			t.Errorf("LWWGraphImpl.ArticulationPoints() of %v = %v, want %v", graph.GetReplica(), got, want)
+		}
+	}
+}
+
+// Check the bridges and the articulation points against the components counted without
+// every edge and every vertex after every random operation
+func TestLWWGraphImpl_Bridges_Random_Operations(t *testing.T) {
+
+	for _, bias := range []Bias{Adds, Removal} {
+
+		r := rand.New(rand.NewSource(int64(bias) + 18))
+		replicas := newRandomReplicas(bias, "x", "y")
+		values := newRandomValues(8)
+
+		for step := 0; step < 300; step++ {
+
+			graph, op := applyRandomOperation(r, replicas, values)
+
+			adjacency := graph.GetAdjacencyVerticesList()
+			components := countComponents(adjacency, "", [2]VertexValue{})
+
+			wantBridges := [][2]VertexValue{}
+			for _, m := range graph.(*LWWGraphImpl).sortedVertices(adjacency) {
+				for _, n := range adjacency[m] {
+					if string(m) < string(n) && countComponents(adjacency, "", [2]VertexValue{m, n}) > components {
+						wantBridges = append(wantBridges, [2]VertexValue{m, n})
+					}
+				}
+			}
+
+			wantPoints := []VertexValue{}
+			for _, v := range graph.(*LWWGraphImpl).sortedVertices(adjacency) {
+				// the vertex removed takes the component of it away when it is alone
+				alone := 0
+				if len(adjacency[v]) == 0 {
+					alone = 1
+				}
+				if countComponents(adjacency, v, [2]VertexValue{}) > components-alone {
+					wantPoints = append(wantPoints, v)
+				}
+			}
+
+			if got := graph.Bridges(); !reflect.DeepEqual(got, wantBridges) {
+				t.Fatalf("step %v %v: LWWGraphImpl.Bridges() = %v, want %v", step, op, got, wantBridges)
+			}
+			if got := graph.ArticulationPoints(); !reflect.DeepEqual(got, wantPoints) {
+				t.Fatalf("step %v %v: LWWGraphImpl.ArticulationPoints() = %v, want %v", step, op, got, wantPoints)
+			}
+		}
+
+		// the replicas find the same bridges after they exchange the records
+		for _, graph := range replicas {
+			for _, other := range replicas {
+				graph.Merge(other.Delta(math.MinInt64))
+			}
+		}
+		for _, graph := range replicas[1:] {
+			if got, want := graph.Bridges(), replicas[0].Bridges(); !reflect.DeepEqual(got, want) {
+				t.Errorf("LWWGraphImpl.Bridges() of %v = %v, want %v", graph.GetReplica(), got, want)
+			}
+			if got, want := graph.ArticulationPoints(), replicas[0].ArticulationPoints(); !reflect.DeepEqual(got, want) {
+				t.Errorf("LWWGraphImpl.ArticulationPoints() of %v = %v, want %v", graph.GetReplica(), got, want)
+			}
+		}
+	}
+}
+
+// countComponents count the components of the adjacency without the vertex and the edge
+func countComponents(adjacency map[VertexValue][]VertexValue, vertex VertexValue, edge [2]VertexValue) int {
+
+	visited := map[VertexValue]bool{vertex: true}
+	count := 0
+
+	for v := range adjacency {
+		if visited[v] {
+			continue
+		}
+		count++
+		visited[v] = true
+		stack := []VertexValue{v}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, n := range adjacency[current] {
+				if visited[n] || EdgeOwner(current, n).Vertices == edge {
+					continue
+				}
+				visited[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+
+	return count
+}
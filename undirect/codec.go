@@ -0,0 +1,224 @@
+package undirect
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// The binary formats are made of frames, a frame is the length of the payload as uvarint,
+// the payload, then the crc32 of the payload, so the readers can tell a frame that was not
+// completely written or was changed from a valid one.
+
+// maxFrameSize limits the memory allocated for a frame when the length is corrupted
+const maxFrameSize = 1 << 24
+
+var (
+	errFrameTruncated = errors.New("frame truncated")
+	errFrameCorrupt   = errors.New("frame corrupt")
+)
+
+func writeFrame(w io.Writer, payload []byte) error {
+	_, err := w.Write(appendFrame(make([]byte, 0, binary.MaxVarintLen64+len(payload)+4), payload))
+	return err
+}
+
+// appendFrame append the frame of the payload to buf
+func appendFrame(buf []byte, payload []byte) []byte {
+
+	buf = appendUvarint(buf, uint64(len(payload)))
+	buf = append(buf, payload...)
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(payload))
+
+	return append(buf, sum[:]...)
+}
+
+type frameReader struct {
+	r *bufio.Reader
+	// offset is the offset of the next frame
+	offset int64
+}
+
+func newFrameReader(r io.Reader, offset int64) *frameReader {
+	return &frameReader{r: bufio.NewReader(r), offset: offset}
+}
+
+// tail return true when there is no more data after the frame read last, so the corrupt
+// frame is the last one, which might be written partly by the crash
+func (fr *frameReader) tail() bool {
+	_, err := fr.r.Peek(1)
+	return err == io.EOF
+}
+
+// next return the payload of the next frame, io.EOF is returned only when there is no
+// more data at the beginning of the frame
+func (fr *frameReader) next() ([]byte, error) {
+
+	var (
+		size  uint64
+		shift uint
+		read  int64
+	)
+
+	for {
+		b, err := fr.r.ReadByte()
+		if err == io.EOF && read == 0 {
+			return nil, io.EOF
+		}
+		if err == io.EOF {
+			return nil, errFrameTruncated
+		}
+		if err != nil {
+			return nil, err
+		}
+		read++
+		if read > binary.MaxVarintLen64 {
+			return nil, errFrameCorrupt
+		}
+		size |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+		shift += 7
+	}
+
+	if size > maxFrameSize {
+		return nil, errFrameCorrupt
+	}
+
+	buf := make([]byte, size+4)
+	if _, err := io.ReadFull(fr.r, buf); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, errFrameTruncated
+	} else if err != nil {
+		return nil, err
+	}
+
+	payload := buf[:size]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(buf[size:]) {
+		return nil, errFrameCorrupt
+	}
+
+	fr.offset += read + int64(size) + 4
+
+	return payload, nil
+}
+
+// payloadDecoder read the values from the payload of a frame, any value out of
+// the payload is reported as errFrameCorrupt, as the frame itself is complete
+type payloadDecoder struct {
+	buf []byte
+	pos int
+}
+
+func (d *payloadDecoder) byte() (byte, error) {
+	if d.pos >= len(d.buf) {
+		return 0, errFrameCorrupt
+	}
+	d.pos++
+	return d.buf[d.pos-1], nil
+}
+
+func (d *payloadDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf[d.pos:])
+	if n <= 0 {
+		return 0, errFrameCorrupt
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *payloadDecoder) varint() (int64, error) {
+	v, n := binary.Varint(d.buf[d.pos:])
+	if n <= 0 {
+		return 0, errFrameCorrupt
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *payloadDecoder) string() (string, error) {
+	size, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if size > uint64(len(d.buf)-d.pos) {
+		return "", errFrameCorrupt
+	}
+	d.pos += int(size)
+	return string(d.buf[d.pos-int(size) : d.pos]), nil
+}
+
+func (d *payloadDecoder) float() (float64, error) {
+	if len(d.buf)-d.pos < 8 {
+		return 0, errFrameCorrupt
+	}
+	d.pos += 8
+	return math.Float64frombits(binary.BigEndian.Uint64(d.buf[d.pos-8:])), nil
+}
+
+// weight read the weight of the edge written by appendWeight, nil is returned for the
+// edge without weight
+func (d *payloadDecoder) weight() (*float64, error) {
+
+	flag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch flag {
+	case 0:
+		return nil, nil
+	case 1:
+		weight, err := d.float()
+		if err != nil {
+			return nil, err
+		}
+		return &weight, nil
+	default:
+		return nil, errFrameCorrupt
+	}
+}
+
+// done check the whole payload is read
+func (d *payloadDecoder) done() error {
+	if d.pos != len(d.buf) {
+		return errFrameCorrupt
+	}
+	return nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutUvarint(tmp[:], v)]...)
+}
+
+func appendVarint(buf []byte, v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutVarint(tmp[:], v)]...)
+}
+
+func appendFloat(buf []byte, f float64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], math.Float64bits(f))
+	return append(buf, tmp[:]...)
+}
+
+// appendWeight append the flag of the weight of the edge, followed by the weight when the
+// edge has the weight
+func appendWeight(buf []byte, edge LWWEdge) []byte {
+	weight, ok := edge.GetWeight()
+	if !ok {
+		return append(buf, 0)
+	}
+	return appendFloat(append(buf, 1), weight)
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
@@ -0,0 +1,74 @@
+package undirect
+
+import (
+	"sort"
+)
+
+// The components are found through the neighbours of the index, so the removed vertices
+// are not in any component, and the removed edges do not connect the components.
+
+// ConnectedComponents return the vertices of every connected component, the vertices of
+// the component are sorted, and the components are sorted by the first vertex
+func (graph *LWWGraphImplOf[ID]) ConnectedComponents() [][]ID {
+
+	components := [][]ID{}
+	visited := make(map[ID]bool, len(graph.index))
+
+	for v := range graph.index {
+		if visited[v] {
+			continue
+		}
+		components = append(components, graph.component(v, visited))
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return graph.less(components[i][0], components[j][0])
+	})
+
+	return components
+}
+
+// ComponentOf return the sorted vertices of the connected component of the vertex,
+// nil is returned when the vertex does not exist
+func (graph *LWWGraphImplOf[ID]) ComponentOf(value ID) []ID {
+
+	if _, ok := graph.index[value]; !ok {
+		return nil
+	}
+
+	return graph.component(value, make(map[ID]bool))
+}
+
+// IsReachable check if there is a path between the vertices
+func (graph *LWWGraphImplOf[ID]) IsReachable(v1, v2 ID) bool {
+
+	if _, ok := graph.index[v2]; !ok {
+		return false
+	}
+
+	_, found := graph.bfs(v1, v2)
+	return found
+}
+
+// component return the sorted vertices reachable from the vertex, and mark them visited
+func (graph *LWWGraphImplOf[ID]) component(value ID, visited map[ID]bool) []ID {
+
+	visited[value] = true
+	component := []ID{value}
+
+	for i := 0; i < len(component); i++ {
+		for n := range graph.index[component[i]] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			component = append(component, n)
+		}
+	}
+
+	sort.Slice(component, func(i, j int) bool {
+		return graph.less(component[i], component[j])
+	})
+
+	return component
+}
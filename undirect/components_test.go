@@ -0,0 +1,200 @@
+package undirect
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLWWGraphImpl_ConnectedComponents(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+	F := NewVertexValue("F")
+
+	/*
+	   A - B - C   E - F
+	    \     /
+	       D
+	*/
+	edges := []weightedEdge{
+		{A, B, -1},
+		{B, C, -1},
+		{C, D, -1},
+		{D, A, -1},
+		{E, F, -1},
+	}
+
+	tests := []struct {
+		name              string
+		fields            weightedMockFields
+		want              [][]VertexValue
+		wantComponentOfA  []VertexValue
+		wantReachableAToC bool
+	}{
+		{
+			name:              "test components",
+			fields:            weightedMockFields{bias: Adds, edges: edges},
+			want:              [][]VertexValue{{A, B, C, D}, {E, F}},
+			wantComponentOfA:  []VertexValue{A, B, C, D},
+			wantReachableAToC: true,
+		},
+		{
+			name:              "test component kept by the cycle with a removed edge",
+			fields:            weightedMockFields{bias: Adds, edges: edges, removedEdges: [][2]VertexValue{{B, C}}},
+			want:              [][]VertexValue{{A, B, C, D}, {E, F}},
+			wantComponentOfA:  []VertexValue{A, B, C, D},
+			wantReachableAToC: true,
+		},
+		{
+			name:              "test component split by the removed edges",
+			fields:            weightedMockFields{bias: Adds, edges: edges, removedEdges: [][2]VertexValue{{B, C}, {D, A}}},
+			want:              [][]VertexValue{{A, B}, {C, D}, {E, F}},
+			wantComponentOfA:  []VertexValue{A, B},
+			wantReachableAToC: false,
+		},
+		{
+			name:              "test component split by the removed vertices",
+			fields:            weightedMockFields{bias: Removal, edges: edges, removedVertices: []VertexValue{B, D}},
+			want:              [][]VertexValue{{A}, {C}, {E, F}},
+			wantComponentOfA:  []VertexValue{A},
+			wantReachableAToC: false,
+		},
+		{
+			name:              "test the removed vertex is not a component",
+			fields:            weightedMockFields{bias: Adds, edges: edges, removedVertices: []VertexValue{A, E, F}},
+			want:              [][]VertexValue{{B, C, D}},
+			wantComponentOfA:  nil,
+			wantReachableAToC: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph, _ := NewWeightedMockGraph(tt.fields, "")
+
+			if got := graph.ConnectedComponents(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.ConnectedComponents() = %v, want %v", got, tt.want)
+			}
+			if got := graph.ComponentOf(A); !reflect.DeepEqual(got, tt.wantComponentOfA) {
+				t.Errorf("LWWGraphImpl.ComponentOf() = %v, want %v", got, tt.wantComponentOfA)
+			}
+			if got := graph.IsReachable(A, C); got != tt.wantReachableAToC {
+				t.Errorf("LWWGraphImpl.IsReachable() = %v, want %v", got, tt.wantReachableAToC)
+			}
+			if got := graph.IsReachable(C, A); got != tt.wantReachableAToC {
+				t.Errorf("LWWGraphImpl.IsReachable() of the reverse = %v, want %v", got, tt.wantReachableAToC)
+			}
+		})
+	}
+}
+
+// Check the components are joined by merge, and split by the removal merged
+func TestLWWGraphImpl_ConnectedComponents_Merge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	x, xClock := NewWeightedMockGraph(weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}}}, "x")
+	y, yClock := NewWeightedMockGraph(weightedMockFields{bias: Adds, edges: []weightedEdge{{C, D, -1}}}, "y")
+
+	if got, want := x.IsReachable(A, D), false; got != want {
+		t.Errorf("LWWGraphImpl.IsReachable() = %v, want %v", got, want)
+	}
+
+	yClock.AddDuration(time.Second)
+	y.AddEdge(NewLWWVertex(B, yClock, "y"), NewLWWVertex(C, yClock, "y"))
+	x.Merge(y)
+
+	if got, want := x.ConnectedComponents(), [][]VertexValue{{A, B, C, D}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.ConnectedComponents() = %v, want %v", got, want)
+	}
+	if got, want := x.IsReachable(A, D), true; got != want {
+		t.Errorf("LWWGraphImpl.IsReachable() = %v, want %v", got, want)
+	}
+
+	xClock.SyncWith(yClock)
+	xClock.AddDuration(time.Second)
+	x.RemoveVertex(C)
+	y.Merge(x)
+
+	if got, want := y.ConnectedComponents(), [][]VertexValue{{A, B}, {D}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.ConnectedComponents() = %v, want %v", got, want)
+	}
+	if got, want := y.IsReachable(A, D), false; got != want {
+		t.Errorf("LWWGraphImpl.IsReachable() = %v, want %v", got, want)
+	}
+}
+
+// Check the components are the partition of the existing vertices by the adjacency after
+// every random operation
+func TestLWWGraphImpl_ConnectedComponents_Random_Operations(t *testing.T) {
+
+	for _, bias := range []Bias{Adds, Removal} {
+
+		r := rand.New(rand.NewSource(int64(bias)))
+		replicas := newRandomReplicas(bias, "x", "y", "z")
+		values := newRandomValues(8)
+
+		for step := 0; step < 500; step++ {
+
+			graph, op := applyRandomOperation(r, replicas, values)
+
+			adjacency := graph.GetAdjacencyVerticesList()
+			components := graph.ConnectedComponents()
+
+			of := make(map[VertexValue]int)
+			for i, component := range components {
+				for _, v := range component {
+					if _, ok := of[v]; ok {
+						t.Fatalf("step %v %v: LWWGraphImpl.ConnectedComponents() = %v, %v is in more than one component", step, op, components, v)
+					}
+					of[v] = i
+				}
+				if got := graph.ComponentOf(component[0]); !reflect.DeepEqual(got, component) {
+					t.Fatalf("step %v %v: LWWGraphImpl.ComponentOf() = %v, want %v", step, op, got, component)
+				}
+			}
+
+			if len(of) != len(adjacency) {
+				t.Fatalf("step %v %v: LWWGraphImpl.ConnectedComponents() = %v, want the vertices of %v", step, op, components, adjacency)
+			}
+			for m, adj := range adjacency {
+				for _, n := range adj {
+					if of[m] != of[n] {
+						t.Fatalf("step %v %v: LWWGraphImpl.ConnectedComponents() = %v, %v and %v are connected", step, op, components, m, n)
+					}
+				}
+			}
+
+			v1, v2 := values[r.Intn(len(values))], values[r.Intn(len(values))]
+			i1, ok1 := of[v1]
+			i2, ok2 := of[v2]
+			if got, want := graph.IsReachable(v1, v2), ok1 && ok2 && i1 == i2; got != want {
+				t.Fatalf("step %v %v: LWWGraphImpl.IsReachable(%v, %v) = %v, want %v", step, op, v1, v2, got, want)
+			}
+			if _, got := graph.Distances(v1)[v2]; got != (ok1 && ok2 && i1 == i2) {
+				t.Fatalf("step %v %v: LWWGraphImpl.Distances(%v) has %v = %v, want %v", step, op, v1, v2, got, !got)
+			}
+		}
+
+		// the components of the replicas are the same after they exchange the records
+		for _, graph := range replicas {
+			for _, other := range replicas {
+				graph.Merge(other.Delta(math.MinInt64))
+			}
+		}
+		for _, graph := range replicas[1:] {
+			if got, want := graph.ConnectedComponents(), replicas[0].ConnectedComponents(); !reflect.DeepEqual(got, want) {
+				t.Errorf("LWWGraphImpl.ConnectedComponents() of %v = %v, want %v", graph.GetReplica(), got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package undirect
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// ConcurrentLWWGraphOf is the graph that can be used by multiple goroutines, the reads
+// share the read lock and the writes take the write lock, so merge is atomic for the
+// readers, they see the graph either before or after the whole merge.
+//
+// The records and the maps returned are copies, as the records of the graph are
+// updated in place by the following writes.
+type ConcurrentLWWGraphOf[ID comparable] struct {
+	mu    sync.RWMutex
+	graph LWWGraphOf[ID]
+}
+
+// ConcurrentLWWGraph is the concurrent graph of the string values
+type ConcurrentLWWGraph = ConcurrentLWWGraphOf[VertexValue]
+
+// NewConcurrentLWWGraphOf wrap the graph for the concurrent use, the graph should
+// not be used directly after it is wrapped, or the writes are not synchronized
+func NewConcurrentLWWGraphOf[ID comparable](graph LWWGraphOf[ID]) LWWGraphOf[ID] {
+	return &ConcurrentLWWGraphOf[ID]{graph: graph}
+}
+
+// NewConcurrentLWWGraph wrap the graph of the string values for the concurrent use
+func NewConcurrentLWWGraph(graph LWWGraph) LWWGraph {
+	return NewConcurrentLWWGraphOf(graph)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) IsVertexExist(value ID) bool {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.IsVertexExist(value)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) AddVertex(value ID) LWWVertexOf[ID] {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	return copyVertexOrNil(graph.graph.AddVertex(value))
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetVertex(value ID) LWWVertexOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyVertexOrNil(graph.graph.GetVertex(value))
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) RemoveVertex(value ID) {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	graph.graph.RemoveVertex(value)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) AddEdge(v1, v2 LWWVertexOf[ID]) LWWEdgeOf[ID] {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	return copyEdgeOrNil(graph.graph.AddEdge(v1, v2))
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) AddWeightedEdge(v1, v2 LWWVertexOf[ID], weight float64) LWWEdgeOf[ID] {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	return copyEdgeOrNil(graph.graph.AddWeightedEdge(v1, v2, weight))
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetEdge(v1, v2 ID) LWWEdgeOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyEdgeOrNil(graph.graph.GetEdge(v1, v2))
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetEdges(value ID) []LWWEdgeOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+
+	edges := graph.graph.GetEdges(value)
+	if edges == nil {
+		return nil
+	}
+
+	arr := make([]LWWEdgeOf[ID], 0, len(edges))
+	for _, e := range edges {
+		arr = append(arr, copyEdgeOrNil(e))
+	}
+
+	return arr
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetPaths(start, end ID) [][]ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetPaths(start, end)
+}
+
+// WalkPaths walk the copy of the graph, so the lock is not held while f runs, and f can
+// write to the graph, the writes are not seen by the walk
+func (graph *ConcurrentLWWGraphOf[ID]) WalkPaths(ctx context.Context, start, end ID, maxLength, maxResults int, f func(path []ID) bool) error {
+	return graph.snapshot().WalkPaths(ctx, start, end, maxLength, maxResults, f)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) ShortestPath(start, end ID) ([]ID, float64) {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.ShortestPath(start, end)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) ShortestPathHops(start, end ID) []ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.ShortestPathHops(start, end)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) Distances(from ID) map[ID]int {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.Distances(from)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) ConnectedComponents() [][]ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.ConnectedComponents()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) ComponentOf(value ID) []ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.ComponentOf(value)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) IsReachable(v1, v2 ID) bool {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.IsReachable(v1, v2)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) HasCycle() bool {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.HasCycle()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) FindCycle() []ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.FindCycle()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) SpanningForest(mode SpanningMode) [][2]ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.SpanningForest(mode)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) Bridges() [][2]ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.Bridges()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) ArticulationPoints() []ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.ArticulationPoints()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) RemoveEdgeByVertices(v1, v2 ID) {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	graph.graph.RemoveEdgeByVertices(v1, v2)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) SetVertexProperty(value ID, key, property string) LWWProperty {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	return graph.graph.SetVertexProperty(value, key, property)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) DeleteVertexProperty(value ID, key string) {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	graph.graph.DeleteVertexProperty(value, key)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetVertexProperty(value ID, key string) (string, bool) {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetVertexProperty(value, key)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetVertexProperties(value ID) map[string]string {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetVertexProperties(value)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) SetEdgeProperty(v1, v2 ID, key, property string) LWWProperty {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	return graph.graph.SetEdgeProperty(v1, v2, key, property)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) DeleteEdgeProperty(v1, v2 ID, key string) {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	graph.graph.DeleteEdgeProperty(v1, v2, key)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetEdgeProperty(v1, v2 ID, key string) (string, bool) {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetEdgeProperty(v1, v2, key)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetEdgeProperties(v1, v2 ID) map[string]string {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetEdgeProperties(v1, v2)
+}
+
+// Merge take the copy of the other graph before taking the write lock when the other
+// graph is concurrent as well, so the replicas merging each other at the same time
+// do not wait for the locks of each other
+func (graph *ConcurrentLWWGraphOf[ID]) Merge(other LWWGraphOf[ID]) {
+
+	if concurrent, ok := other.(*ConcurrentLWWGraphOf[ID]); ok {
+		other = concurrent.snapshot()
+	}
+
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	graph.graph.Merge(other)
+}
+
+// MergeWithReport take the copy of the other graph like Merge
+func (graph *ConcurrentLWWGraphOf[ID]) MergeWithReport(other LWWGraphOf[ID]) MergeReportOf[ID] {
+
+	if concurrent, ok := other.(*ConcurrentLWWGraphOf[ID]); ok {
+		other = concurrent.snapshot()
+	}
+
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	return graph.graph.MergeWithReport(other)
+}
+
+// snapshot return the copy of the whole graph
+func (graph *ConcurrentLWWGraphOf[ID]) snapshot() LWWGraphOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.Delta(math.MinInt64)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetAdjacencyVerticesList() map[ID][]ID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetAdjacencyVerticesList()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) IsComponentExist(add, remove Component) bool {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.IsComponentExist(add, remove)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetConnectedVertices(value ID) []LWWVertexOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+
+	vertices := graph.graph.GetConnectedVertices(value)
+	if vertices == nil {
+		return nil
+	}
+
+	arr := make([]LWWVertexOf[ID], 0, len(vertices))
+	for _, v := range vertices {
+		arr = append(arr, copyVertexOrNil(v))
+	}
+
+	return arr
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) Delta(since int64) LWWGraphOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.Delta(since)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GarbageCollect(stable int64) {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	graph.graph.GarbageCollect(stable)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetBias() Bias {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetBias()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetEdgeBias() Bias {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetEdgeBias()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetClock() Clock {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetClock()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetReplica() ReplicaID {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return graph.graph.GetReplica()
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetVertices() map[ID]LWWVertexOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyVertices(graph.graph.GetVertices())
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetTombstoneVertices() map[ID]LWWVertexOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyVertices(graph.graph.GetTombstoneVertices())
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetEdgesMatrix() map[ID]map[ID]LWWEdgeOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyEdgesMatrix(graph.graph.GetEdgesMatrix())
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetTombstoneEdgesMatrix() map[ID]map[ID]LWWEdgeOf[ID] {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyEdgesMatrix(graph.graph.GetTombstoneEdgesMatrix())
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetProperties() map[PropertyOwnerOf[ID]]map[string]LWWProperty {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyProperties(graph.graph.GetProperties())
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) GetVersions() map[PropertyOwnerOf[ID]]VersionVector {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return copyVersions(graph.graph.GetVersions())
+}
+
+func copyVertexOrNil[ID comparable](vertex LWWVertexOf[ID]) LWWVertexOf[ID] {
+	if vertex == nil {
+		return nil
+	}
+	return copyVertex(vertex)
+}
+
+func copyEdgeOrNil[ID comparable](edge LWWEdgeOf[ID]) LWWEdgeOf[ID] {
+	if edge == nil {
+		return nil
+	}
+	return copyEdge(edge)
+}
+
+func copyVertices[ID comparable](vertices map[ID]LWWVertexOf[ID]) map[ID]LWWVertexOf[ID] {
+
+	arr := make(map[ID]LWWVertexOf[ID], len(vertices))
+
+	for k, v := range vertices {
+		if v != nil {
+			arr[k] = copyVertex(v)
+		}
+	}
+
+	return arr
+}
+
+func copyEdgesMatrix[ID comparable](matrix map[ID]map[ID]LWWEdgeOf[ID]) map[ID]map[ID]LWWEdgeOf[ID] {
+
+	arr := make(map[ID]map[ID]LWWEdgeOf[ID], len(matrix))
+
+	for m := range matrix {
+		for n, e := range matrix[m] {
+			if e != nil {
+				setEdge(arr, m, n, copyEdge(e))
+			}
+		}
+	}
+
+	return arr
+}
+
+// copyProperties copy the maps of the registers only, as the registers are not updated in place
+func copyProperties[ID comparable](properties map[PropertyOwnerOf[ID]]map[string]LWWProperty) map[PropertyOwnerOf[ID]]map[string]LWWProperty {
+
+	arr := make(map[PropertyOwnerOf[ID]]map[string]LWWProperty, len(properties))
+
+	for owner := range properties {
+		for key, p := range properties[owner] {
+			if p == nil {
+				continue
+			}
+			if _, ok := arr[owner]; !ok {
+				arr[owner] = make(map[string]LWWProperty)
+			}
+			arr[owner][key] = p
+		}
+	}
+
+	return arr
+}
@@ -0,0 +1,224 @@
+package undirect
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// The tests of the concurrent graph are meant to be run with the race detector:
+//
+//	go test -race ./...
+
+func TestConcurrentLWWGraph_Stress(t *testing.T) {
+
+	const (
+		writers    = 4
+		readers    = 4
+		operations = 200
+	)
+
+	values := []VertexValue{}
+	for i := 0; i < 10; i++ {
+		values = append(values, NewVertexValue(fmt.Sprintf("V%v", i)))
+	}
+
+	x := NewConcurrentLWWGraph(NewLWWGraph(Adds, NewHLC(nil), "x"))
+	y := NewConcurrentLWWGraph(NewLWWGraph(Adds, NewHLC(nil), "y"))
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			graph, other := x, y
+			if w%2 == 1 {
+				graph, other = y, x
+			}
+			for i := 0; i < operations; i++ {
+				v1, v2 := values[(w+i)%len(values)], values[(w*3+i*7)%len(values)]
+				switch i % 5 {
+				case 0, 1:
+					graph.AddEdge(NewLWWVertex(v1, graph.GetClock(), graph.GetReplica()), NewLWWVertex(v2, graph.GetClock(), graph.GetReplica()))
+				case 2:
+					graph.RemoveEdgeByVertices(v1, v2)
+				case 3:
+					graph.RemoveVertex(v1)
+				case 4:
+					graph.Merge(other)
+				}
+			}
+		}(w)
+	}
+
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			graph := x
+			if r%2 == 1 {
+				graph = y
+			}
+			for i := 0; i < operations; i++ {
+				v1, v2 := values[(r+i)%len(values)], values[(r*5+i*3)%len(values)]
+				graph.GetPaths(v1, v2)
+				graph.GetAdjacencyVerticesList()
+				graph.GetConnectedVertices(v1)
+				for _, e := range graph.GetEdges(v1) {
+					e.GetVertices()[0].SetTimestamp(0)
+				}
+				for _, v := range graph.GetVertices() {
+					v.SetTimestamp(0)
+				}
+				for m := range graph.GetEdgesMatrix() {
+					for _, e := range graph.GetEdgesMatrix()[m] {
+						e.GetTimestamp()
+					}
+				}
+				graph.Delta(0)
+			}
+		}(r)
+	}
+
+	wg.Wait()
+
+	// the replicas converge when the writes are done
+	x.Merge(y)
+	y.Merge(x)
+
+	gotX := x.GetAdjacencyVerticesList()
+	gotY := y.GetAdjacencyVerticesList()
+	if !reflect.DeepEqual(gotX, gotY) {
+		t.Errorf("ConcurrentLWWGraph.Merge() replicas diverged, x: %v, y: %v, diff: %v", gotX, gotY, deep.Equal(gotX, gotY))
+	}
+}
+
+// Check the readers never see the graph in the middle of a merge
+func TestConcurrentLWWGraph_Merge_Atomic(t *testing.T) {
+
+	const size = 200
+
+	other := NewLWWGraph(Adds, NewHLC(nil), "y")
+	for i := 0; i < size; i++ {
+		other.AddEdge(
+			NewLWWVertex(NewVertexValue(fmt.Sprintf("V%v", i)), other.GetClock(), "y"),
+			NewLWWVertex(NewVertexValue(fmt.Sprintf("V%v", (i+1)%size)), other.GetClock(), "y"),
+		)
+	}
+
+	graph := NewConcurrentLWWGraph(NewLWWGraph(Adds, NewHLC(nil), "x"))
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				adj := graph.GetAdjacencyVerticesList()
+				if len(adj) != 0 && len(adj) != size {
+					t.Errorf("ConcurrentLWWGraph.GetAdjacencyVerticesList() during merge has %v vertices, want 0 or %v", len(adj), size)
+					return
+				}
+				for k, v := range adj {
+					if len(v) != 2 {
+						t.Errorf("ConcurrentLWWGraph.GetAdjacencyVerticesList() during merge has %v neighbours of %v, want 2", len(v), k)
+						return
+					}
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}()
+	}
+
+	graph.Merge(other)
+	close(done)
+	wg.Wait()
+
+	if got := len(graph.GetAdjacencyVerticesList()); got != size {
+		t.Errorf("ConcurrentLWWGraph.Merge() has %v vertices, want %v", got, size)
+	}
+}
+
+func TestConcurrentLWWGraph_Copies(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	clock := &testCkock{}
+	graph := NewConcurrentLWWGraph(NewLWWGraph(Adds, clock, ""))
+	graph.AddEdge(NewLWWVertex(A, clock, ""), NewLWWVertex(B, clock, ""))
+
+	want := graph.GetVertex(A).GetTimestamp()
+
+	// change the records returned, the records of the graph are not affected
+	graph.GetVertex(A).SetTimestamp(want + 1)
+	graph.GetVertices()[A].SetTimestamp(want + 1)
+	graph.GetEdge(A, B).SetTimestamp(want + 1)
+	graph.GetEdgesMatrix()[A][B].SetTimestamp(want + 1)
+	delete(graph.GetVertices(), A)
+	delete(graph.GetEdgesMatrix()[A], B)
+
+	if got := graph.GetVertex(A).GetTimestamp(); got != want {
+		t.Errorf("ConcurrentLWWGraph.GetVertex() timestamp = %v, want %v", got, want)
+	}
+	if got := graph.GetEdge(A, B).GetTimestamp(); got != want {
+		t.Errorf("ConcurrentLWWGraph.GetEdge() timestamp = %v, want %v", got, want)
+	}
+	if _, ok := graph.GetEdgesMatrix()[A][B]; !ok {
+		t.Errorf("ConcurrentLWWGraph.GetEdgesMatrix() does not have the edge of %v and %v", A, B)
+	}
+}
+
+// Check the callback of WalkPaths can write to the graph
+func TestConcurrentLWWGraph_WalkPaths_Write(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	clock := &testCkock{}
+	graph := NewConcurrentLWWGraph(NewLWWGraph(Adds, clock, ""))
+	graph.AddEdge(NewLWWVertex(A, clock, ""), NewLWWVertex(B, clock, ""))
+	graph.AddEdge(NewLWWVertex(B, clock, ""), NewLWWVertex(C, clock, ""))
+
+	got := [][]VertexValue{}
+	done := make(chan error)
+	go func() {
+		done <- graph.WalkPaths(context.Background(), A, C, 0, 0, func(path []VertexValue) bool {
+			got = append(got, path)
+			// the edge added is not seen by the walk
+			graph.AddEdge(graph.GetVertex(A), graph.GetVertex(C))
+			return true
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ConcurrentLWWGraph.WalkPaths() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("ConcurrentLWWGraph.WalkPaths() is blocked by the write of the callback")
+	}
+
+	if want := [][]VertexValue{{A, B, C}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ConcurrentLWWGraph.WalkPaths() = %v, want %v", got, want)
+	}
+	if graph.GetEdge(A, C) == nil {
+		t.Errorf("ConcurrentLWWGraph.GetEdge() of %v and %v = nil, want the edge added by the callback", A, C)
+	}
+}
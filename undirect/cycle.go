@@ -0,0 +1,223 @@
+package undirect
+
+import (
+	"sort"
+)
+
+// SpanningMode is the way the edges of the spanning forest are chosen
+type SpanningMode int
+
+const (
+	// BreadthFirst choose the edges by BFS from the least vertex of every component
+	BreadthFirst SpanningMode = 0
+	// MinimumWeight choose the edges of the least total weight by Kruskal, the edge
+	// without weight weighs 1
+	MinimumWeight SpanningMode = 1
+)
+
+// The cycles and the spanning forest are found on the adjacency of GetAdjacencyVerticesList,
+// which is the live view of the graph, and the vertices are visited by the order of the
+// values, so the same result is returned for the same graph. As the graph is undirected
+// and the edge of the vertex itself is not allowed, the cycle has at least three vertices.
+
+// HasCycle check if the graph is not a forest
+func (graph *LWWGraphImplOf[ID]) HasCycle() bool {
+	return graph.FindCycle() != nil
+}
+
+// FindCycle return the vertices of a cycle in the order of the cycle, the last vertex is
+// connected to the first one, nil is returned when there is no cycle
+func (graph *LWWGraphImplOf[ID]) FindCycle() []ID {
+
+	adjacency := graph.GetAdjacencyVerticesList()
+
+	parent := make(map[ID]ID, len(adjacency))
+	depth := make(map[ID]int, len(adjacency))
+
+	for _, root := range graph.sortedVertices(adjacency) {
+
+		if _, ok := depth[root]; ok {
+			continue
+		}
+		depth[root] = 0
+
+		// the DFS keeps the position of the next neighbour of every vertex of the stack
+		stack := []ID{root}
+		next := map[ID]int{}
+
+		for len(stack) > 0 {
+
+			current := stack[len(stack)-1]
+			if next[current] == len(adjacency[current]) {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			n := adjacency[current][next[current]]
+			next[current]++
+
+			if n == parent[current] && current != root {
+				continue
+			}
+
+			if _, ok := depth[n]; !ok {
+				parent[n] = current
+				depth[n] = depth[current] + 1
+				stack = append(stack, n)
+				continue
+			}
+
+			// the visited vertex which is not the parent is an ancestor on the stack,
+			// as the edges to the finished vertices are already followed by them
+			cycle := []ID{}
+			for v := current; v != n; v = parent[v] {
+				cycle = append(cycle, v)
+			}
+			cycle = append(cycle, n)
+
+			for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+				cycle[i], cycle[j] = cycle[j], cycle[i]
+			}
+
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// SpanningForest return the edges of the spanning tree of every connected component, every
+// edge is the pair of the vertices of which the first is the lesser, and the edges are sorted.
+// The edges of the graph that are not in the forest are the edges closing the cycles
+func (graph *LWWGraphImplOf[ID]) SpanningForest(mode SpanningMode) [][2]ID {
+
+	adjacency := graph.GetAdjacencyVerticesList()
+
+	var forest [][2]ID
+	if mode == MinimumWeight {
+		forest = graph.minimumSpanningForest(adjacency)
+	} else {
+		forest = graph.breadthFirstSpanningForest(adjacency)
+	}
+
+	sort.Slice(forest, func(i, j int) bool {
+		return graph.lessPair(forest[i], forest[j])
+	})
+
+	return forest
+}
+
+func (graph *LWWGraphImplOf[ID]) breadthFirstSpanningForest(adjacency map[ID][]ID) [][2]ID {
+
+	forest := [][2]ID{}
+	visited := make(map[ID]bool, len(adjacency))
+
+	for _, root := range graph.sortedVertices(adjacency) {
+
+		if visited[root] {
+			continue
+		}
+		visited[root] = true
+
+		queue := []ID{root}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, n := range adjacency[current] {
+				if visited[n] {
+					continue
+				}
+				visited[n] = true
+				forest = append(forest, graph.pair(current, n))
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	return forest
+}
+
+func (graph *LWWGraphImplOf[ID]) minimumSpanningForest(adjacency map[ID][]ID) [][2]ID {
+
+	type weightedPair struct {
+		pair   [2]ID
+		weight float64
+	}
+
+	edges := []weightedPair{}
+	for m, adj := range adjacency {
+		for _, n := range adj {
+			if graph.less(m, n) {
+				edges = append(edges, weightedPair{graph.pair(m, n), graph.edgeWeight(m, n)})
+			}
+		}
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].weight != edges[j].weight {
+			return edges[i].weight < edges[j].weight
+		}
+		return graph.lessPair(edges[i].pair, edges[j].pair)
+	})
+
+	// the union find of the vertices, the root of the set is the vertex of which the
+	// parent is itself
+	parent := make(map[ID]ID, len(adjacency))
+	for v := range adjacency {
+		parent[v] = v
+	}
+	find := func(v ID) ID {
+		for parent[v] != v {
+			parent[v] = parent[parent[v]]
+			v = parent[v]
+		}
+		return v
+	}
+
+	forest := [][2]ID{}
+	for _, e := range edges {
+		r1, r2 := find(e.pair[0]), find(e.pair[1])
+		if r1 == r2 {
+			continue
+		}
+		parent[r1] = r2
+		forest = append(forest, e.pair)
+	}
+
+	return forest
+}
+
+// sortedVertices return the vertices of the adjacency in the order of the values
+func (graph *LWWGraphImplOf[ID]) sortedVertices(adjacency map[ID][]ID) []ID {
+
+	arr := make([]ID, 0, len(adjacency))
+	for v := range adjacency {
+		arr = append(arr, v)
+	}
+
+	sort.Slice(arr, func(i, j int) bool {
+		return graph.less(arr[i], arr[j])
+	})
+
+	return arr
+}
+
+// pair return the pair of the vertices of which the first is the lesser
+func (graph *LWWGraphImplOf[ID]) pair(v1, v2 ID) [2]ID {
+	return orderedPair(graph.order, v1, v2)
+}
+
+// orderedPair return the pair of the vertices of which the first is the lesser by the order
+func orderedPair[ID comparable](order order[ID], v1, v2 ID) [2]ID {
+	if order.less(v2, v1) {
+		return [2]ID{v2, v1}
+	}
+	return [2]ID{v1, v2}
+}
+
+func (graph *LWWGraphImplOf[ID]) lessPair(p1, p2 [2]ID) bool {
+	if p1[0] != p2[0] {
+		return graph.less(p1[0], p2[0])
+	}
+	return graph.less(p1[1], p2[1])
+}
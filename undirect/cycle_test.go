@@ -0,0 +1,219 @@
+package undirect
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestLWWGraphImpl_FindCycle(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+	   A - B - E
+	   |   |
+	   D - C
+	*/
+	square := []weightedEdge{
+		{A, B, -1},
+		{B, C, -1},
+		{C, D, -1},
+		{D, A, -1},
+		{B, E, -1},
+	}
+
+	tests := []struct {
+		name   string
+		fields weightedMockFields
+		want   []VertexValue
+	}{
+		{
+			name:   "test the cycle",
+			fields: weightedMockFields{bias: Adds, edges: square},
+			want:   []VertexValue{A, B, C, D},
+		},
+		{
+			name:   "test the cycle broken by the removed edge",
+			fields: weightedMockFields{bias: Adds, edges: square, removedEdges: [][2]VertexValue{{C, D}}},
+			want:   nil,
+		},
+		{
+			name:   "test the cycle broken by the removed vertex",
+			fields: weightedMockFields{bias: Removal, edges: square, removedVertices: []VertexValue{D}},
+			want:   nil,
+		},
+		{
+			name: "test the cycle of the other component",
+			fields: weightedMockFields{bias: Adds, edges: []weightedEdge{
+				{A, B, -1},
+				{C, D, -1},
+				{D, E, -1},
+				{E, C, -1},
+			}},
+			want: []VertexValue{C, D, E},
+		},
+		{
+			name:   "test the graph without edges",
+			fields: weightedMockFields{bias: Adds},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph, _ := NewWeightedMockGraph(tt.fields, "")
+
+			if got := graph.FindCycle(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.FindCycle() = %v, want %v", got, tt.want)
+			}
+			if got := graph.HasCycle(); got != (tt.want != nil) {
+				t.Errorf("LWWGraphImpl.HasCycle() = %v, want %v", got, tt.want != nil)
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_SpanningForest(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+	   A -5- B -1- E
+	   |     |
+	   1     1
+	   |     |
+	   D -1- C
+	*/
+	edges := []weightedEdge{
+		{A, B, 5},
+		{B, C, 1},
+		{C, D, 1},
+		{D, A, 1},
+		{B, E, 1},
+	}
+
+	tests := []struct {
+		name   string
+		fields weightedMockFields
+		mode   SpanningMode
+		want   [][2]VertexValue
+	}{
+		{
+			name:   "test breadth first",
+			fields: weightedMockFields{bias: Adds, edges: edges},
+			mode:   BreadthFirst,
+			want:   [][2]VertexValue{{A, B}, {A, D}, {B, C}, {B, E}},
+		},
+		{
+			name:   "test minimum weight",
+			fields: weightedMockFields{bias: Adds, edges: edges},
+			mode:   MinimumWeight,
+			want:   [][2]VertexValue{{A, D}, {B, C}, {B, E}, {C, D}},
+		},
+		{
+			name:   "test minimum weight with the removed edge",
+			fields: weightedMockFields{bias: Adds, edges: edges, removedEdges: [][2]VertexValue{{C, D}}},
+			mode:   MinimumWeight,
+			want:   [][2]VertexValue{{A, B}, {A, D}, {B, C}, {B, E}},
+		},
+		{
+			name:   "test minimum weight with the removed vertex",
+			fields: weightedMockFields{bias: Adds, edges: edges, removedVertices: []VertexValue{B}},
+			mode:   MinimumWeight,
+			want:   [][2]VertexValue{{A, D}, {C, D}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph, _ := NewWeightedMockGraph(tt.fields, "")
+
+			if got := graph.SpanningForest(tt.mode); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.SpanningForest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Check the cycle found is a cycle of the adjacency, and the spanning forests have an edge
+// less than the vertices for every component after every random operation
+func TestLWWGraphImpl_Cycle_Random_Operations(t *testing.T) {
+
+	for _, bias := range []Bias{Adds, Removal} {
+
+		r := rand.New(rand.NewSource(int64(bias) + 17))
+		replicas := newRandomReplicas(bias, "x", "y")
+		values := newRandomValues(8)
+
+		for step := 0; step < 500; step++ {
+
+			graph, op := applyRandomOperation(r, replicas, values)
+
+			adjacency := graph.GetAdjacencyVerticesList()
+			connected := func(m, n VertexValue) bool {
+				for _, v := range adjacency[m] {
+					if v == n {
+						return true
+					}
+				}
+				return false
+			}
+
+			edges := 0
+			for _, adj := range adjacency {
+				edges += len(adj)
+			}
+			edges /= 2
+
+			wantForest := len(adjacency) - len(graph.ConnectedComponents())
+			weights := map[SpanningMode]float64{}
+
+			for _, mode := range []SpanningMode{BreadthFirst, MinimumWeight} {
+				forest := graph.SpanningForest(mode)
+				if len(forest) != wantForest {
+					t.Fatalf("step %v %v: LWWGraphImpl.SpanningForest(%v) = %v, want %v edges", step, op, mode, forest, wantForest)
+				}
+				for _, e := range forest {
+					if !connected(e[0], e[1]) {
+						t.Fatalf("step %v %v: LWWGraphImpl.SpanningForest(%v) = %v, %v is not an edge", step, op, mode, forest, e)
+					}
+					weights[mode] += graph.(*LWWGraphImpl).edgeWeight(e[0], e[1])
+				}
+			}
+			if weights[MinimumWeight] > weights[BreadthFirst] {
+				t.Fatalf("step %v %v: LWWGraphImpl.SpanningForest() minimum weight %v, want at most %v", step, op, weights[MinimumWeight], weights[BreadthFirst])
+			}
+
+			cycle := graph.FindCycle()
+			if got, want := cycle != nil, edges > wantForest; got != want {
+				t.Fatalf("step %v %v: LWWGraphImpl.FindCycle() = %v, want cycle %v", step, op, cycle, want)
+			}
+			if cycle == nil {
+				continue
+			}
+
+			seen := map[VertexValue]bool{}
+			for i, v := range cycle {
+				if seen[v] {
+					t.Fatalf("step %v %v: LWWGraphImpl.FindCycle() = %v, %v is repeated", step, op, cycle, v)
+				}
+				seen[v] = true
+				if next := cycle[(i+1)%len(cycle)]; !connected(v, next) {
+					t.Fatalf("step %v %v: LWWGraphImpl.FindCycle() = %v, %v and %v are not connected", step, op, cycle, v, next)
+				}
+			}
+			if len(cycle) < 3 {
+				t.Fatalf("step %v %v: LWWGraphImpl.FindCycle() = %v, want at least 3 vertices", step, op, cycle)
+			}
+		}
+	}
+}
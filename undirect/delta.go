@@ -0,0 +1,57 @@
+package undirect
+
+// Delta return the records written after the since timestamp as a graph, the delta is
+// a state of the same graph that only contains part of the records, so it can be merged
+// into any replica, or merged with the other deltas before it is shipped, in the same
+// way as merging the whole graph.
+//
+// The records are copied, so the delta is not affected by the following writes to the graph.
+// The records received by merge keep the timestamps of the replica that wrote them, so the
+// delta only contains them when they are written after the since timestamp. The replicas
+// should exchange the deltas with every other replica, or the whole graph when a replica
+// is relaying the records of the others.
+//
+// The version vectors of the vertices and the edges of the records in the delta are taken
+// along, the version vectors only changed by merge are sent with the following writes.
+func (graph *LWWGraphImplOf[ID]) Delta(since int64) LWWGraphOf[ID] {
+
+	delta := NewLWWGraphOf[ID](graph.bias, graph.clock, graph.replica, WithEdgeBiasOf[ID](graph.edgeBias), WithIntegrityOf[ID](graph.integrity)).(*LWWGraphImplOf[ID])
+
+	deltaVertices(delta.vertices, graph.vertices, since)
+	deltaVertices(delta.tombstoneVertices, graph.tombstoneVertices, since)
+	deltaEdgesMatrix(delta.edgesMatrix, graph.edgesMatrix, since)
+	deltaEdgesMatrix(delta.tombstoneEdgesMatrix, graph.tombstoneEdgesMatrix, since)
+	graph.properties.Range(func(owner PropertyOwnerOf[ID], key string, p LWWProperty) bool {
+		if p.GetTimestamp() > since {
+			delta.properties.Set(owner, key, p)
+		}
+		return true
+	})
+	graph.versions.Range(func(component PropertyOwnerOf[ID], vv VersionVector) bool {
+		if add, remove := delta.localRecords(component); add != nil || remove != nil {
+			delta.versions.Set(component, vv)
+		}
+		return true
+	})
+	delta.buildIndex()
+
+	return delta
+}
+
+func deltaVertices[ID comparable](delta, vertices VertexStoreOf[ID], since int64) {
+	vertices.Range(func(v LWWVertexOf[ID]) bool {
+		if v.GetTimestamp() > since {
+			delta.Set(copyVertex(v))
+		}
+		return true
+	})
+}
+
+func deltaEdgesMatrix[ID comparable](delta, matrix EdgeStoreOf[ID], since int64) {
+	matrix.Range(func(m, n ID, e LWWEdgeOf[ID]) bool {
+		if e.GetTimestamp() > since {
+			delta.Set(m, n, copyEdge(e))
+		}
+		return true
+	})
+}
@@ -0,0 +1,210 @@
+package undirect
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestLWWGraphImpl_Delta(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	graph := NewLWWGraph(Adds, &testCkock{}, "x")
+	applyMockOperations(graph, []mockOperation{
+		{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}},
+		{C, mockGraphAddAction, 2 * time.Minute, nil},
+		{A, mockGraphRemoveAction, 3 * time.Minute, []VertexValue{B}},
+	})
+
+	delta := graph.Delta(time.Unix(0, 0).Add(1 * time.Minute).UnixNano())
+
+	vertices, tombstoneVertices, edges, tombstoneEdges := countRecords(delta)
+	if vertices != 1 || tombstoneVertices != 0 || edges != 0 || tombstoneEdges != 2 {
+		t.Errorf("LWWGraphImpl.Delta() records = %v, %v, %v, %v, want 1, 0, 0, 2", vertices, tombstoneVertices, edges, tombstoneEdges)
+	}
+	if _, ok := delta.GetVertices()[C]; !ok {
+		t.Errorf("LWWGraphImpl.Delta() vertices = %v, want %v", delta.GetVertices(), C)
+	}
+
+	// the delta is not affected by the following writes
+	applyMockOperations(graph, []mockOperation{{C, mockGraphAddAction, 4 * time.Minute, nil}})
+
+	if got, want := delta.GetVertices()[C].GetTimestamp(), time.Unix(0, 0).Add(2*time.Minute).UnixNano(); got != want {
+		t.Errorf("LWWGraphImpl.Delta() timestamp of %v = %v, want %v", C, got, want)
+	}
+}
+
+// Check the replicas exchanging deltas converge to the same state as exchanging the whole graph
+func TestLWWGraphImpl_Delta_Merge_Converge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	type round struct {
+		x, y []mockOperation
+	}
+
+	tests := []struct {
+		name   string
+		bias   Bias
+		rounds []round
+	}{
+		{
+			name: "adds only",
+			bias: Adds,
+			rounds: []round{
+				{
+					x: []mockOperation{{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}}},
+					y: []mockOperation{{C, mockGraphAddAction, 1 * time.Minute, []VertexValue{D}}},
+				},
+				{
+					x: []mockOperation{{B, mockGraphAddAction, 2 * time.Minute, []VertexValue{C}}},
+					y: []mockOperation{{D, mockGraphAddAction, 2 * time.Minute, []VertexValue{A}}},
+				},
+			},
+		},
+		{
+			name: "removals of the synced records",
+			bias: Adds,
+			rounds: []round{
+				{
+					x: []mockOperation{{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}}},
+					y: []mockOperation{{C, mockGraphAddAction, 1 * time.Minute, []VertexValue{D}}},
+				},
+				{
+					x: []mockOperation{{C, mockGraphRemoveAction, 2 * time.Minute, nil}},
+					y: []mockOperation{{A, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{B}}},
+				},
+				{
+					x: []mockOperation{{C, mockGraphAddAction, 3 * time.Minute, []VertexValue{B}}},
+					y: []mockOperation{{D, mockGraphRemoveAction, 3 * time.Minute, nil}},
+				},
+			},
+		},
+		{
+			name: "concurrent removals and adds with removal bias",
+			bias: Removal,
+			rounds: []round{
+				{
+					x: []mockOperation{{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}}},
+					y: []mockOperation{{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{C}}},
+				},
+				{
+					x: []mockOperation{{A, mockGraphRemoveAction, 2 * time.Minute, nil}},
+					y: []mockOperation{{A, mockGraphAddAction, 2 * time.Minute, []VertexValue{D}}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			xFull := NewLWWGraph(tt.bias, &testCkock{}, "x")
+			yFull := NewLWWGraph(tt.bias, &testCkock{}, "y")
+			xDelta := NewLWWGraph(tt.bias, &testCkock{}, "x")
+			yDelta := NewLWWGraph(tt.bias, &testCkock{}, "y")
+
+			var since int64
+
+			for _, r := range tt.rounds {
+
+				applyMockOperations(xFull, r.x)
+				applyMockOperations(yFull, r.y)
+				applyMockOperations(xDelta, r.x)
+				applyMockOperations(yDelta, r.y)
+
+				xFull.Merge(yFull)
+				yFull.Merge(xFull)
+
+				// ship the changes since the last round only
+				dx, dy := xDelta.Delta(since), yDelta.Delta(since)
+				xDelta.Merge(dy)
+				yDelta.Merge(dx)
+
+				since = xDelta.GetClock().Now().UnixNano()
+
+				want := xFull.GetAdjacencyVerticesList()
+				for _, g := range []LWWGraph{yFull, xDelta, yDelta} {
+					if got := g.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+						t.Errorf("LWWGraphImpl.Merge() of %v = %v, want %v, diff: %v", g.GetReplica(), got, want, deep.Equal(got, want))
+					}
+				}
+			}
+		})
+	}
+}
+
+// Check the deltas are associative, commutative and idempotent for merge
+func TestLWWGraphImpl_Delta_Merge_Check_CRDT(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	newDeltas := func() (LWWGraph, LWWGraph, LWWGraph) {
+		x := NewLWWGraph(Adds, &testCkock{}, "x")
+		y := NewLWWGraph(Adds, &testCkock{}, "y")
+		z := NewLWWGraph(Adds, &testCkock{}, "z")
+		applyMockOperations(x, []mockOperation{
+			{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+			{A, mockGraphRemoveAction, 3 * time.Minute, []VertexValue{B}},
+		})
+		applyMockOperations(y, []mockOperation{
+			{C, mockGraphAddAction, 2 * time.Minute, []VertexValue{D}},
+			{A, mockGraphAddAction, 2 * time.Minute, []VertexValue{B}},
+		})
+		applyMockOperations(z, []mockOperation{
+			{D, mockGraphAddAction, 1 * time.Minute, []VertexValue{A}},
+			{C, mockGraphRemoveAction, 4 * time.Minute, nil},
+		})
+		return x.Delta(0), y.Delta(0), z.Delta(0)
+	}
+
+	newReplica := func() LWWGraph {
+		return NewLWWGraph(Adds, &testCkock{}, "r")
+	}
+
+	// (X U Y) U Z
+	x, y, z := newDeltas()
+	associativeLeft := newReplica()
+	x.Merge(y)
+	x.Merge(z)
+	associativeLeft.Merge(x)
+
+	// X U (Y U Z)
+	x, y, z = newDeltas()
+	associativeRight := newReplica()
+	y.Merge(z)
+	x.Merge(y)
+	associativeRight.Merge(x)
+
+	// Z U Y U X
+	x, y, z = newDeltas()
+	commutative := newReplica()
+	commutative.Merge(z)
+	commutative.Merge(y)
+	commutative.Merge(x)
+
+	// X U X U Y U Y U Z U Z
+	x, y, z = newDeltas()
+	idempotent := newReplica()
+	for _, d := range []LWWGraph{x, x, y, y, z, z} {
+		idempotent.Merge(d)
+	}
+
+	want := associativeLeft.GetAdjacencyVerticesList()
+
+	for name, g := range map[string]LWWGraph{"associative": associativeRight, "commutative": commutative, "idempotent": idempotent} {
+		if got := g.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+			t.Errorf("LWWGraphImpl.Merge() %v of deltas = %v, want %v, diff: %v", name, got, want, deep.Equal(got, want))
+		}
+	}
+}
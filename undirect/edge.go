@@ -1,32 +1,96 @@
 package undirect
 
-type LWWEdge interface {
-	GetVertices() (vertices []LWWVertex)
+// LWWEdgeOf is the record of the edge between the vertices of the ID type
+type LWWEdgeOf[ID comparable] interface {
+	GetVertices() (vertices []LWWVertexOf[ID])
 	GetTimestamp() int64
 	SetTimestamp(int64) int64
+	GetReplica() ReplicaID
+	// GetWeight return the weight of the edge, ok is false when the edge has no weight
+	GetWeight() (weight float64, ok bool)
 }
 
-type LWWEdgeImpl struct {
-	vertices  *[]LWWVertex
+type LWWEdgeImplOf[ID comparable] struct {
+	vertices  *[]LWWVertexOf[ID]
 	timestamp int64
+	replica   ReplicaID
+	// weight is part of the record, so it is resolved along with the edge by the order
+	// of the records, it is nil for the edge without weight
+	weight *float64
 }
 
-func NewLWWEdgeImpl(vertices []LWWVertex, clock Clock) LWWEdge {
-	return &LWWEdgeImpl{
+// LWWEdge is the record of the edge between the vertices of the string values
+type LWWEdge = LWWEdgeOf[VertexValue]
+
+type LWWEdgeImpl = LWWEdgeImplOf[VertexValue]
+
+func NewLWWEdgeImplOf[ID comparable](vertices []LWWVertexOf[ID], clock Clock, replica ReplicaID) LWWEdgeOf[ID] {
+	return &LWWEdgeImplOf[ID]{
 		vertices:  &vertices,
 		timestamp: clock.Now().UnixNano(),
+		replica:   replica,
 	}
 }
 
-func (edge *LWWEdgeImpl) GetVertices() (vertices []LWWVertex) {
+func NewLWWEdgeImpl(vertices []LWWVertex, clock Clock, replica ReplicaID) LWWEdge {
+	return NewLWWEdgeImplOf(vertices, clock, replica)
+}
+
+// NewWeightedLWWEdgeImplOf return the edge with the weight
+func NewWeightedLWWEdgeImplOf[ID comparable](vertices []LWWVertexOf[ID], weight float64, clock Clock, replica ReplicaID) LWWEdgeOf[ID] {
+	return &LWWEdgeImplOf[ID]{
+		vertices:  &vertices,
+		timestamp: clock.Now().UnixNano(),
+		replica:   replica,
+		weight:    &weight,
+	}
+}
+
+// NewWeightedLWWEdgeImpl return the edge with the weight
+func NewWeightedLWWEdgeImpl(vertices []LWWVertex, weight float64, clock Clock, replica ReplicaID) LWWEdge {
+	return NewWeightedLWWEdgeImplOf(vertices, weight, clock, replica)
+}
+
+func (edge *LWWEdgeImplOf[ID]) GetVertices() (vertices []LWWVertexOf[ID]) {
 	return *edge.vertices
 }
 
-func (edge *LWWEdgeImpl) GetTimestamp() int64 {
+func (edge *LWWEdgeImplOf[ID]) GetTimestamp() int64 {
 	return edge.timestamp
 }
 
-func (edge *LWWEdgeImpl) SetTimestamp(t int64) int64 {
+func (edge *LWWEdgeImplOf[ID]) SetTimestamp(t int64) int64 {
 	edge.timestamp = t
 	return edge.timestamp
 }
+
+func (edge *LWWEdgeImplOf[ID]) GetReplica() ReplicaID {
+	return edge.replica
+}
+
+func (edge *LWWEdgeImplOf[ID]) GetWeight() (float64, bool) {
+	if edge.weight == nil {
+		return 0, false
+	}
+	return *edge.weight, true
+}
+
+// copyEdge return a copy of the record of the edge along with the vertices
+func copyEdge[ID comparable](edge LWWEdgeOf[ID]) LWWEdgeOf[ID] {
+
+	vertices := []LWWVertexOf[ID]{}
+	for _, v := range edge.GetVertices() {
+		vertices = append(vertices, copyVertex(v))
+	}
+
+	copied := &LWWEdgeImplOf[ID]{
+		vertices:  &vertices,
+		timestamp: edge.GetTimestamp(),
+		replica:   edge.GetReplica(),
+	}
+	if weight, ok := edge.GetWeight(); ok {
+		copied.weight = &weight
+	}
+
+	return copied
+}
@@ -0,0 +1,70 @@
+package undirect
+
+// GarbageCollect purges the records that can no longer affect the graph, stable is the
+// minimum timestamp acknowledged by all known replicas, which means every replica has
+// merged all of the records written at or before it, and no record at or before it
+// will be written anymore.
+//
+// For the records at or before the stable timestamp:
+//   - the tombstone is purged when the add record is after it, as the component exists anyway
+//   - both records are purged when the tombstone is after the add record, as the component
+//     is removed and every replica has seen the removal
+//   - the tombstone without add record is purged
+//
+// The removed vertex is kept when there are edges still connected to it, as the edges
+// come back when the vertex is added again. The empty cells of the matrices are purged
+// as well.
+//
+// The deletions of the properties at or before the stable timestamp are purged. The properties
+// of the vertices and the edges of which the add records are purged are kept, as they come back
+// along with the owner added again on every replica. The version vectors are kept, so the vertex
+// or the edge written again is ordered after the writes before.
+func (graph *LWWGraphImplOf[ID]) GarbageCollect(stable int64) {
+
+	graph.edgesMatrix.Range(func(m, n ID, edge LWWEdgeOf[ID]) bool {
+		tombstoneEdge, ok := graph.tombstoneEdgesMatrix.Get(m, n)
+		if !ok || tombstoneEdge.GetTimestamp() > stable {
+			return true
+		}
+		if !graph.IsComponentExist(edge, tombstoneEdge) {
+			graph.edgesMatrix.Delete(m, n)
+		}
+		graph.tombstoneEdgesMatrix.Delete(m, n)
+		return true
+	})
+
+	graph.tombstoneEdgesMatrix.Range(func(m, n ID, tombstoneEdge LWWEdgeOf[ID]) bool {
+		if _, ok := graph.edgesMatrix.Get(m, n); !ok && tombstoneEdge.GetTimestamp() <= stable {
+			graph.tombstoneEdgesMatrix.Delete(m, n)
+		}
+		return true
+	})
+
+	graph.tombstoneVertices.Range(func(tombstoneVertex LWWVertexOf[ID]) bool {
+		k := tombstoneVertex.GetValue()
+		if tombstoneVertex.GetTimestamp() > stable {
+			return true
+		}
+		vertex, ok := graph.vertices.Get(k)
+		if !ok {
+			graph.tombstoneVertices.Delete(k)
+			return true
+		}
+		if graph.IsComponentExist(vertex, tombstoneVertex) {
+			graph.tombstoneVertices.Delete(k)
+			return true
+		}
+		if graph.edgesMatrix.RowLen(k) == 0 {
+			graph.vertices.Delete(k)
+			graph.tombstoneVertices.Delete(k)
+		}
+		return true
+	})
+
+	graph.properties.Range(func(owner PropertyOwnerOf[ID], key string, property LWWProperty) bool {
+		if property.IsDeleted() && property.GetTimestamp() <= stable {
+			graph.properties.Delete(owner, key)
+		}
+		return true
+	})
+}
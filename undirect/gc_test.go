@@ -0,0 +1,363 @@
+package undirect
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// applyMockOperations apply the operations to the graph, the duration of the
+// operation is the time since the unix epoch of the clock of the graph
+func applyMockOperations(graph LWWGraph, operations []mockOperation) {
+
+	clock := graph.GetClock().(*testCkock)
+
+	for _, op := range operations {
+
+		now := time.Unix(0, 0).Add(op.duration)
+		clock.now = &now
+
+		switch op.action {
+		case mockGraphAddAction:
+			if op.connectedVertices == nil {
+				graph.AddVertex(op.value)
+				continue
+			}
+			for _, v := range op.connectedVertices {
+				graph.AddEdge(NewLWWVertex(op.value, clock, graph.GetReplica()), NewLWWVertex(v, clock, graph.GetReplica()))
+			}
+		case mockGraphRemoveAction:
+			if op.connectedVertices == nil {
+				graph.RemoveVertex(op.value)
+				continue
+			}
+			for _, v := range op.connectedVertices {
+				graph.RemoveEdgeByVertices(op.value, v)
+			}
+		}
+	}
+}
+
+// observe return the query results of the graph for the vertices
+func observe(graph LWWGraph, values []VertexValue) map[string]interface{} {
+
+	exist := map[VertexValue]bool{}
+	edges := map[VertexValue][][]VertexValue{}
+
+	for _, v := range values {
+		exist[v] = graph.IsVertexExist(v)
+		for _, e := range graph.GetEdges(v) {
+			vs := e.GetVertices()
+			edges[v] = append(edges[v], []VertexValue{vs[0].GetValue(), vs[1].GetValue()})
+		}
+	}
+
+	return map[string]interface{}{
+		"adjacency": graph.GetAdjacencyVerticesList(),
+		"exist":     exist,
+		"edges":     edges,
+	}
+}
+
+func countRecords(graph LWWGraph) (vertices, tombstoneVertices, edges, tombstoneEdges int) {
+
+	for _, v := range graph.GetVertices() {
+		if v != nil {
+			vertices++
+		}
+	}
+	for _, v := range graph.GetTombstoneVertices() {
+		if v != nil {
+			tombstoneVertices++
+		}
+	}
+	for m := range graph.GetEdgesMatrix() {
+		for _, e := range graph.GetEdgesMatrix()[m] {
+			if e != nil {
+				edges++
+			}
+		}
+	}
+	for m := range graph.GetTombstoneEdgesMatrix() {
+		for _, e := range graph.GetTombstoneEdgesMatrix()[m] {
+			if e != nil {
+				tombstoneEdges++
+			}
+		}
+	}
+
+	return
+}
+
+func TestLWWGraphImpl_GarbageCollect(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	values := []VertexValue{A, B, C, D}
+
+	type want struct {
+		vertices, tombstoneVertices, edges, tombstoneEdges int
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		bias        Bias
+		operations  []mockOperation
+		stable      time.Duration
+		want        want
+	}{
+		{
+			name:        "purge removed vertex and its edges",
+			description: "the vertex and the edges are removed before the stable time",
+			bias:        Adds,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+				{A, mockGraphRemoveAction, 2 * time.Minute, nil},
+			},
+			stable: 2 * time.Minute,
+			want:   want{vertices: 2},
+		},
+		{
+			name:        "keep records after the stable time",
+			description: "the removal is after the stable time",
+			bias:        Adds,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+				{A, mockGraphRemoveAction, 3 * time.Minute, nil},
+			},
+			stable: 2 * time.Minute,
+			want:   want{vertices: 3, tombstoneVertices: 1, edges: 4, tombstoneEdges: 4},
+		},
+		{
+			name:        "purge tombstone of vertex added again",
+			description: "the vertex is added after it was removed",
+			bias:        Adds,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}},
+				{A, mockGraphRemoveAction, 2 * time.Minute, nil},
+				{A, mockGraphAddAction, 3 * time.Minute, nil},
+			},
+			stable: 3 * time.Minute,
+			want:   want{vertices: 2},
+		},
+		{
+			name:        "purge tombstone of edge added again",
+			description: "the edge is added after it was removed",
+			bias:        Adds,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}},
+				{A, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{B}},
+				{A, mockGraphAddAction, 3 * time.Minute, []VertexValue{B}},
+			},
+			stable: 3 * time.Minute,
+			want:   want{vertices: 2, edges: 2},
+		},
+		{
+			name:        "purge removed edge with removal bias and same timestamp",
+			description: "the edge is removed at the same time as it was added",
+			bias:        Removal,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}},
+				{A, mockGraphRemoveAction, 1 * time.Minute, []VertexValue{B}},
+			},
+			stable: 1 * time.Minute,
+			want:   want{vertices: 2},
+		},
+		{
+			name:        "purge vertex and edge removed more than once",
+			description: "the edge is added again and removed along with the vertex",
+			bias:        Adds,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}},
+				{C, mockGraphAddAction, 1 * time.Minute, []VertexValue{D}},
+				{C, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{D}},
+				{A, mockGraphRemoveAction, 2 * time.Minute, nil},
+				{C, mockGraphAddAction, 3 * time.Minute, []VertexValue{D}},
+				{C, mockGraphRemoveAction, 4 * time.Minute, nil},
+			},
+			stable: 4 * time.Minute,
+			want:   want{vertices: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph := NewLWWGraph(tt.bias, &testCkock{}, "")
+			applyMockOperations(graph, tt.operations)
+
+			before := observe(graph, values)
+
+			graph.GarbageCollect(time.Unix(0, 0).Add(tt.stable).UnixNano())
+
+			if after := observe(graph, values); !reflect.DeepEqual(before, after) {
+				t.Errorf("LWWGraphImpl.GarbageCollect() changed the query results, got %v, want %v, diff: %v", after, before, deep.Equal(after, before))
+			}
+
+			var got want
+			got.vertices, got.tombstoneVertices, got.edges, got.tombstoneEdges = countRecords(graph)
+			if got != tt.want {
+				t.Errorf("LWWGraphImpl.GarbageCollect() records = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_GarbageCollect_With_Edges_Of_Removed_Vertex(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	x := NewLWWGraph(Adds, &testCkock{}, "x")
+	y := NewLWWGraph(Adds, &testCkock{}, "y")
+
+	applyMockOperations(x, []mockOperation{
+		{A, mockGraphAddAction, 1 * time.Minute, nil},
+		{B, mockGraphAddAction, 1 * time.Minute, nil},
+	})
+	y.Merge(x)
+
+	// X removes A while Y adds the edge concurrently
+	applyMockOperations(x, []mockOperation{{A, mockGraphRemoveAction, 2 * time.Minute, nil}})
+	applyMockOperations(y, []mockOperation{{A, mockGraphAddAction, 2 * time.Minute, []VertexValue{B}}})
+
+	x.Merge(y)
+	x.GarbageCollect(time.Unix(0, 0).Add(2 * time.Minute).UnixNano())
+
+	want := map[VertexValue][]VertexValue{
+		B: {},
+	}
+	if got := x.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+
+	// A comes back with the edge from Y when it is added again
+	applyMockOperations(x, []mockOperation{{A, mockGraphAddAction, 3 * time.Minute, nil}})
+
+	want = map[VertexValue][]VertexValue{
+		A: {B},
+		B: {A},
+	}
+	if got := x.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+}
+
+// Check the replicas still converge after one of them purged the records acknowledged by both of them
+func TestLWWGraphImpl_GarbageCollect_Merge_Converge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	xBefore := []mockOperation{
+		{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+		{A, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{B}},
+		{C, mockGraphRemoveAction, 3 * time.Minute, nil},
+	}
+	yBefore := []mockOperation{
+		{D, mockGraphAddAction, 1 * time.Minute, []VertexValue{A}},
+		{D, mockGraphRemoveAction, 2 * time.Minute, nil},
+		{D, mockGraphAddAction, 3 * time.Minute, []VertexValue{B}},
+	}
+	xAfter := []mockOperation{
+		{C, mockGraphAddAction, 5 * time.Minute, []VertexValue{D}},
+		{B, mockGraphRemoveAction, 6 * time.Minute, nil},
+	}
+	yAfter := []mockOperation{
+		{A, mockGraphAddAction, 5 * time.Minute, []VertexValue{B}},
+		{D, mockGraphRemoveAction, 6 * time.Minute, []VertexValue{A}},
+	}
+
+	newReplicas := func() (LWWGraph, LWWGraph) {
+		x := NewLWWGraph(Adds, &testCkock{}, "x")
+		y := NewLWWGraph(Adds, &testCkock{}, "y")
+		applyMockOperations(x, xBefore)
+		applyMockOperations(y, yBefore)
+		// both of the replicas acknowledged every record at or before 4 minutes
+		x.Merge(y)
+		y.Merge(x)
+		return x, y
+	}
+
+	x, y := newReplicas()
+	x.GarbageCollect(time.Unix(0, 0).Add(4 * time.Minute).UnixNano())
+
+	xWithoutGC, yWithoutGC := newReplicas()
+
+	for _, pair := range [][2]LWWGraph{{x, y}, {xWithoutGC, yWithoutGC}} {
+		applyMockOperations(pair[0], xAfter)
+		applyMockOperations(pair[1], yAfter)
+		pair[0].Merge(pair[1])
+		pair[1].Merge(pair[0])
+	}
+
+	gotX := x.GetAdjacencyVerticesList()
+	gotY := y.GetAdjacencyVerticesList()
+	want := xWithoutGC.GetAdjacencyVerticesList()
+
+	if !reflect.DeepEqual(gotX, gotY) {
+		t.Errorf("LWWGraphImpl.GarbageCollect() replicas diverged, x: %v, y: %v, diff: %v", gotX, gotY, deep.Equal(gotX, gotY))
+	}
+	if !reflect.DeepEqual(gotX, want) {
+		t.Errorf("LWWGraphImpl.GarbageCollect() = %v, want %v, diff: %v", gotX, want, deep.Equal(gotX, want))
+	}
+}
+
+// Check the removal of the edge purged by the garbage collection still wins over the concurrent add
+func TestLWWGraphImpl_GarbageCollect_Remove_Purged_Edge(t *testing.T) {
+
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	before := []mockOperation{
+		{C, mockGraphAddAction, 1 * time.Minute, []VertexValue{D}},
+		{C, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{D}},
+	}
+	xAfter := []mockOperation{
+		{C, mockGraphRemoveAction, 5 * time.Minute, []VertexValue{D}},
+	}
+	yAfter := []mockOperation{
+		{C, mockGraphAddAction, 4 * time.Minute, []VertexValue{D}},
+	}
+
+	newReplicas := func() (LWWGraph, LWWGraph) {
+		x := NewLWWGraph(Adds, &testCkock{}, "x")
+		y := NewLWWGraph(Adds, &testCkock{}, "y")
+		applyMockOperations(x, before)
+		// both of the replicas acknowledged every record at or before 3 minutes
+		y.Merge(x)
+		return x, y
+	}
+
+	x, y := newReplicas()
+	x.GarbageCollect(time.Unix(0, 0).Add(3 * time.Minute).UnixNano())
+
+	xWithoutGC, yWithoutGC := newReplicas()
+
+	for _, pair := range [][2]LWWGraph{{x, y}, {xWithoutGC, yWithoutGC}} {
+		applyMockOperations(pair[0], xAfter)
+		applyMockOperations(pair[1], yAfter)
+		pair[0].Merge(pair[1])
+	}
+
+	got := observe(x, []VertexValue{C, D})
+	want := observe(xWithoutGC, []VertexValue{C, D})
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GarbageCollect() changed the query results, got %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+	wantAdjacency := map[VertexValue][]VertexValue{
+		C: {},
+		D: {},
+	}
+	if got := x.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, wantAdjacency) {
+		t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, wantAdjacency, deep.Equal(got, wantAdjacency))
+	}
+}
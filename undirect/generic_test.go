@@ -0,0 +1,254 @@
+package undirect
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// cell is the composite ID of the tests, which is ordered by the row then the column
+type cell struct {
+	Row, Column int
+}
+
+// addEdgesOf add the edges of the pairs of the IDs to the graph
+func addEdgesOf[ID comparable](graph LWWGraphOf[ID], clock Clock, replica ReplicaID, edges [][2]ID) {
+	for _, e := range edges {
+		graph.AddEdge(NewLWWVertexOf(e[0], clock, replica), NewLWWVertexOf(e[1], clock, replica))
+	}
+}
+
+func TestLWWGraphImplOf_Int(t *testing.T) {
+
+	/*
+	   1 - 2 - 10   9 - 20
+	        \ /
+	         3
+	*/
+	clock := &testCkock{}
+	graph := NewLWWGraphOf[int](Adds, clock, "x")
+	addEdgesOf(graph, clock, "x", [][2]int{{1, 2}, {2, 10}, {10, 3}, {3, 2}, {9, 20}})
+
+	// the integers are ordered as the numbers, not as the strings
+	if got, want := graph.ConnectedComponents(), [][]int{{1, 2, 3, 10}, {9, 20}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.ConnectedComponents() = %v, want %v", got, want)
+	}
+
+	if got, want := graph.GetPaths(20, 9), [][]int{{20, 9}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.GetPaths() = %v, want %v", got, want)
+	}
+
+	if got, distance := graph.ShortestPath(1, 10); !reflect.DeepEqual(got, []int{1, 2, 10}) || distance != 2 {
+		t.Errorf("LWWGraphImplOf.ShortestPath() = %v, %v, want %v, %v", got, distance, []int{1, 2, 10}, 2)
+	}
+
+	if got, want := graph.Bridges(), [][2]int{{1, 2}, {9, 20}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.Bridges() = %v, want %v", got, want)
+	}
+
+	clock.AddDuration(1 * time.Minute)
+	graph.RemoveEdgeByVertices(2, 10)
+	graph.RemoveVertex(3)
+
+	if got := graph.IsReachable(1, 10); got {
+		t.Errorf("LWWGraphImplOf.IsReachable() = %v, want %v", got, false)
+	}
+
+	if got, want := graph.GetAdjacencyVerticesList(), map[int][]int{1: {2}, 2: {1}, 9: {20}, 10: {}, 20: {9}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+}
+
+func TestLWWGraphImplOf_Struct(t *testing.T) {
+
+	/*
+	   (0,0) - (0,1) - (0,2)
+	     |               |
+	   (1,0) - (1,1) - (1,2)
+	*/
+	clock := &testCkock{}
+	graph := NewLWWGraphOf[cell](Removal, clock, "x")
+	addEdgesOf(graph, clock, "x", [][2]cell{
+		{{0, 1}, {0, 0}},
+		{{0, 2}, {0, 1}},
+		{{1, 0}, {0, 0}},
+		{{1, 1}, {1, 0}},
+		{{1, 2}, {1, 1}},
+		{{1, 2}, {0, 2}},
+	})
+
+	if got, want := graph.FindCycle(), []cell{{0, 0}, {0, 1}, {0, 2}, {1, 2}, {1, 1}, {1, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.FindCycle() = %v, want %v", got, want)
+	}
+
+	if got, want := graph.ShortestPathHops(cell{1, 0}, cell{0, 2}), []cell{{1, 0}, {0, 0}, {0, 1}, {0, 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.ShortestPathHops() = %v, want %v", got, want)
+	}
+
+	want := [][2]cell{
+		{{0, 0}, {0, 1}},
+		{{0, 0}, {1, 0}},
+		{{0, 1}, {0, 2}},
+		{{0, 2}, {1, 2}},
+		{{1, 0}, {1, 1}},
+	}
+	if got := graph.SpanningForest(BreadthFirst); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.SpanningForest() = %v, want %v", got, want)
+	}
+
+	clock.AddDuration(1 * time.Minute)
+	graph.SetVertexProperty(cell{0, 0}, "color", "red")
+	graph.SetEdgeProperty(cell{1, 0}, cell{0, 0}, "color", "blue")
+
+	if got, ok := graph.GetEdgeProperty(cell{0, 0}, cell{1, 0}, "color"); !ok || got != "blue" {
+		t.Errorf("LWWGraphImplOf.GetEdgeProperty() = %v, %v, want %v, %v", got, ok, "blue", true)
+	}
+}
+
+// mergeReplicasOf merge the replicas of the same operations of the values a, b, c and d
+// in both orders
+func mergeReplicasOf[ID comparable](bias Bias, a, b, c, d ID) (LWWGraphOf[ID], LWWGraphOf[ID]) {
+
+	xClock, yClock := &testCkock{}, &testCkock{}
+	x := NewLWWGraphOf[ID](bias, xClock, "x")
+	y := NewLWWGraphOf[ID](bias, yClock, "y")
+
+	addEdgesOf(x, xClock, "x", [][2]ID{{a, b}, {b, d}})
+	yClock.Now()
+	yClock.SyncWith(xClock)
+	addEdgesOf(y, yClock, "y", [][2]ID{{a, c}, {c, d}})
+
+	xClock.AddDuration(1 * time.Minute)
+	x.RemoveVertex(b)
+	yClock.SyncWith(xClock)
+	addEdgesOf(y, yClock, "y", [][2]ID{{b, a}})
+
+	xy := NewLWWGraphOf[ID](bias, &testCkock{}, "xy")
+	xy.Merge(x)
+	xy.Merge(y)
+	yx := NewLWWGraphOf[ID](bias, &testCkock{}, "yx")
+	yx.Merge(y)
+	yx.Merge(x)
+
+	return xy, yx
+}
+
+// Check the replicas of the generic graph converge to the same state as the graph of the string values
+func TestLWWGraphImplOf_Merge(t *testing.T) {
+
+	for _, bias := range []Bias{Adds, Removal} {
+
+		xy, yx := mergeReplicasOf(bias, cell{0, 0}, cell{0, 1}, cell{1, 0}, cell{1, 1})
+		if got, want := xy.GetAdjacencyVerticesList(), yx.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+			t.Errorf("bias %v: LWWGraphImplOf.Merge() = %v, want %v, diff: %v", bias, got, want, deep.Equal(got, want))
+		}
+
+		A, B, C, D := NewVertexValue("A"), NewVertexValue("B"), NewVertexValue("C"), NewVertexValue("D")
+		want, _ := mergeReplicasOf(bias, A, B, C, D)
+
+		if got, want := xy.IsVertexExist(cell{0, 1}), want.IsVertexExist(B); got != want {
+			t.Errorf("bias %v: LWWGraphImplOf.IsVertexExist() = %v, want %v", bias, got, want)
+		}
+		if got, want := len(xy.GetEdges(cell{0, 0})), len(want.GetEdges(A)); got != want {
+			t.Errorf("bias %v: LWWGraphImplOf.GetEdges() = %v edges, want %v edges", bias, got, want)
+		}
+	}
+}
+
+func TestLWWGraphImplOf_JSON(t *testing.T) {
+
+	clock := &testCkock{}
+	graph := NewLWWGraphOf[cell](Adds, clock, "x")
+	addEdgesOf(graph, clock, "x", [][2]cell{{{0, 0}, {0, 1}}, {{0, 1}, {1, 1}}})
+	clock.AddDuration(1 * time.Minute)
+	graph.RemoveEdgeByVertices(cell{0, 1}, cell{1, 1})
+	graph.SetEdgeProperty(cell{0, 1}, cell{0, 0}, "color", "red")
+
+	data, err := json.Marshal(graph)
+	if err != nil {
+		t.Fatalf("LWWGraphImplOf.MarshalJSON() error = %v", err)
+	}
+
+	decoded := &LWWGraphImplOf[cell]{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("LWWGraphImplOf.UnmarshalJSON() error = %v", err)
+	}
+
+	if got, want := decoded.GetEdgesMatrix(), graph.GetEdgesMatrix(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.UnmarshalJSON() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+	if got, want := decoded.GetAdjacencyVerticesList(), graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.UnmarshalJSON() = %v, want %v", got, want)
+	}
+	if got, want := decoded.GetProperties(), graph.GetProperties(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImplOf.UnmarshalJSON() = %v, want %v", got, want)
+	}
+}
+
+func TestNewOrder(t *testing.T) {
+
+	type key struct {
+		Name  string
+		Index [2]uint8
+		Ok    bool
+	}
+
+	tests := []struct {
+		name string
+		sort func() interface{}
+		want interface{}
+	}{
+		{
+			name: "test integers",
+			sort: func() interface{} { return sortedOf([]int{10, -1, 2}) },
+			want: []int{-1, 2, 10},
+		},
+		{
+			name: "test floats",
+			sort: func() interface{} { return sortedOf([]float64{2.5, -1, 0.5}) },
+			want: []float64{-1, 0.5, 2.5},
+		},
+		{
+			name: "test structs by the fields",
+			sort: func() interface{} { return sortedOf([]cell{{1, 0}, {0, 2}, {0, 1}}) },
+			want: []cell{{0, 1}, {0, 2}, {1, 0}},
+		},
+		{
+			name: "test structs of the arrays",
+			sort: func() interface{} {
+				return sortedOf([]key{
+					{"B", [2]uint8{0, 0}, false},
+					{"A", [2]uint8{1, 0}, false},
+					{"A", [2]uint8{0, 1}, true},
+					{"A", [2]uint8{0, 1}, false},
+				})
+			},
+			want: []key{
+				{"A", [2]uint8{0, 1}, false},
+				{"A", [2]uint8{0, 1}, true},
+				{"A", [2]uint8{1, 0}, false},
+				{"B", [2]uint8{0, 0}, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sort(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// sortedOf sort the IDs by the order of the type
+func sortedOf[ID comparable](arr []ID) []ID {
+	order := newOrder[ID]()
+	sort.Slice(arr, func(i, j int) bool {
+		return order.less(arr[i], arr[j])
+	})
+	return arr
+}
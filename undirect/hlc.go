@@ -0,0 +1,63 @@
+package undirect
+
+import (
+	"sync"
+	"time"
+)
+
+// ObservingClock is the interface for the clocks that can be advanced by the timestamps
+// received from other replicas, the graph will call Observe with the greatest timestamp
+// of the other replica when merging, so that the following local writes are always
+// ordered after everything the replica has seen.
+type ObservingClock interface {
+	Clock
+	// advance the clock to at least the provided unix nano timestamp
+	Observe(timestamp int64)
+}
+
+// HLC is a hybrid logical clock, it follows the physical clock when it is ahead
+// and falls back to a logical counter when the physical clock is behind of the
+// last issued or observed timestamp.
+//
+// The logical counter is folded into the nanoseconds of the timestamp, every call
+// of Now returns a timestamp strictly greater than the previous one, so the clock
+// ticks on every write of the graph even when the physical clock stands still or
+// goes backwards.
+type HLC struct {
+	mu       sync.Mutex
+	physical Clock
+	last     int64
+}
+
+// NewHLC return a hybrid logical clock on top of the physical clock,
+// the system clock will be used when physical is nil
+func NewHLC(physical Clock) *HLC {
+	if physical == nil {
+		physical = &clock{}
+	}
+	return &HLC{physical: physical}
+}
+
+func (hlc *HLC) Now() time.Time {
+
+	hlc.mu.Lock()
+	defer hlc.mu.Unlock()
+
+	if pt := hlc.physical.Now().UnixNano(); pt > hlc.last {
+		hlc.last = pt
+	} else {
+		hlc.last++
+	}
+
+	return time.Unix(0, hlc.last)
+}
+
+func (hlc *HLC) Observe(timestamp int64) {
+
+	hlc.mu.Lock()
+	defer hlc.mu.Unlock()
+
+	if timestamp > hlc.last {
+		hlc.last = timestamp
+	}
+}
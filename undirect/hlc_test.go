@@ -0,0 +1,208 @@
+package undirect
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestHLC_Now(t *testing.T) {
+
+	t.Run("test ticks when physical clock stands still", func(t *testing.T) {
+
+		hlc := NewHLC(&testCkock{})
+
+		last := hlc.Now().UnixNano()
+		for i := 0; i < 10; i++ {
+			if got := hlc.Now().UnixNano(); got <= last {
+				t.Errorf("HLC.Now() = %v, want greater than %v", got, last)
+			}
+			last = hlc.Now().UnixNano()
+		}
+	})
+
+	t.Run("test ticks when physical clock goes backwards", func(t *testing.T) {
+
+		physical := &testCkock{}
+		physical.AddDuration(10 * time.Minute)
+
+		hlc := NewHLC(physical)
+		before := hlc.Now().UnixNano()
+
+		now := time.Unix(0, 0)
+		physical.now = &now
+
+		if got := hlc.Now().UnixNano(); got <= before {
+			t.Errorf("HLC.Now() = %v, want greater than %v", got, before)
+		}
+	})
+
+	t.Run("test follows physical clock when it is ahead", func(t *testing.T) {
+
+		physical := &testCkock{}
+		hlc := NewHLC(physical)
+		hlc.Now()
+
+		physical.AddDuration(time.Minute)
+
+		if got, want := hlc.Now().UnixNano(), physical.Now().UnixNano(); got != want {
+			t.Errorf("HLC.Now() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestHLC_Observe(t *testing.T) {
+
+	physical := &testCkock{}
+	hlc := NewHLC(physical)
+
+	remote := time.Unix(0, 0).Add(5 * time.Minute).UnixNano()
+	hlc.Observe(remote)
+
+	if got := hlc.Now().UnixNano(); got <= remote {
+		t.Errorf("HLC.Now() = %v, want greater than observed %v", got, remote)
+	}
+
+	// observing an older timestamp should never move the clock backwards
+	last := hlc.Now().UnixNano()
+	hlc.Observe(remote - int64(time.Minute))
+
+	if got := hlc.Now().UnixNano(); got <= last {
+		t.Errorf("HLC.Now() = %v, want greater than %v", got, last)
+	}
+}
+
+// Check the causally later writes win after merge even though the physical clocks of the replicas are minutes apart
+func TestLWWGraphImpl_Merge_With_HLC(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	type operation func(graph LWWGraph)
+
+	addEdge := func(v1, v2 VertexValue) operation {
+		return func(graph LWWGraph) {
+			graph.AddEdge(NewLWWVertex(v1, graph.GetClock(), graph.GetReplica()), NewLWWVertex(v2, graph.GetClock(), graph.GetReplica()))
+		}
+	}
+	addVertex := func(v VertexValue) operation {
+		return func(graph LWWGraph) { graph.AddVertex(v) }
+	}
+	removeVertex := func(v VertexValue) operation {
+		return func(graph LWWGraph) { graph.RemoveVertex(v) }
+	}
+	removeEdge := func(v1, v2 VertexValue) operation {
+		return func(graph LWWGraph) { graph.RemoveEdgeByVertices(v1, v2) }
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		useHLC      bool
+		xSkew       time.Duration
+		ySkew       time.Duration
+		xOperations []operation
+		yOperations []operation
+		want        map[VertexValue][]VertexValue
+	}{
+		{
+			name:        "remove vertex on replica with slower clock",
+			description: "Y removes the vertex after it has seen the add from X, the remove should win",
+			useHLC:      true,
+			xSkew:       10 * time.Minute,
+			ySkew:       5 * time.Minute,
+			xOperations: []operation{addEdge(A, B)},
+			yOperations: []operation{removeVertex(A)},
+			want: map[VertexValue][]VertexValue{
+				B: {},
+			},
+		},
+		{
+			name:        "remove vertex on replica with slower clock without hlc",
+			description: "the physical clock of Y is behind, so the causally later remove is lost",
+			useHLC:      false,
+			xSkew:       10 * time.Minute,
+			ySkew:       5 * time.Minute,
+			xOperations: []operation{addEdge(A, B)},
+			yOperations: []operation{removeVertex(A)},
+			want: map[VertexValue][]VertexValue{
+				A: {B},
+				B: {A},
+			},
+		},
+		{
+			name:        "remove edge on replica with slower clock",
+			description: "Y removes the edge after it has seen the add from X, the remove should win",
+			useHLC:      true,
+			xSkew:       10 * time.Minute,
+			ySkew:       1 * time.Minute,
+			xOperations: []operation{addEdge(A, B)},
+			yOperations: []operation{removeEdge(A, B)},
+			want: map[VertexValue][]VertexValue{
+				A: {},
+				B: {},
+			},
+		},
+		{
+			name:        "add vertex again on replica with slower clock",
+			description: "Y adds the vertex back after it has seen the remove from X, the add should win",
+			useHLC:      true,
+			xSkew:       10 * time.Minute,
+			ySkew:       5 * time.Minute,
+			xOperations: []operation{addVertex(A), addVertex(B), removeVertex(A)},
+			yOperations: []operation{addVertex(A)},
+			want: map[VertexValue][]VertexValue{
+				A: {},
+				B: {},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var (
+				xPhysical = &testCkock{}
+				yPhysical = &testCkock{}
+				xClock    Clock
+				yClock    Clock
+			)
+
+			xPhysical.AddDuration(tt.xSkew)
+			yPhysical.AddDuration(tt.ySkew)
+
+			if tt.useHLC {
+				xClock, yClock = NewHLC(xPhysical), NewHLC(yPhysical)
+			} else {
+				xClock, yClock = xPhysical, yPhysical
+			}
+
+			xGraph := NewLWWGraph(Adds, xClock, "x")
+			yGraph := NewLWWGraph(Adds, yClock, "y")
+
+			for _, op := range tt.xOperations {
+				op(xGraph)
+			}
+
+			yGraph.Merge(xGraph)
+
+			for _, op := range tt.yOperations {
+				op(yGraph)
+			}
+
+			xGraph.Merge(yGraph)
+			yGraph.Merge(xGraph)
+
+			gotX := xGraph.GetAdjacencyVerticesList()
+			gotY := yGraph.GetAdjacencyVerticesList()
+
+			if !reflect.DeepEqual(gotX, gotY) {
+				t.Errorf("LWWGraphImpl.Merge() replicas diverged, x: %v, y: %v, diff: %v", gotX, gotY, deep.Equal(gotX, gotY))
+			}
+			if !reflect.DeepEqual(gotX, tt.want) {
+				t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", gotX, tt.want, deep.Equal(gotX, tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package undirect
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The graph of the ID type orders the IDs for the results that are sorted, like the
+// neighbours, the components and the pairs of the vertices of the edges, so every replica
+// returns the same results for the same state. The IDs of the string and the integer types
+// are ordered as they are, the arrays and the structs are ordered element by element, or
+// field by field, so the composite keys are ordered by the first element or field first.
+//
+// The pointers and the channels are ordered by the addresses, which are not the same for
+// the replicas, so the IDs should be the values instead.
+
+// lessFunc return true when a is before b
+type lessFunc[ID comparable] func(a, b ID) bool
+
+// order is the order of the IDs of the graph, the orders are the empty structs, so the
+// graphs of the same state are deeply equal
+type order[ID comparable] interface {
+	less(a, b ID) bool
+}
+
+// orderedIDs is the order of the types of which the values can be compared by <
+type orderedIDs[ID VertexValue | string | int | int64 | int32 | uint | uint64 | uint32] struct{}
+
+func (orderedIDs[ID]) less(a, b ID) bool {
+	return a < b
+}
+
+// less return true when a is before b by the order of the graph
+func (graph *LWWGraphImplOf[ID]) less(a, b ID) bool {
+	return graph.order.less(a, b)
+}
+
+// reflectedIDs is the order of the other types by reflection
+type reflectedIDs[ID comparable] struct{}
+
+func (reflectedIDs[ID]) less(a, b ID) bool {
+	return compareValues(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()) < 0
+}
+
+// newOrder return the order of the IDs of the type, the common types are ordered without
+// reflection
+func newOrder[ID comparable]() order[ID] {
+
+	for _, o := range []interface{}{
+		orderedIDs[VertexValue]{},
+		orderedIDs[string]{},
+		orderedIDs[int]{},
+		orderedIDs[int64]{},
+		orderedIDs[int32]{},
+		orderedIDs[uint]{},
+		orderedIDs[uint64]{},
+		orderedIDs[uint32]{},
+	} {
+		if o, ok := o.(order[ID]); ok {
+			return o
+		}
+	}
+
+	return reflectedIDs[ID]{}
+}
+
+// compareValues return the order of the values of the same comparable type like strings.Compare
+func compareValues(a, b reflect.Value) int {
+
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Bool:
+		return compareOrdered(boolToInt(a.Bool()), boolToInt(b.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if order := compareOrdered(real(a.Complex()), real(b.Complex())); order != 0 {
+			return order
+		}
+		return compareOrdered(imag(a.Complex()), imag(b.Complex()))
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if order := compareValues(a.Index(i), b.Index(i)); order != 0 {
+				return order
+			}
+		}
+		return 0
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if order := compareValues(a.Field(i), b.Field(i)); order != 0 {
+				return order
+			}
+		}
+		return 0
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return compareOrdered(boolToInt(!a.IsNil()), boolToInt(!b.IsNil()))
+		}
+		a, b = a.Elem(), b.Elem()
+		if a.Type() != b.Type() {
+			return strings.Compare(a.Type().String(), b.Type().String())
+		}
+		return compareValues(a, b)
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return compareOrdered(a.Pointer(), b.Pointer())
+	}
+
+	// the other kinds are not comparable, which are not the IDs
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareOrdered[T int | int64 | uint64 | uintptr | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
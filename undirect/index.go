@@ -0,0 +1,181 @@
+package undirect
+
+import (
+	"sort"
+)
+
+// The index keeps the adjacency of the existing vertices, a vertex is in the index when
+// it exists, and the neighbours of the vertex are the vertices connected by the existing
+// edges, which the edge and both of the vertices exist. Every write to the records
+// refreshes the index for the vertices or the cells it touches, so the queries only go
+// through the neighbours of the vertex instead of the whole matrix.
+
+// refreshVertex update the index for the vertex and the edges connected to it, the vertices
+// connected are refreshed as well when the edges resurrect the vertices, as the existence of
+// them depends on the vertex
+func (graph *LWWGraphImplOf[ID]) refreshVertex(value ID) {
+
+	graph.refreshVertexOnly(value)
+
+	if graph.integrity != ResurrectVertices {
+		return
+	}
+
+	graph.edgesMatrix.RangeRow(value, func(n ID, _ LWWEdgeOf[ID]) bool {
+		graph.refreshVertexOnly(n)
+		return true
+	})
+}
+
+// refreshVertexOnly update the index for the vertex and the edges connected to it
+func (graph *LWWGraphImplOf[ID]) refreshVertexOnly(value ID) {
+
+	if !graph.IsVertexExist(value) {
+		for n := range graph.index[value] {
+			delete(graph.index[n], value)
+		}
+		graph.edgesMatrix.RangeRow(value, func(n ID, _ LWWEdgeOf[ID]) bool {
+			delete(graph.index[n], value)
+			return true
+		})
+		delete(graph.index, value)
+		return
+	}
+
+	if _, ok := graph.index[value]; !ok {
+		graph.index[value] = make(map[ID]struct{})
+	}
+
+	graph.edgesMatrix.RangeRow(value, func(n ID, _ LWWEdgeOf[ID]) bool {
+		graph.refreshEdge(value, n)
+		graph.refreshEdge(n, value)
+		return true
+	})
+}
+
+// refreshEdge update the index for the cell of the matrix, the cell is one direction
+// of the edge, so it should be refreshed for both directions
+func (graph *LWWGraphImplOf[ID]) refreshEdge(m, n ID) {
+
+	if _, ok := graph.index[m]; !ok {
+		return
+	}
+
+	if _, ok := graph.index[n]; ok && graph.isEdgeExist(m, n) {
+		graph.index[m][n] = struct{}{}
+		return
+	}
+
+	delete(graph.index[m], n)
+}
+
+// refreshEdges update the index for both directions of the edge written
+func (graph *LWWGraphImplOf[ID]) refreshEdges(v1, v2 ID) {
+	graph.refreshCell(v1, v2)
+	graph.refreshCell(v2, v1)
+}
+
+// refreshCell update the index for the cell of the matrix, the vertices of the cell are
+// refreshed instead when the edges resurrect the vertices, which refreshes the cell too
+func (graph *LWWGraphImplOf[ID]) refreshCell(m, n ID) {
+
+	if graph.integrity != ResurrectVertices {
+		graph.refreshEdge(m, n)
+		return
+	}
+
+	graph.refreshVertexOnly(m)
+	graph.refreshVertexOnly(n)
+}
+
+// isEdgeExist check the records of the cell only, the vertices are not checked
+func (graph *LWWGraphImplOf[ID]) isEdgeExist(m, n ID) bool {
+
+	edge, ok := graph.edgesMatrix.Get(m, n)
+	if !ok {
+		return false
+	}
+
+	tombstoneEdge, ok := graph.tombstoneEdgesMatrix.Get(m, n)
+	if !ok {
+		return true
+	}
+
+	return graph.IsComponentExist(edge, tombstoneEdge)
+}
+
+// neighbours return the sorted neighbours of the vertex from the index
+func (graph *LWWGraphImplOf[ID]) neighbours(value ID) []ID {
+
+	adj, ok := graph.index[value]
+	if !ok {
+		return nil
+	}
+
+	arr := make([]ID, 0, len(adj))
+	for n := range adj {
+		arr = append(arr, n)
+	}
+
+	sort.Slice(arr, func(i, j int) bool {
+		return graph.less(arr[i], arr[j])
+	})
+
+	return arr
+}
+
+// buildIndex build the index from the records
+func (graph *LWWGraphImplOf[ID]) buildIndex() {
+
+	graph.index = make(map[ID]map[ID]struct{})
+
+	graph.vertices.Range(func(v LWWVertexOf[ID]) bool {
+		if graph.IsVertexExist(v.GetValue()) {
+			graph.index[v.GetValue()] = make(map[ID]struct{})
+		}
+		return true
+	})
+
+	for m := range graph.index {
+		graph.edgesMatrix.RangeRow(m, func(n ID, _ LWWEdgeOf[ID]) bool {
+			graph.refreshEdge(m, n)
+			return true
+		})
+	}
+}
+
+// rebuildAdjacencyVerticesList generate the adjacency vertices list by going through
+// the whole matrix, it is the reference of the index
+func (graph *LWWGraphImplOf[ID]) rebuildAdjacencyVerticesList() map[ID][]ID {
+
+	dict := make(map[ID][]ID)
+
+	for k := range graph.GetVertices() {
+		if !graph.IsVertexExist(k) {
+			continue
+		}
+		dict[k] = []ID{}
+	}
+
+	if len(dict) == 0 {
+		return nil
+	}
+
+	for m, v := range graph.GetEdgesMatrix() {
+		if _, ok := dict[m]; !ok {
+			continue
+		}
+		for n := range v {
+			if !graph.IsVertexExist(n) || !graph.isEdgeExist(m, n) {
+				continue
+			}
+			dict[m] = append(dict[m], n)
+		}
+
+		sort.Slice(dict[m], func(i, j int) bool {
+			return graph.less(dict[m][i], dict[m][j])
+		})
+	}
+
+	return dict
+}
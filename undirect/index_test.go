@@ -0,0 +1,151 @@
+package undirect
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// newRandomReplicas return the replicas with the test clocks
+func newRandomReplicas(bias Bias, replicas ...ReplicaID) []LWWGraph {
+
+	graphs := []LWWGraph{}
+
+	for _, replica := range replicas {
+		graph := NewLWWGraph(bias, &testCkock{}, replica)
+		graph.GetClock().Now()
+		graphs = append(graphs, graph)
+	}
+
+	return graphs
+}
+
+// newRandomValues return the values of the vertices for the random operations
+func newRandomValues(n int) []VertexValue {
+
+	values := []VertexValue{}
+	for i := 0; i < n; i++ {
+		values = append(values, NewVertexValue(fmt.Sprintf("%c", 'A'+i)))
+	}
+
+	return values
+}
+
+// applyRandomOperation apply a random operation to one of the replicas with the test
+// clocks, and return the replica and the name of the operation
+func applyRandomOperation(r *rand.Rand, replicas []LWWGraph, values []VertexValue) (LWWGraph, string) {
+
+	graph := replicas[r.Intn(len(replicas))]
+	clock := graph.GetClock().(*testCkock)
+	// the clock might not move, so the records are written at the same time
+	clock.AddDuration(time.Duration(r.Intn(3)) * time.Second)
+
+	v1, v2 := values[r.Intn(len(values))], values[r.Intn(len(values))]
+
+	switch n := r.Intn(110); {
+	case n < 25:
+		graph.AddVertex(v1)
+		return graph, "add vertex"
+	case n < 40:
+		graph.RemoveVertex(v1)
+		return graph, "remove vertex"
+	case n < 55:
+		graph.AddEdge(NewLWWVertex(v1, clock, graph.GetReplica()), NewLWWVertex(v2, clock, graph.GetReplica()))
+		return graph, "add edge"
+	case n < 65:
+		graph.AddWeightedEdge(NewLWWVertex(v1, clock, graph.GetReplica()), NewLWWVertex(v2, clock, graph.GetReplica()), float64(r.Intn(10)))
+		return graph, "add weighted edge"
+	case n < 80:
+		graph.RemoveEdgeByVertices(v1, v2)
+		return graph, "remove edge"
+	case n < 90:
+		other := replicas[r.Intn(len(replicas))]
+		clock.SyncWith(other.GetClock().(*testCkock))
+		graph.Merge(other)
+		return graph, "merge"
+	case n < 95:
+		other := replicas[r.Intn(len(replicas))]
+		since := other.GetClock().Now().Add(-time.Duration(r.Intn(10)) * time.Second).UnixNano()
+		graph.Merge(other.Delta(since))
+		return graph, "merge delta"
+	case n < 100:
+		graph.GarbageCollect(clock.Now().Add(-5 * time.Second).UnixNano())
+		return graph, "garbage collect"
+	case n < 105:
+		property := fmt.Sprint(r.Intn(3))
+		if r.Intn(2) == 0 {
+			graph.SetVertexProperty(v1, "p"+property, property)
+		} else {
+			graph.SetEdgeProperty(v1, v2, "p"+property, property)
+		}
+		return graph, "set property"
+	default:
+		key := fmt.Sprint("p", r.Intn(3))
+		if r.Intn(2) == 0 {
+			graph.DeleteVertexProperty(v1, key)
+		} else {
+			graph.DeleteEdgeProperty(v1, v2, key)
+		}
+		return graph, "delete property"
+	}
+}
+
+// Check the index is the same as the adjacency vertices list generated from the
+// whole matrix after every operation of the random sequences
+func TestLWWGraphImpl_Index_Random_Operations(t *testing.T) {
+
+	values := newRandomValues(8)
+
+	tests := []struct {
+		name string
+		bias Bias
+		seed int64
+	}{
+		{name: "adds bias", bias: Adds, seed: 1},
+		{name: "removal bias", bias: Removal, seed: 2},
+		{name: "adds bias with another sequence", bias: Adds, seed: 3},
+		{name: "removal bias with another sequence", bias: Removal, seed: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			r := rand.New(rand.NewSource(tt.seed))
+
+			replicas := newRandomReplicas(tt.bias, "x", "y", "z")
+
+			for step := 0; step < 2000; step++ {
+
+				graph, op := applyRandomOperation(r, replicas, values)
+
+				impl := graph.(*LWWGraphImpl)
+				got := impl.GetAdjacencyVerticesList()
+				want := impl.rebuildAdjacencyVerticesList()
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("step %v %v of %v: LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", step, op, graph.GetReplica(), got, want, deep.Equal(got, want))
+				}
+
+				for _, v := range values {
+					var gotEdges, wantEdges []VertexValue
+					for _, e := range graph.GetEdges(v) {
+						vs := e.GetVertices()
+						if vs[0].GetValue().IsEqual(v) {
+							gotEdges = append(gotEdges, vs[1].GetValue())
+						} else {
+							gotEdges = append(gotEdges, vs[0].GetValue())
+						}
+					}
+					if len(want[v]) > 0 {
+						wantEdges = want[v]
+					}
+					if !reflect.DeepEqual(gotEdges, wantEdges) {
+						t.Fatalf("step %v %v of %v: LWWGraphImpl.GetEdges(%v) = %v, want %v", step, op, graph.GetReplica(), v, gotEdges, wantEdges)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package undirect
+
+// The integrity policy decides how the graph shows the edge added by one replica while
+// another replica removes the vertex of it. It only depends on the records, so the replicas
+// of the same policy converge, and it is not kept by the JSON, the snapshot and the log.
+
+// IntegrityPolicy is the way the graph shows the edges of which the vertices are removed
+type IntegrityPolicy int
+
+const (
+	// HideDanglingEdges hide the edges of the vertices removed until the vertices are added
+	// again, which is the default
+	HideDanglingEdges IntegrityPolicy = 0
+	// ResurrectVertices make the vertex removed exist when the edge of it is added later and
+	// the other vertex of the edge exists, removing the edge again removes the vertex
+	ResurrectVertices IntegrityPolicy = 1
+)
+
+// WithIntegrityOf set the integrity policy of the graph
+func WithIntegrityOf[ID comparable](policy IntegrityPolicy) OptionOf[ID] {
+	return func(graph *LWWGraphImplOf[ID]) {
+		if policy != ResurrectVertices {
+			policy = HideDanglingEdges
+		}
+		graph.integrity = policy
+	}
+}
+
+// WithIntegrity set the integrity policy of the graph of the string values
+func WithIntegrity(policy IntegrityPolicy) Option {
+	return WithIntegrityOf[VertexValue](policy)
+}
+
+// GetIntegrity return the integrity policy of the graph
+func (graph *LWWGraphImplOf[ID]) GetIntegrity() IntegrityPolicy {
+	return graph.integrity
+}
+
+// isResurrected check the vertex of the tombstone is resurrected by the edges of it
+func (graph *LWWGraphImplOf[ID]) isResurrected(value ID, tombstone LWWVertexOf[ID]) bool {
+
+	resurrected := false
+
+	graph.edgesMatrix.RangeRow(value, func(n ID, edge LWWEdgeOf[ID]) bool {
+		resurrected = graph.isEdgeExist(value, n) && graph.IsComponentExist(edge, tombstone) && graph.isEndpointAlive(n, edge)
+		return !resurrected
+	})
+
+	return resurrected
+}
+
+// isEndpointAlive check the vertex exists by the records of its own, or the edge is after
+// the tombstone of it, the vertex resurrected by the other edges is not counted, so the
+// existence of the vertex only depends on the records of the vertex, the edges of it and
+// the vertices connected
+func (graph *LWWGraphImplOf[ID]) isEndpointAlive(value ID, edge LWWEdgeOf[ID]) bool {
+
+	v, ok := graph.vertices.Get(value)
+	if !ok {
+		return false
+	}
+
+	tv, ok := graph.tombstoneVertices.Get(value)
+	if !ok {
+		return true
+	}
+
+	return graph.IsComponentExist(v, tv) || graph.IsComponentExist(edge, tv)
+}
@@ -0,0 +1,182 @@
+package undirect
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestLWWGraphImpl_Integrity(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	addEdge := func(graph LWWGraph, v1, v2 VertexValue) {
+		clock := graph.GetClock().(*testCkock)
+		graph.AddEdge(NewLWWVertex(v1, clock, graph.GetReplica()), NewLWWVertex(v2, clock, graph.GetReplica()))
+	}
+
+	// x removes A while y adds the edge of A without seeing the removal
+	removeAndAddEdge := func(removal, add time.Duration) func(x, y LWWGraph, xClock, yClock *testCkock) {
+		return func(x, y LWWGraph, xClock, yClock *testCkock) {
+			addEdge(x, A, B)
+			y.Merge(x)
+			xClock.AddDuration(removal)
+			x.RemoveVertex(A)
+			yClock.AddDuration(add)
+			addEdge(y, A, C)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		policy  IntegrityPolicy
+		operate func(x, y LWWGraph, xClock, yClock *testCkock)
+		want    map[VertexValue][]VertexValue
+	}{
+		{
+			name:    "test hide the edge added after the removal",
+			policy:  HideDanglingEdges,
+			operate: removeAndAddEdge(time.Minute, 2*time.Minute),
+			want:    map[VertexValue][]VertexValue{B: {}, C: {}},
+		},
+		{
+			name:    "test hide the edge added before the removal",
+			policy:  HideDanglingEdges,
+			operate: removeAndAddEdge(2*time.Minute, time.Minute),
+			want:    map[VertexValue][]VertexValue{B: {}, C: {}},
+		},
+		{
+			name:    "test resurrect the vertex by the edge added after the removal",
+			policy:  ResurrectVertices,
+			operate: removeAndAddEdge(time.Minute, 2*time.Minute),
+			want:    map[VertexValue][]VertexValue{A: {C}, B: {}, C: {A}},
+		},
+		{
+			name:    "test hide the edge added before the removal with the resurrection",
+			policy:  ResurrectVertices,
+			operate: removeAndAddEdge(2*time.Minute, time.Minute),
+			want:    map[VertexValue][]VertexValue{B: {}, C: {}},
+		},
+		{
+			name:   "test remove the edge resurrecting the vertex",
+			policy: ResurrectVertices,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				removeAndAddEdge(time.Minute, 2*time.Minute)(x, y, xClock, yClock)
+				y.Merge(x)
+				yClock.AddDuration(time.Minute)
+				y.RemoveEdgeByVertices(A, C)
+			},
+			want: map[VertexValue][]VertexValue{B: {}, C: {}},
+		},
+		{
+			name:   "test not resurrect the vertex by the edge of the vertex removed later",
+			policy: ResurrectVertices,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				x.AddVertex(C)
+				removeAndAddEdge(time.Minute, 2*time.Minute)(x, y, xClock, yClock)
+				// the removal of C does not see the edge, so the edge is not removed
+				xClock.AddDuration(2 * time.Minute)
+				x.RemoveVertex(C)
+			},
+			want: map[VertexValue][]VertexValue{B: {}},
+		},
+	}
+	for _, tt := range tests {
+		for _, bias := range []Bias{Adds, Removal} {
+			t.Run(fmt.Sprintf("%v bias %v", tt.name, bias), func(t *testing.T) {
+
+				xClock, yClock := &testCkock{}, &testCkock{}
+				xClock.Now()
+				yClock.Now()
+				x := NewLWWGraph(bias, xClock, "x", WithIntegrity(tt.policy))
+				y := NewLWWGraph(bias, yClock, "y", WithIntegrity(tt.policy))
+
+				tt.operate(x, y, xClock, yClock)
+				x.Merge(y)
+				y.Merge(x)
+
+				// the copy of the whole graph shows the same graph
+				for _, graph := range []LWWGraph{x, y, x.Delta(math.MinInt64)} {
+					if got := graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, tt.want) {
+						t.Errorf("%v: LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", graph.GetReplica(), got, tt.want, deep.Equal(got, tt.want))
+					}
+				}
+			})
+		}
+	}
+}
+
+// Check the merge is commutative, associative and idempotent, and the replicas converge
+// with every integrity policy on the states of the random operations
+func TestLWWGraphImpl_Integrity_Random_Operations(t *testing.T) {
+
+	values := newRandomValues(5)
+
+	for _, policy := range []IntegrityPolicy{HideDanglingEdges, ResurrectVertices} {
+		for _, bias := range []Bias{Adds, Removal} {
+			for seed := int64(1); seed <= 5; seed++ {
+				t.Run(fmt.Sprintf("policy %v bias %v seed %v", policy, bias, seed), func(t *testing.T) {
+
+					r := rand.New(rand.NewSource(seed))
+					replicas := []LWWGraph{}
+					for _, replica := range []ReplicaID{"x", "y", "z"} {
+						graph := NewLWWGraph(bias, &testCkock{}, replica, WithIntegrity(policy))
+						graph.GetClock().Now()
+						replicas = append(replicas, graph)
+					}
+
+					merged := func(graphs ...LWWGraph) LWWGraph {
+						graph := NewLWWGraph(bias, &testCkock{}, "m", WithIntegrity(policy))
+						for _, other := range graphs {
+							graph.Merge(other)
+						}
+						return graph
+					}
+
+					check := func(step int, name string, got, want LWWGraph) {
+						if got, want := got.GetAdjacencyVerticesList(), want.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+							t.Fatalf("step %v %v: LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", step, name, got, want, deep.Equal(got, want))
+						}
+					}
+
+					for step := 0; step < 500; step++ {
+
+						graph, op := applyRandomOperation(r, replicas, values)
+
+						impl := graph.(*LWWGraphImpl)
+						if got, want := impl.GetAdjacencyVerticesList(), impl.rebuildAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+							t.Fatalf("step %v %v of %v: LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", step, op, graph.GetReplica(), got, want, deep.Equal(got, want))
+						}
+
+						if step%20 != 19 {
+							continue
+						}
+
+						x, y, z := replicas[0], replicas[1], replicas[2]
+						check(step, "commutative", merged(x, y), merged(y, x))
+						check(step, "associative", merged(merged(x, y), z), merged(x, merged(y, z)))
+						check(step, "idempotent", merged(x, x), merged(x))
+					}
+
+					for i := 0; i < 2; i++ {
+						for _, graph := range replicas {
+							for _, other := range replicas {
+								graph.Merge(other)
+							}
+						}
+					}
+					for _, graph := range replicas {
+						check(500, fmt.Sprint(graph.GetReplica(), " converge"), graph, replicas[0])
+					}
+				})
+			}
+		}
+	}
+}
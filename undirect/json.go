@@ -0,0 +1,380 @@
+package undirect
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// The JSON form of the graph keeps every record of the four sets, the registers of the
+// properties and the version vectors along with the biases and the replica id. The clock
+// is not a part of the state, so the graph decoded keeps its own clock. The records are
+// sorted, so the same state is always encoded to the same bytes.
+
+type jsonGraph[ID comparable] struct {
+	Bias Bias `json:"bias"`
+	// EdgeBias is only kept when the bias of the edges is not the bias of the graph
+	EdgeBias          *Bias              `json:"edgeBias,omitempty"`
+	Replica           ReplicaID          `json:"replica"`
+	Vertices          []jsonVertex[ID]   `json:"vertices"`
+	TombstoneVertices []jsonVertex[ID]   `json:"tombstoneVertices"`
+	Edges             []jsonEdge[ID]     `json:"edges"`
+	TombstoneEdges    []jsonEdge[ID]     `json:"tombstoneEdges"`
+	Properties        []jsonProperty[ID] `json:"properties,omitempty"`
+	Versions          []jsonVersion[ID]  `json:"versions,omitempty"`
+}
+
+type jsonVertex[ID comparable] struct {
+	Value     ID        `json:"value"`
+	Timestamp int64     `json:"timestamp"`
+	Replica   ReplicaID `json:"replica"`
+}
+
+// jsonEdge is the cell of the matrix, the cells of both directions are kept,
+// as they are the records of the replicas that can be merged separately
+type jsonEdge[ID comparable] struct {
+	Row       ID               `json:"row"`
+	Column    ID               `json:"column"`
+	Vertices  []jsonVertex[ID] `json:"vertices"`
+	Timestamp int64            `json:"timestamp"`
+	Replica   ReplicaID        `json:"replica"`
+	Weight    *float64         `json:"weight,omitempty"`
+}
+
+// jsonProperty is the register of the key of the owner, the owner is the vertex when
+// there is one value, or the edge of the two values
+type jsonProperty[ID comparable] struct {
+	Owner     []ID      `json:"owner"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	Timestamp int64     `json:"timestamp"`
+	Replica   ReplicaID `json:"replica"`
+}
+
+// jsonVersion is the version vector of the component, the component is the vertex when
+// there is one value, or the edge of the two values
+type jsonVersion[ID comparable] struct {
+	Component []ID          `json:"component"`
+	Version   VersionVector `json:"version"`
+}
+
+func (graph *LWWGraphImplOf[ID]) MarshalJSON() ([]byte, error) {
+
+	var edgeBias *Bias
+	if graph.edgeBias != graph.bias {
+		edgeBias = &graph.edgeBias
+	}
+
+	return json.Marshal(jsonGraph[ID]{
+		Bias:              graph.bias,
+		EdgeBias:          edgeBias,
+		Replica:           graph.replica,
+		Vertices:          graph.encodeJSONVertices(graph.GetVertices()),
+		TombstoneVertices: graph.encodeJSONVertices(graph.GetTombstoneVertices()),
+		Edges:             graph.encodeJSONEdges(graph.GetEdgesMatrix()),
+		TombstoneEdges:    graph.encodeJSONEdges(graph.GetTombstoneEdgesMatrix()),
+		Properties:        graph.encodeJSONProperties(graph.GetProperties()),
+		Versions:          graph.encodeJSONVersions(graph.GetVersions()),
+	})
+}
+
+// UnmarshalJSON replace the state of the graph with the state decoded, the clock and the
+// storage of the graph are kept, or the defaults are used when the graph has none
+func (graph *LWWGraphImplOf[ID]) UnmarshalJSON(data []byte) error {
+
+	if graph.order == nil {
+		graph.order = newOrder[ID]()
+	}
+
+	var decoded jsonGraph[ID]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.Bias != Adds && decoded.Bias != Removal {
+		return fmt.Errorf("undirect: unknown bias %v", decoded.Bias)
+	}
+
+	edgeBias := decoded.Bias
+	if decoded.EdgeBias != nil {
+		edgeBias = *decoded.EdgeBias
+	}
+	if edgeBias != Adds && edgeBias != Removal {
+		return fmt.Errorf("undirect: unknown edge bias %v", edgeBias)
+	}
+
+	vertices := decodeJSONVertices(decoded.Vertices)
+	tombstoneVertices := decodeJSONVertices(decoded.TombstoneVertices)
+
+	edgesMatrix, err := decodeJSONEdges(decoded.Edges)
+	if err != nil {
+		return err
+	}
+
+	tombstoneEdgesMatrix, err := decodeJSONEdges(decoded.TombstoneEdges)
+	if err != nil {
+		return err
+	}
+
+	properties, err := graph.decodeJSONProperties(decoded.Properties)
+	if err != nil {
+		return err
+	}
+
+	versions, err := graph.decodeJSONVersions(decoded.Versions)
+	if err != nil {
+		return err
+	}
+
+	if graph.clock == nil {
+		graph.clock = &clock{}
+	}
+	if graph.vertices == nil {
+		WithStorageOf(NewMapStorageOf[ID]())(graph)
+	}
+	graph.bias = decoded.Bias
+	graph.edgeBias = edgeBias
+	graph.replica = decoded.Replica
+	graph.replaceRecords(vertices, tombstoneVertices, edgesMatrix, tombstoneEdgesMatrix, properties, versions)
+
+	return nil
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) MarshalJSON() ([]byte, error) {
+	graph.mu.RLock()
+	defer graph.mu.RUnlock()
+	return json.Marshal(graph.graph)
+}
+
+func (graph *ConcurrentLWWGraphOf[ID]) UnmarshalJSON(data []byte) error {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	return json.Unmarshal(data, graph.graph)
+}
+
+func encodeJSONVertex[ID comparable](vertex LWWVertexOf[ID]) jsonVertex[ID] {
+	return jsonVertex[ID]{
+		Value:     vertex.GetValue(),
+		Timestamp: vertex.GetTimestamp(),
+		Replica:   vertex.GetReplica(),
+	}
+}
+
+func (graph *LWWGraphImplOf[ID]) encodeJSONVertices(vertices map[ID]LWWVertexOf[ID]) []jsonVertex[ID] {
+
+	arr := []jsonVertex[ID]{}
+
+	for _, v := range vertices {
+		if v != nil {
+			arr = append(arr, encodeJSONVertex(v))
+		}
+	}
+
+	sort.Slice(arr, func(i, j int) bool {
+		return graph.less(arr[i].Value, arr[j].Value)
+	})
+
+	return arr
+}
+
+func (graph *LWWGraphImplOf[ID]) encodeJSONEdges(matrix map[ID]map[ID]LWWEdgeOf[ID]) []jsonEdge[ID] {
+
+	arr := []jsonEdge[ID]{}
+
+	for m := range matrix {
+		for n, e := range matrix[m] {
+			if e == nil {
+				continue
+			}
+			vertices := []jsonVertex[ID]{}
+			for _, v := range e.GetVertices() {
+				vertices = append(vertices, encodeJSONVertex(v))
+			}
+			edge := jsonEdge[ID]{
+				Row:       m,
+				Column:    n,
+				Vertices:  vertices,
+				Timestamp: e.GetTimestamp(),
+				Replica:   e.GetReplica(),
+			}
+			if weight, ok := e.GetWeight(); ok {
+				edge.Weight = &weight
+			}
+			arr = append(arr, edge)
+		}
+	}
+
+	sort.Slice(arr, func(i, j int) bool {
+		if arr[i].Row != arr[j].Row {
+			return graph.less(arr[i].Row, arr[j].Row)
+		}
+		return graph.less(arr[i].Column, arr[j].Column)
+	})
+
+	return arr
+}
+
+func decodeJSONVertex[ID comparable](vertex jsonVertex[ID]) LWWVertexOf[ID] {
+	return &LWWVertexImplOf[ID]{
+		value:     vertex.Value,
+		timestamp: vertex.Timestamp,
+		replica:   vertex.Replica,
+	}
+}
+
+func decodeJSONVertices[ID comparable](arr []jsonVertex[ID]) map[ID]LWWVertexOf[ID] {
+
+	vertices := make(map[ID]LWWVertexOf[ID])
+
+	for _, v := range arr {
+		vertices[v.Value] = decodeJSONVertex(v)
+	}
+
+	return vertices
+}
+
+func decodeJSONEdges[ID comparable](arr []jsonEdge[ID]) (map[ID]map[ID]LWWEdgeOf[ID], error) {
+
+	matrix := make(map[ID]map[ID]LWWEdgeOf[ID])
+
+	for _, e := range arr {
+		if len(e.Vertices) != 2 {
+			return nil, fmt.Errorf("undirect: edge of %v and %v has %v vertices, want 2", e.Row, e.Column, len(e.Vertices))
+		}
+		vertices := []LWWVertexOf[ID]{decodeJSONVertex(e.Vertices[0]), decodeJSONVertex(e.Vertices[1])}
+		setEdge[ID](matrix, e.Row, e.Column, &LWWEdgeImplOf[ID]{
+			vertices:  &vertices,
+			timestamp: e.Timestamp,
+			replica:   e.Replica,
+			weight:    e.Weight,
+		})
+	}
+
+	return matrix, nil
+}
+
+func (graph *LWWGraphImplOf[ID]) encodeJSONProperties(properties map[PropertyOwnerOf[ID]]map[string]LWWProperty) []jsonProperty[ID] {
+
+	arr := []jsonProperty[ID]{}
+
+	for owner := range properties {
+		for key, p := range properties[owner] {
+			if p == nil {
+				continue
+			}
+			values := []ID{owner.Vertices[0]}
+			if owner.Edge {
+				values = append(values, owner.Vertices[1])
+			}
+			arr = append(arr, jsonProperty[ID]{
+				Owner:     values,
+				Key:       key,
+				Value:     p.GetValue(),
+				Deleted:   p.IsDeleted(),
+				Timestamp: p.GetTimestamp(),
+				Replica:   p.GetReplica(),
+			})
+		}
+	}
+
+	sort.Slice(arr, func(i, j int) bool {
+		if len(arr[i].Owner) != len(arr[j].Owner) {
+			return len(arr[i].Owner) < len(arr[j].Owner)
+		}
+		for k := range arr[i].Owner {
+			if arr[i].Owner[k] != arr[j].Owner[k] {
+				return graph.less(arr[i].Owner[k], arr[j].Owner[k])
+			}
+		}
+		return arr[i].Key < arr[j].Key
+	})
+
+	return arr
+}
+
+func (graph *LWWGraphImplOf[ID]) decodeJSONProperties(arr []jsonProperty[ID]) (map[PropertyOwnerOf[ID]]map[string]LWWProperty, error) {
+
+	properties := make(map[PropertyOwnerOf[ID]]map[string]LWWProperty)
+
+	for _, p := range arr {
+		var owner PropertyOwnerOf[ID]
+		switch len(p.Owner) {
+		case 1:
+			owner = vertexOwner(p.Owner[0])
+		case 2:
+			if p.Owner[0] == p.Owner[1] {
+				return nil, fmt.Errorf("undirect: property %q of the edge of %v to itself", p.Key, p.Owner[0])
+			}
+			owner = graph.edgeOwner(p.Owner[0], p.Owner[1])
+		default:
+			return nil, fmt.Errorf("undirect: property %q has %v owners, want 1 or 2", p.Key, len(p.Owner))
+		}
+		if _, ok := properties[owner]; !ok {
+			properties[owner] = make(map[string]LWWProperty)
+		}
+		properties[owner][p.Key] = &LWWPropertyImpl{
+			value:     p.Value,
+			deleted:   p.Deleted,
+			timestamp: p.Timestamp,
+			replica:   p.Replica,
+		}
+	}
+
+	return properties, nil
+}
+
+func (graph *LWWGraphImplOf[ID]) encodeJSONVersions(versions map[PropertyOwnerOf[ID]]VersionVector) []jsonVersion[ID] {
+
+	components := []PropertyOwnerOf[ID]{}
+	for component, vv := range versions {
+		if vv != nil {
+			components = append(components, component)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return graph.lessOwner(components[i], components[j])
+	})
+
+	arr := []jsonVersion[ID]{}
+
+	for _, component := range components {
+		values := []ID{component.Vertices[0]}
+		if component.Edge {
+			values = append(values, component.Vertices[1])
+		}
+		arr = append(arr, jsonVersion[ID]{
+			Component: values,
+			Version:   versions[component],
+		})
+	}
+
+	return arr
+}
+
+func (graph *LWWGraphImplOf[ID]) decodeJSONVersions(arr []jsonVersion[ID]) (map[PropertyOwnerOf[ID]]VersionVector, error) {
+
+	versions := make(map[PropertyOwnerOf[ID]]VersionVector)
+
+	for _, v := range arr {
+		var component PropertyOwnerOf[ID]
+		switch len(v.Component) {
+		case 1:
+			component = vertexOwner(v.Component[0])
+		case 2:
+			if v.Component[0] == v.Component[1] {
+				return nil, fmt.Errorf("undirect: version vector of the edge of %v to itself", v.Component[0])
+			}
+			component = graph.edgeOwner(v.Component[0], v.Component[1])
+		default:
+			return nil, fmt.Errorf("undirect: version vector of %v values, want 1 or 2", len(v.Component))
+		}
+		if v.Version == nil {
+			v.Version = VersionVector{}
+		}
+		versions[component] = v.Version
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,156 @@
+package undirect
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// stateOf return the whole state of the graph for comparing the replicas, the empty
+// rows of the matrices are left out as they are not a part of the state
+func stateOf(graph LWWGraph) map[string]interface{} {
+	return map[string]interface{}{
+		"bias":              graph.GetBias(),
+		"edgeBias":          graph.GetEdgeBias(),
+		"replica":           graph.GetReplica(),
+		"vertices":          graph.GetVertices(),
+		"tombstoneVertices": graph.GetTombstoneVertices(),
+		"edges":             copyEdgesMatrix(graph.GetEdgesMatrix()),
+		"tombstoneEdges":    copyEdgesMatrix(graph.GetTombstoneEdgesMatrix()),
+		"adjacency":         graph.GetAdjacencyVerticesList(),
+		"properties":        copyProperties(graph.GetProperties()),
+		"versions":          copyVersions(graph.GetVersions()),
+	}
+}
+
+func TestLWWGraphImpl_MarshalJSON(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	tests := []struct {
+		name       string
+		bias       Bias
+		operations []mockOperation
+	}{
+		{
+			name: "empty graph",
+			bias: Adds,
+		},
+		{
+			name: "vertices and edges",
+			bias: Adds,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+				{C, mockGraphAddAction, 2 * time.Minute, nil},
+			},
+		},
+		{
+			name: "tombstones with removal bias",
+			bias: Removal,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+				{A, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{B}},
+				{C, mockGraphRemoveAction, 2 * time.Minute, nil},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph := NewLWWGraph(tt.bias, &testCkock{}, "x")
+			applyMockOperations(graph, tt.operations)
+
+			data, err := json.Marshal(graph)
+			if err != nil {
+				t.Fatalf("LWWGraphImpl.MarshalJSON() error = %v", err)
+			}
+
+			decoded := NewLWWGraph(Adds, &testCkock{}, "")
+			if err := json.Unmarshal(data, decoded); err != nil {
+				t.Fatalf("LWWGraphImpl.UnmarshalJSON() error = %v", err)
+			}
+
+			if got, want := stateOf(decoded), stateOf(graph); !reflect.DeepEqual(got, want) {
+				t.Errorf("LWWGraphImpl.UnmarshalJSON() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+			}
+
+			again, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("LWWGraphImpl.MarshalJSON() error = %v", err)
+			}
+			if !bytes.Equal(again, data) {
+				t.Errorf("LWWGraphImpl.MarshalJSON() = %s, want %s", again, data)
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_UnmarshalJSON_Error(t *testing.T) {
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid json", data: `{"bias":`},
+		{name: "unknown bias", data: `{"bias":2}`},
+		{name: "edge without vertices", data: `{"edges":[{"row":"A","column":"B"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := NewLWWGraph(Adds, &testCkock{}, "")
+			if err := json.Unmarshal([]byte(tt.data), graph); err == nil {
+				t.Errorf("LWWGraphImpl.UnmarshalJSON() error = nil, want error")
+			}
+		})
+	}
+}
+
+// Check the graphs decoded are the same as the graphs encoded, and merging the decoded
+// graph into a live replica is the same as merging the graph itself
+func TestLWWGraphImpl_JSON_Round_Trip_Merge(t *testing.T) {
+
+	values := newRandomValues(6)
+
+	for _, bias := range []Bias{Adds, Removal} {
+		for seed := int64(1); seed <= 20; seed++ {
+
+			r := rand.New(rand.NewSource(seed))
+
+			replicas := newRandomReplicas(bias, "x", "y")
+			for step := 0; step < 100; step++ {
+				applyRandomOperation(r, replicas, values)
+			}
+			x, y := replicas[0], replicas[1]
+
+			data, err := json.Marshal(x)
+			if err != nil {
+				t.Fatalf("LWWGraphImpl.MarshalJSON() error = %v", err)
+			}
+
+			decoded := NewLWWGraph(Adds, &testCkock{}, "")
+			if err := json.Unmarshal(data, decoded); err != nil {
+				t.Fatalf("LWWGraphImpl.UnmarshalJSON() error = %v", err)
+			}
+
+			if got, want := stateOf(decoded), stateOf(x); !reflect.DeepEqual(got, want) {
+				t.Fatalf("bias %v seed %v: LWWGraphImpl.UnmarshalJSON() = %v, want %v, diff: %v", bias, seed, got, want, deep.Equal(got, want))
+			}
+
+			// merge into the copies of the live replica, so the replica itself is not changed
+			withDecoded, withGraph := y.Delta(0), y.Delta(0)
+			withDecoded.Merge(decoded)
+			withGraph.Merge(x)
+
+			if got, want := withDecoded.GetAdjacencyVerticesList(), withGraph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+				t.Errorf("bias %v seed %v: LWWGraphImpl.Merge() of decoded graph = %v, want %v, diff: %v", bias, seed, got, want, deep.Equal(got, want))
+			}
+		}
+	}
+}
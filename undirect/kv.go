@@ -0,0 +1,832 @@
+package undirect
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// The key-value storage keeps the records in a file that is only appended, every write of
+// a record is a frame of the record, and every delete is a frame of the key, so the latest
+// frame of the key is the record of the key. The offsets of the latest frames are kept in
+// memory, which is the keydir, so a record is read from the file by one read, and the
+// records recently used are cached. The frames that are not the latest anymore are dropped
+// by Compact. The keydir has the location of every key, and the graph keeps the index of the
+// live adjacency as well, so the memory still grows with the number of the keys.
+//
+// The payload of the frame is the kind, the set of the record, the key, then the record
+// for the write, the key of the edge is the row and the column of the cell, and the key of
+// the property is the owner and the key of the property, and the key of the version vector
+// is the component.
+
+const (
+	kvSet    byte = 1
+	kvDelete byte = 2
+)
+
+const (
+	kvVertices byte = iota
+	kvTombstoneVertices
+	kvEdges
+	kvTombstoneEdges
+	kvProperties
+	kvVersions
+)
+
+// kvCacheSize is the number of the records decoded kept in memory
+const kvCacheSize = 1024
+
+var (
+	// ErrKVCorrupt is the error of the frame of the key-value file that is complete but is
+	// not a record, the corrupt frame followed by the other frames, or the frame that was
+	// changed after the file was opened
+	ErrKVCorrupt = errors.New("undirect: key-value file corrupt")
+
+	errKVClosed = errors.New("undirect: key-value storage closed")
+)
+
+// KVError is the value of the panic of the key-value storage, as the stores have no error to
+// return, the record that can not be read or written is not taken as a missing record
+type KVError struct {
+	Err error
+}
+
+func (e *KVError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *KVError) Unwrap() error {
+	return e.Err
+}
+
+// KVStorage is the storage that keeps the records in a key-value file, the first error of
+// the file is kept and returned by Err, and the reads and the writes of the records after it
+// panic with the *KVError of it. The records can be read concurrently as the reads update the
+// cache under the lock, but the writes should not be concurrent with any other access,
+// which is the same as the maps.
+type KVStorage struct {
+	mu sync.Mutex
+
+	path string
+	// dir is the directory of the temporary file, the file is created by the first write
+	// and removed by Close
+	dir  string
+	temp bool
+
+	file *os.File
+	size int64
+	err  error
+
+	vertices [2]map[VertexValue]kvLocation
+	edges    [2]map[VertexValue]map[VertexValue]kvLocation
+	// properties is the keydir of the registers of the properties
+	properties map[PropertyOwner]map[string]kvLocation
+	// versions is the keydir of the version vectors
+	versions map[PropertyOwner]kvLocation
+
+	cache      map[kvKey]*list.Element
+	cacheOrder *list.List
+
+	buf []byte
+}
+
+// kvLocation is the offset and the size of the latest frame of the key
+type kvLocation struct {
+	offset int64
+	size   int64
+}
+
+// kvKey is the key of the record of the set, the vertices use m, the edges use m and n,
+// the properties use the owner and the key, and the version vectors use the owner
+type kvKey struct {
+	set   byte
+	m, n  VertexValue
+	owner PropertyOwner
+	key   string
+}
+
+type kvCacheEntry struct {
+	key    kvKey
+	record interface{}
+}
+
+func newKVStorage() *KVStorage {
+	return &KVStorage{
+		vertices: [2]map[VertexValue]kvLocation{make(map[VertexValue]kvLocation), make(map[VertexValue]kvLocation)},
+		edges:    [2]map[VertexValue]map[VertexValue]kvLocation{make(map[VertexValue]map[VertexValue]kvLocation), make(map[VertexValue]map[VertexValue]kvLocation)},
+
+		properties: make(map[PropertyOwner]map[string]kvLocation),
+		versions:   make(map[PropertyOwner]kvLocation),
+	}
+}
+
+// OpenKVStorage open the key-value file of the path, the file is created when it is not
+// exist, and the last frame that was not completely written is truncated, ErrKVCorrupt is
+// returned when the corrupt frame is not the last one
+func OpenKVStorage(path string) (*KVStorage, error) {
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	storage := newKVStorage()
+	storage.path = path
+	storage.file = file
+
+	if err := storage.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return storage, nil
+}
+
+// NewTempKVStorage return the key-value storage of a temporary file in the directory, the
+// default directory for temporary files is used when dir is empty, and the file is removed
+// by Close
+func NewTempKVStorage(dir string) *KVStorage {
+	storage := newKVStorage()
+	storage.dir = dir
+	storage.temp = true
+	return storage
+}
+
+// load read the frames of the file into the keydir
+func (storage *KVStorage) load() error {
+
+	fr := newFrameReader(storage.file, 0)
+
+	for {
+		offset := fr.offset
+		payload, err := fr.next()
+		if err == io.EOF {
+			break
+		}
+		if err == errFrameTruncated || err == errFrameCorrupt && fr.tail() {
+			// the tail written partly by the crash, which is dropped
+			if err := storage.file.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+		if err == errFrameCorrupt {
+			// the frames after it are not dropped along with it
+			return ErrKVCorrupt
+		}
+		if err != nil {
+			return err
+		}
+		if err := storage.apply(payload, kvLocation{offset: offset, size: fr.offset - offset}); err != nil {
+			// the frame is complete but the payload is not a record
+			return ErrKVCorrupt
+		}
+	}
+
+	storage.size = fr.offset
+	if _, err := storage.file.Seek(fr.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	return storage.file.Sync()
+}
+
+// apply update the keydir by the payload of the frame at the location
+func (storage *KVStorage) apply(payload []byte, location kvLocation) error {
+
+	d := &payloadDecoder{buf: payload}
+
+	kind, err := d.byte()
+	if err != nil {
+		return err
+	}
+	if kind != kvSet && kind != kvDelete {
+		return errFrameCorrupt
+	}
+
+	key, err := decodeKVKey(d)
+	if err != nil {
+		return err
+	}
+
+	if kind == kvDelete {
+		storage.forget(key)
+		return d.done()
+	}
+
+	if _, err := decodeKVRecord(d, key); err != nil {
+		return err
+	}
+
+	storage.remember(key, location)
+	return d.done()
+}
+
+func (storage *KVStorage) Vertices() VertexStore {
+	return kvVertexStore{storage: storage, set: kvVertices}
+}
+
+func (storage *KVStorage) TombstoneVertices() VertexStore {
+	return kvVertexStore{storage: storage, set: kvTombstoneVertices}
+}
+
+func (storage *KVStorage) Edges() EdgeStore {
+	return kvEdgeStore{storage: storage, set: kvEdges}
+}
+
+func (storage *KVStorage) TombstoneEdges() EdgeStore {
+	return kvEdgeStore{storage: storage, set: kvTombstoneEdges}
+}
+
+func (storage *KVStorage) Properties() PropertyStore {
+	return kvPropertyStore{storage: storage}
+}
+
+func (storage *KVStorage) Versions() VersionStore {
+	return kvVersionStore{storage: storage}
+}
+
+// Err return the first error of the file
+func (storage *KVStorage) Err() error {
+	return storage.err
+}
+
+// Sync commit the records written to the disk
+func (storage *KVStorage) Sync() error {
+	if storage.err != nil {
+		return storage.err
+	}
+	if storage.file == nil {
+		return nil
+	}
+	if err := storage.file.Sync(); err != nil {
+		storage.err = err
+	}
+	return storage.err
+}
+
+// Close close the file, and remove it when it is temporary
+func (storage *KVStorage) Close() error {
+
+	if storage.err == errKVClosed {
+		return nil
+	}
+
+	var err error
+	if storage.file != nil {
+		err = storage.file.Close()
+		if storage.temp {
+			if rerr := os.Remove(storage.path); err == nil {
+				err = rerr
+			}
+		}
+	}
+
+	storage.err = errKVClosed
+
+	return err
+}
+
+// Compact rewrite the file with the latest frames only
+func (storage *KVStorage) Compact() error {
+
+	if storage.err != nil {
+		return storage.err
+	}
+	if storage.file == nil {
+		return nil
+	}
+
+	tmp := storage.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	compacted := newKVStorage()
+	compacted.path = storage.path
+	compacted.file = file
+
+	fail := func(err error) error {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	copyFrame := func(key kvKey, location kvLocation) error {
+		frame := make([]byte, location.size)
+		if _, err := storage.file.ReadAt(frame, location.offset); err != nil {
+			return err
+		}
+		if _, err := file.Write(frame); err != nil {
+			return err
+		}
+		compacted.remember(key, kvLocation{offset: compacted.size, size: location.size})
+		compacted.size += location.size
+		return nil
+	}
+
+	for set, locations := range storage.vertices {
+		for k, location := range locations {
+			if err := copyFrame(kvKey{set: byte(set), m: k}, location); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	for set, matrix := range storage.edges {
+		for m := range matrix {
+			for n, location := range matrix[m] {
+				if err := copyFrame(kvKey{set: byte(set) + kvEdges, m: m, n: n}, location); err != nil {
+					return fail(err)
+				}
+			}
+		}
+	}
+
+	for owner := range storage.properties {
+		for key, location := range storage.properties[owner] {
+			if err := copyFrame(kvKey{set: kvProperties, owner: owner, key: key}, location); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	for component, location := range storage.versions {
+		if err := copyFrame(kvKey{set: kvVersions, owner: component}, location); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := file.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmp, storage.path); err != nil {
+		return fail(err)
+	}
+
+	storage.file.Close()
+	storage.file = file
+	storage.size = compacted.size
+	storage.vertices = compacted.vertices
+	storage.edges = compacted.edges
+	storage.properties = compacted.properties
+	storage.versions = compacted.versions
+
+	return nil
+}
+
+// write append the frame of the payload to the file, the temporary file is created by
+// the first write
+func (storage *KVStorage) write(key kvKey, payload []byte) {
+
+	if storage.err != nil {
+		storage.fail(storage.err)
+	}
+
+	if storage.file == nil {
+		file, err := os.CreateTemp(storage.dir, "lwwgraph-*.kv")
+		if err != nil {
+			storage.fail(err)
+		}
+		storage.file = file
+		storage.path = file.Name()
+	}
+
+	offset := storage.size
+	frame := appendFrame(nil, payload)
+
+	if _, err := storage.file.Write(frame); err != nil {
+		storage.fail(err)
+	}
+	storage.size += int64(len(frame))
+
+	if payload[0] == kvDelete {
+		storage.forget(key)
+	} else {
+		storage.remember(key, kvLocation{offset: offset, size: int64(len(frame))})
+	}
+}
+
+// fail keep the first error of the file and panic with it
+func (storage *KVStorage) fail(err error) {
+	if storage.err == nil {
+		storage.err = err
+	}
+	panic(&KVError{Err: storage.err})
+}
+
+// read return the record of the frame at the location
+func (storage *KVStorage) read(key kvKey, location kvLocation) interface{} {
+
+	if storage.err != nil {
+		storage.fail(storage.err)
+	}
+
+	frame := make([]byte, location.size)
+	if _, err := storage.file.ReadAt(frame, location.offset); err != nil {
+		storage.fail(err)
+	}
+
+	payload, err := newFrameReader(bytes.NewReader(frame), 0).next()
+	if err != nil {
+		storage.fail(ErrKVCorrupt)
+	}
+
+	d := &payloadDecoder{buf: payload}
+	if _, err := d.byte(); err != nil {
+		storage.fail(ErrKVCorrupt)
+	}
+	if _, err := decodeKVKey(d); err != nil {
+		storage.fail(ErrKVCorrupt)
+	}
+
+	record, err := decodeKVRecord(d, key)
+	if err != nil {
+		storage.fail(ErrKVCorrupt)
+	}
+
+	return record
+}
+
+func (storage *KVStorage) location(key kvKey) (kvLocation, bool) {
+	var location kvLocation
+	var ok bool
+	switch {
+	case key.set < kvEdges:
+		location, ok = storage.vertices[key.set][key.m]
+	case key.set < kvProperties:
+		location, ok = storage.edges[key.set-kvEdges][key.m][key.n]
+	case key.set == kvProperties:
+		location, ok = storage.properties[key.owner][key.key]
+	default:
+		location, ok = storage.versions[key.owner]
+	}
+	return location, ok
+}
+
+func (storage *KVStorage) remember(key kvKey, location kvLocation) {
+	switch {
+	case key.set < kvEdges:
+		storage.vertices[key.set][key.m] = location
+	case key.set < kvProperties:
+		matrix := storage.edges[key.set-kvEdges]
+		if _, ok := matrix[key.m]; !ok {
+			matrix[key.m] = make(map[VertexValue]kvLocation)
+		}
+		matrix[key.m][key.n] = location
+	case key.set == kvProperties:
+		if _, ok := storage.properties[key.owner]; !ok {
+			storage.properties[key.owner] = make(map[string]kvLocation)
+		}
+		storage.properties[key.owner][key.key] = location
+	default:
+		storage.versions[key.owner] = location
+	}
+}
+
+func (storage *KVStorage) forget(key kvKey) {
+	storage.uncache(key)
+	switch {
+	case key.set < kvEdges:
+		delete(storage.vertices[key.set], key.m)
+	case key.set < kvProperties:
+		matrix := storage.edges[key.set-kvEdges]
+		delete(matrix[key.m], key.n)
+		if len(matrix[key.m]) == 0 {
+			delete(matrix, key.m)
+		}
+	case key.set == kvProperties:
+		delete(storage.properties[key.owner], key.key)
+		if len(storage.properties[key.owner]) == 0 {
+			delete(storage.properties, key.owner)
+		}
+	default:
+		delete(storage.versions, key.owner)
+	}
+}
+
+// get return the record of the key from the cache, or from the file
+func (storage *KVStorage) get(key kvKey) (interface{}, bool) {
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	location, ok := storage.location(key)
+	if !ok {
+		return nil, false
+	}
+
+	if element, ok := storage.cache[key]; ok {
+		storage.cacheOrder.MoveToFront(element)
+		return element.Value.(*kvCacheEntry).record, true
+	}
+
+	record := storage.read(key, location)
+	storage.store(key, record)
+
+	return record, true
+}
+
+// set write the record of the key, the record is cached as it is, so the record returned
+// by get is the same as the record set until it is evicted
+func (storage *KVStorage) set(key kvKey, record interface{}, encode func([]byte) []byte) {
+
+	storage.buf = append(storage.buf[:0], kvSet)
+	storage.buf = appendKVKey(storage.buf, key)
+	storage.buf = encode(storage.buf)
+
+	storage.write(key, storage.buf)
+	storage.store(key, record)
+}
+
+func (storage *KVStorage) delete(key kvKey) {
+
+	if _, ok := storage.location(key); !ok {
+		return
+	}
+
+	storage.buf = append(storage.buf[:0], kvDelete)
+	storage.buf = appendKVKey(storage.buf, key)
+
+	storage.write(key, storage.buf)
+}
+
+// store put the record to the front of the cache, and evict the least recently used one
+// when the cache is full
+func (storage *KVStorage) store(key kvKey, record interface{}) {
+
+	if storage.cache == nil {
+		storage.cache = make(map[kvKey]*list.Element)
+		storage.cacheOrder = list.New()
+	}
+
+	if element, ok := storage.cache[key]; ok {
+		element.Value.(*kvCacheEntry).record = record
+		storage.cacheOrder.MoveToFront(element)
+		return
+	}
+
+	storage.cache[key] = storage.cacheOrder.PushFront(&kvCacheEntry{key: key, record: record})
+
+	if storage.cacheOrder.Len() > kvCacheSize {
+		storage.uncache(storage.cacheOrder.Back().Value.(*kvCacheEntry).key)
+	}
+}
+
+func (storage *KVStorage) uncache(key kvKey) {
+	if element, ok := storage.cache[key]; ok {
+		storage.cacheOrder.Remove(element)
+		delete(storage.cache, key)
+	}
+}
+
+func appendKVKey(buf []byte, key kvKey) []byte {
+	buf = append(buf, key.set)
+	if key.set == kvProperties {
+		buf = appendPropertyOwner(buf, key.owner)
+		return appendString(buf, key.key)
+	}
+	if key.set == kvVersions {
+		return appendPropertyOwner(buf, key.owner)
+	}
+	buf = appendString(buf, string(key.m))
+	if key.set >= kvEdges {
+		buf = appendString(buf, string(key.n))
+	}
+	return buf
+}
+
+// decodeKVRecord read the record of the set of the key
+func decodeKVRecord(d *payloadDecoder, key kvKey) (interface{}, error) {
+	switch key.set {
+	case kvVertices, kvTombstoneVertices:
+		return decodeLogVertex(d)
+	case kvEdges, kvTombstoneEdges:
+		return decodeLogEdge(d)
+	case kvProperties:
+		return decodeLogProperty(d)
+	default:
+		return decodeVersionVector(d)
+	}
+}
+
+func decodeKVKey(d *payloadDecoder) (kvKey, error) {
+
+	var key kvKey
+
+	set, err := d.byte()
+	if err != nil {
+		return key, err
+	}
+	if set > kvVersions {
+		return key, errFrameCorrupt
+	}
+	key.set = set
+
+	if set == kvProperties {
+		if key.owner, err = decodePropertyOwner(d); err != nil {
+			return key, err
+		}
+		key.key, err = d.string()
+		return key, err
+	}
+
+	if set == kvVersions {
+		key.owner, err = decodePropertyOwner(d)
+		return key, err
+	}
+
+	m, err := d.string()
+	if err != nil {
+		return key, err
+	}
+	key.m = VertexValue(m)
+
+	if set >= kvEdges {
+		n, err := d.string()
+		if err != nil {
+			return key, err
+		}
+		key.n = VertexValue(n)
+	}
+
+	return key, nil
+}
+
+type kvVertexStore struct {
+	storage *KVStorage
+	set     byte
+}
+
+func (store kvVertexStore) Get(value VertexValue) (LWWVertex, bool) {
+	record, ok := store.storage.get(kvKey{set: store.set, m: value})
+	if !ok {
+		return nil, false
+	}
+	return record.(LWWVertex), true
+}
+
+func (store kvVertexStore) Set(vertex LWWVertex) {
+	store.storage.set(kvKey{set: store.set, m: vertex.GetValue()}, vertex, func(buf []byte) []byte {
+		return appendLogVertex(buf, vertex)
+	})
+}
+
+func (store kvVertexStore) Delete(value VertexValue) {
+	store.storage.delete(kvKey{set: store.set, m: value})
+}
+
+func (store kvVertexStore) Range(f func(vertex LWWVertex) bool) {
+	for k := range store.storage.vertices[store.set] {
+		v, ok := store.Get(k)
+		if !ok {
+			continue
+		}
+		if !f(v) {
+			return
+		}
+	}
+}
+
+func (store kvVertexStore) Len() int {
+	return len(store.storage.vertices[store.set])
+}
+
+type kvEdgeStore struct {
+	storage *KVStorage
+	set     byte
+}
+
+func (store kvEdgeStore) Get(m, n VertexValue) (LWWEdge, bool) {
+	record, ok := store.storage.get(kvKey{set: store.set, m: m, n: n})
+	if !ok {
+		return nil, false
+	}
+	return record.(LWWEdge), true
+}
+
+func (store kvEdgeStore) Set(m, n VertexValue, edge LWWEdge) {
+	store.storage.set(kvKey{set: store.set, m: m, n: n}, edge, func(buf []byte) []byte {
+		return appendLogEdge(buf, edge)
+	})
+}
+
+func (store kvEdgeStore) Delete(m, n VertexValue) {
+	store.storage.delete(kvKey{set: store.set, m: m, n: n})
+}
+
+func (store kvEdgeStore) RangeRow(m VertexValue, f func(n VertexValue, edge LWWEdge) bool) {
+	for n := range store.storage.edges[store.set-kvEdges][m] {
+		e, ok := store.Get(m, n)
+		if !ok {
+			continue
+		}
+		if !f(n, e) {
+			return
+		}
+	}
+}
+
+func (store kvEdgeStore) Range(f func(m, n VertexValue, edge LWWEdge) bool) {
+	for m := range store.storage.edges[store.set-kvEdges] {
+		for n := range store.storage.edges[store.set-kvEdges][m] {
+			e, ok := store.Get(m, n)
+			if !ok {
+				continue
+			}
+			if !f(m, n, e) {
+				return
+			}
+		}
+	}
+}
+
+func (store kvEdgeStore) RowLen(m VertexValue) int {
+	return len(store.storage.edges[store.set-kvEdges][m])
+}
+
+type kvPropertyStore struct {
+	storage *KVStorage
+}
+
+func (store kvPropertyStore) Get(owner PropertyOwner, key string) (LWWProperty, bool) {
+	record, ok := store.storage.get(kvKey{set: kvProperties, owner: owner, key: key})
+	if !ok {
+		return nil, false
+	}
+	return record.(LWWProperty), true
+}
+
+func (store kvPropertyStore) Set(owner PropertyOwner, key string, property LWWProperty) {
+	store.storage.set(kvKey{set: kvProperties, owner: owner, key: key}, property, func(buf []byte) []byte {
+		return appendLogProperty(buf, property)
+	})
+}
+
+func (store kvPropertyStore) Delete(owner PropertyOwner, key string) {
+	store.storage.delete(kvKey{set: kvProperties, owner: owner, key: key})
+}
+
+func (store kvPropertyStore) RangeOwner(owner PropertyOwner, f func(key string, property LWWProperty) bool) {
+	for key := range store.storage.properties[owner] {
+		p, ok := store.Get(owner, key)
+		if !ok {
+			continue
+		}
+		if !f(key, p) {
+			return
+		}
+	}
+}
+
+func (store kvPropertyStore) Range(f func(owner PropertyOwner, key string, property LWWProperty) bool) {
+	for owner := range store.storage.properties {
+		for key := range store.storage.properties[owner] {
+			p, ok := store.Get(owner, key)
+			if !ok {
+				continue
+			}
+			if !f(owner, key, p) {
+				return
+			}
+		}
+	}
+}
+
+type kvVersionStore struct {
+	storage *KVStorage
+}
+
+func (store kvVersionStore) Get(component PropertyOwner) (VersionVector, bool) {
+	record, ok := store.storage.get(kvKey{set: kvVersions, owner: component})
+	if !ok {
+		return nil, false
+	}
+	return record.(VersionVector), true
+}
+
+func (store kvVersionStore) Set(component PropertyOwner, version VersionVector) {
+	store.storage.set(kvKey{set: kvVersions, owner: component}, version, func(buf []byte) []byte {
+		return appendVersionVector(buf, version)
+	})
+}
+
+func (store kvVersionStore) Delete(component PropertyOwner) {
+	store.storage.delete(kvKey{set: kvVersions, owner: component})
+}
+
+func (store kvVersionStore) Range(f func(component PropertyOwner, version VersionVector) bool) {
+	for component := range store.storage.versions {
+		vv, ok := store.Get(component)
+		if !ok {
+			continue
+		}
+		if !f(component, vv) {
+			return
+		}
+	}
+}
@@ -1,7 +1,8 @@
 package undirect
 
 import (
-	"sort"
+	"context"
+	"math"
 )
 
 type Bias int
@@ -11,7 +12,9 @@ const (
 	Removal Bias = 1
 )
 
-type LWWGraph interface {
+// LWWGraphOf is the LWW-Element-Graph of which the vertices are identified by the values
+// of the ID type, the graphs of the same ID type can be merged
+type LWWGraphOf[ID comparable] interface {
 
 	// The function check if the vertex is exist in the graph or not.
 	// vertex exist when the vertex is:
@@ -19,177 +22,301 @@ type LWWGraph interface {
 	//   - in vertices list and not in tombstone vertices list
 	//   - in both list but timestamp of record from vertices list is greater
 	//   - in both list and no time difference but with adds bias
-	IsVertexExist(value VertexValue) bool
+	IsVertexExist(value ID) bool
 	// AddVertex check if the record exist, if existed then will udpate the timestamp
 	// of existing record to prevent lost of the relations of the edges.
-	// If not exist, it will be append to vertices list and the matrix and tombstone matrix
-	// wiht null for the diagonal of the matrix.
-	AddVertex(value VertexValue) LWWVertex
+	// If not exist, it will be append to vertices list, the matrices only keep the cells
+	// of the edges, so there is nothing to expend for the vertex.
+	AddVertex(value ID) LWWVertexOf[ID]
 	// It get the vertex when it exist in terms of LWW aspect. Related to IsVertexExist
-	GetVertex(value VertexValue) LWWVertex
+	GetVertex(value ID) LWWVertexOf[ID]
 	// It remove all of the edges connected and the vertex itself if it exist
-	RemoveVertex(value VertexValue)
+	RemoveVertex(value ID)
 
 	// It add the edge when:
 	// 	- the vertices exist
 	// 	- the vertices are not the same vertex
-	// 	then it expends the vertex matix with the connection and the tombstone vertex matrix will null
-	AddEdge(v1, v2 LWWVertex) LWWEdge
-	// It return the edge of the two, by generate an adjacency vertices list
-	// and return the edge that connect with the provided vertices value
-	GetEdge(v1, v2 VertexValue) LWWEdge
+	// 	then it set the cells of the matrix with the connection and clear the cells of the tombstone matrix
+	AddEdge(v1, v2 LWWVertexOf[ID]) LWWEdgeOf[ID]
+	// It add the edge with the weight like AddEdge, the weight is a part of the record of the
+	// edge, so adding the edge again with another weight replace it like the edge itself.
+	// The weight should be a finite number not less than zero, otherwise nil is returned
+	AddWeightedEdge(v1, v2 LWWVertexOf[ID], weight float64) LWWEdgeOf[ID]
+	// It return the edge of the two when the edge and the vertices exist,
+	// by looking up the neighbours of the vertex from the index
+	GetEdge(v1, v2 ID) LWWEdgeOf[ID]
 	// It return the edges that connected with the provided vertex,
-	// by generate an adjacency vertices list and return the edges that connect with the provided value
-	GetEdges(value VertexValue) []LWWEdge
+	// by going through the neighbours of the vertex from the index
+	GetEdges(value ID) []LWWEdgeOf[ID]
 	// it search through the matrix by the DFS function and get all of the paths between start and end
-	GetPaths(start, end VertexValue) [][]VertexValue
+	GetPaths(start, end ID) [][]ID
+	// it pass the simple paths between start and end to f one by one, so the paths are not
+	// kept in memory, the paths longer than maxLength edges are skipped and it stops after
+	// maxResults paths, when f return false or when the context is done, the error of the
+	// context is returned then. The limits are not applied when they are not greater than zero
+	WalkPaths(ctx context.Context, start, end ID, maxLength, maxResults int, f func(path []ID) bool) error
+	// it return the path of the least total weight between start and end and the total weight,
+	// the edge without weight weighs 1, nil is returned when there is no path
+	ShortestPath(start, end ID) ([]ID, float64)
+	// it return the path of the least edges between start and end by BFS, the weights are
+	// not considered, nil is returned when there is no path
+	ShortestPathHops(start, end ID) []ID
+	// it return the number of the edges of the shortest path from the vertex to every
+	// vertex reachable, including the vertex itself
+	Distances(from ID) map[ID]int
+	// it return the vertices of every connected component, which are the vertices connected
+	// by the existing edges, the components and the vertices of them are sorted
+	ConnectedComponents() [][]ID
+	// it return the sorted vertices of the connected component of the vertex
+	ComponentOf(value ID) []ID
+	// it check if there is a path between the vertices through the existing edges
+	IsReachable(v1, v2 ID) bool
+	// it check if there is a cycle of the existing edges, which is the graph is not a forest
+	HasCycle() bool
+	// it return the vertices of a cycle in the order of the cycle, nil is returned when there is no cycle
+	FindCycle() []ID
+	// it return the edges of the spanning tree of every connected component by the mode
+	SpanningForest(mode SpanningMode) [][2]ID
+	// it return the existing edges of which the removal disconnects the component of them
+	Bridges() [][2]ID
+	// it return the existing vertices of which the removal disconnects the component of them
+	ArticulationPoints() []ID
 	// it update the tombstone if the vertex exist
-	RemoveEdgeByVertices(v1, v2 VertexValue)
-
-	// it merge the other graph when the component timestamp is smaller
-	Merge(other LWWGraph)
+	RemoveEdgeByVertices(v1, v2 ID)
+
+	// It set the property of the vertex when the vertex exist, every key of the properties
+	// is resolved on its own by the order of the records, nil is returned when it is not set
+	SetVertexProperty(value ID, key, property string) LWWProperty
+	// It delete the property of the vertex when the vertex has the property
+	DeleteVertexProperty(value ID, key string)
+	// It return the property of the vertex when the vertex exist and has the property
+	GetVertexProperty(value ID, key string) (string, bool)
+	// It return the properties of the vertex, nil is returned when the vertex is not exist
+	GetVertexProperties(value ID) map[string]string
+	// It set the property of the edge when the edge exist, the properties of both
+	// directions of the edge are the same
+	SetEdgeProperty(v1, v2 ID, key, property string) LWWProperty
+	// It delete the property of the edge when the edge has the property
+	DeleteEdgeProperty(v1, v2 ID, key string)
+	// It return the property of the edge when the edge exist and has the property
+	GetEdgeProperty(v1, v2 ID, key string) (string, bool)
+	// It return the properties of the edge, nil is returned when the edge is not exist
+	GetEdgeProperties(v1, v2 ID) map[string]string
+
+	// it merge the other graph when the component timestamp is smaller, and the version
+	// vectors of the vertices and the edges, the conflicts of them are reported to the
+	// graph created with WithVersionVectors
+	Merge(other LWWGraphOf[ID])
+	// it merge the other graph like Merge, and return the vertices and the edges added,
+	// removed, resurrected or overwritten by the merge along with the conflicts
+	MergeWithReport(other LWWGraphOf[ID]) MergeReportOf[ID]
 	// get the adjacency vertices of every vertex
-	GetAdjacencyVerticesList() map[VertexValue][]VertexValue
-	// The function check if the component is exist in the graph or not logically by timestamp
+	GetAdjacencyVerticesList() map[ID][]ID
+	// The function check if the component is exist in the graph or not logically by
+	// the order of the records, which is the timestamp then the replica id
+	//
+	// component exist when the add record is:
+	//   - after the remove record
+	//   - adds bias when no difference in terms of timestamp and replica
 	//
-	// component exist when the timestamp is:
-	//   - add timestamp is greater
-	//   - adds bias when no difference in terms of timestamp
-	IsComponentExist(add, remove int64) bool
-	// It return the connected vertices, by generate an adjacency vertices list
-	// and return the vertices that connect with the provided vertex value
-	GetConnectedVertices(value VertexValue) []LWWVertex
-	// retrieve the graph bias
+	// the bias of the edges is used when the remove record is the edge, or the bias of
+	// the vertices otherwise
+	IsComponentExist(add, remove Component) bool
+	// It return the connected vertices, by going through the neighbours
+	// of the vertex from the index
+	GetConnectedVertices(value ID) []LWWVertexOf[ID]
+	// it return the records written after the since timestamp as a graph that can be merged
+	// into any replica, so the replicas can exchange the changes instead of the whole graph
+	Delta(since int64) LWWGraphOf[ID]
+	// it purges the tombstones and the removed records at or before the stable timestamp,
+	// which is the minimum timestamp acknowledged by all known replicas
+	GarbageCollect(stable int64)
+	// retrieve the bias of the vertices, which is the bias of the graph
 	GetBias() Bias
+	// retrieve the bias of the edges, which is the bias of the graph unless it is
+	// created with WithEdgeBias
+	GetEdgeBias() Bias
 	// retrieve the graph clock
 	GetClock() Clock
+	// retrieve the id of the replica
+	GetReplica() ReplicaID
 	// retrieve the graph vertices
-	GetVertices() map[VertexValue]LWWVertex
+	GetVertices() map[ID]LWWVertexOf[ID]
 	// retrieve the graph tombstone vertices list
-	GetTombstoneVertices() map[VertexValue]LWWVertex
+	GetTombstoneVertices() map[ID]LWWVertexOf[ID]
 	// retrieve the graph edge matrix
-	GetEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge
+	GetEdgesMatrix() map[ID]map[ID]LWWEdgeOf[ID]
 	// retrieve the graph edge tombstone matrix
-	GetTombstoneEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge
+	GetTombstoneEdgesMatrix() map[ID]map[ID]LWWEdgeOf[ID]
+	// retrieve the registers of the properties, including the deleted ones
+	GetProperties() map[PropertyOwnerOf[ID]]map[string]LWWProperty
+	// retrieve the version vectors of the vertices and the edges
+	GetVersions() map[PropertyOwnerOf[ID]]VersionVector
 }
 
-type LWWGraphImpl struct {
+// LWWGraph is the graph of which the vertices are the string values
+type LWWGraph = LWWGraphOf[VertexValue]
+
+type LWWGraphImplOf[ID comparable] struct {
 	clock                Clock
 	bias                 Bias
-	vertices             map[VertexValue]LWWVertex
-	tombstoneVertices    map[VertexValue]LWWVertex
-	edgesMatrix          map[VertexValue]map[VertexValue]LWWEdge
-	tombstoneEdgesMatrix map[VertexValue]map[VertexValue]LWWEdge
+	edgeBias             Bias
+	replica              ReplicaID
+	vertices             VertexStoreOf[ID]
+	tombstoneVertices    VertexStoreOf[ID]
+	edgesMatrix          EdgeStoreOf[ID]
+	tombstoneEdgesMatrix EdgeStoreOf[ID]
+	properties           PropertyStoreOf[ID]
+	versions             VersionStoreOf[ID]
+	// versioned is true when the graph writes the version vectors, and report is called
+	// with the conflicts of the merge
+	versioned bool
+	report    func(conflicts []ConflictOf[ID])
+	// integrity is the way the edges of the vertices removed are shown
+	integrity IntegrityPolicy
+	// order is the order of the IDs for the results that are sorted
+	order order[ID]
+	// index is the live adjacency of the existing vertices, it is updated along
+	// with the records, so the neighbours can be retrieved without going through the matrix
+	index map[ID]map[ID]struct{}
 }
 
-func NewLWWGraph(bias Bias, clockImpl Clock) LWWGraph {
+type LWWGraphImpl = LWWGraphImplOf[VertexValue]
+
+// NewLWWGraphOf return the graph of the replica of which the vertices are of the ID type,
+// the replica id is stamped on every record written by the graph to make the order of the
+// records written at the same time deterministic, so the replicas should have different ids
+func NewLWWGraphOf[ID comparable](bias Bias, clockImpl Clock, replica ReplicaID, options ...OptionOf[ID]) LWWGraphOf[ID] {
 	if bias != Adds && bias != Removal {
 		bias = Adds
 	}
 	if clockImpl == nil {
 		clockImpl = &clock{}
 	}
-	return &LWWGraphImpl{
-		clock:                clockImpl,
-		bias:                 bias,
-		vertices:             make(map[VertexValue]LWWVertex),
-		tombstoneVertices:    make(map[VertexValue]LWWVertex),
-		edgesMatrix:          make(map[VertexValue]map[VertexValue]LWWEdge),
-		tombstoneEdgesMatrix: make(map[VertexValue]map[VertexValue]LWWEdge),
+	graph := &LWWGraphImplOf[ID]{
+		clock:    clockImpl,
+		bias:     bias,
+		edgeBias: bias,
+		replica:  replica,
+		order:    newOrder[ID](),
+	}
+	WithStorageOf(NewMapStorageOf[ID]())(graph)
+	for _, option := range options {
+		option(graph)
 	}
+	graph.buildIndex()
+	return graph
 }
 
-func (graph *LWWGraphImpl) AddVertex(value VertexValue) LWWVertex {
-
-	vertex := NewLWWVertex(value, graph.clock)
+// NewLWWGraph return the graph of the replica of which the vertices are the string values
+func NewLWWGraph(bias Bias, clockImpl Clock, replica ReplicaID, options ...Option) LWWGraph {
+	return NewLWWGraphOf(bias, clockImpl, replica, options...)
+}
 
-	if graph.IsVertexExist(value) {
-		graph.vertices[vertex.GetValue()].SetTimestamp(graph.clock.Now().UnixNano())
-		return graph.GetVertex(value)
+// WithEdgeBiasOf set the bias of the edges, so the vertices and the edges can have the
+// different biases, the bias given to the graph is the bias of the vertices then
+func WithEdgeBiasOf[ID comparable](bias Bias) OptionOf[ID] {
+	return func(graph *LWWGraphImplOf[ID]) {
+		if bias != Adds && bias != Removal {
+			bias = Adds
+		}
+		graph.edgeBias = bias
 	}
+}
 
-	graph.vertices[vertex.GetValue()] = vertex
+// WithEdgeBias set the bias of the edges of the graph of the string values
+func WithEdgeBias(bias Bias) Option {
+	return WithEdgeBiasOf[VertexValue](bias)
+}
 
-	for m := range graph.vertices {
-		for n := range graph.vertices {
-			if v, ok := graph.edgesMatrix[m]; !ok {
-				graph.edgesMatrix[m] = map[VertexValue]LWWEdge{n: nil}
-			} else if _, ok := v[n]; !ok {
-				graph.edgesMatrix[m][n] = nil
-			}
-			if v, ok := graph.tombstoneEdgesMatrix[m]; !ok {
-				graph.tombstoneEdgesMatrix[m] = map[VertexValue]LWWEdge{n: nil}
-			} else if _, ok := v[n]; !ok {
-				graph.tombstoneEdgesMatrix[m][n] = nil
-			}
+func (graph *LWWGraphImplOf[ID]) AddVertex(value ID) LWWVertexOf[ID] {
+
+	vertex := NewLWWVertexOf(value, graph.clock, graph.replica)
+
+	if graph.IsVertexExist(value) {
+		existing, _ := graph.vertices.Get(value)
+		// the existing record might be merged from the replica of which the clock is ahead,
+		// it is kept then, as moving it back might put it before the tombstone
+		if CompareComponents(existing, vertex) < 0 {
+			existing.SetTimestamp(vertex.GetTimestamp())
+			existing.SetReplica(vertex.GetReplica())
+			graph.vertices.Set(existing)
+			graph.writeVersion(vertexOwner(value))
 		}
+		return existing
 	}
 
+	graph.vertices.Set(vertex)
+	graph.writeVersion(vertexOwner(value))
+	graph.refreshVertex(value)
+
 	return vertex
 }
 
-func (graph *LWWGraphImpl) IsVertexExist(value VertexValue) bool {
+func (graph *LWWGraphImplOf[ID]) IsVertexExist(value ID) bool {
 
-	v, ok := graph.vertices[value]
+	v, ok := graph.vertices.Get(value)
 	if !ok {
 		return false
 	}
 
-	tv, ok := graph.tombstoneVertices[value]
+	tv, ok := graph.tombstoneVertices.Get(value)
 	if !ok {
 		return true
 	}
 
-	switch graph.bias {
-	case Removal:
-		// when vertex adds timestamp is greater than removal timestamp, it exists
-		return v.GetTimestamp() > tv.GetTimestamp()
-	default: // Adds
-		// when vertex adds timestamp is greater than or equal to removal timestamp, it exists
-		return v.GetTimestamp() >= tv.GetTimestamp()
+	if graph.IsComponentExist(v, tv) {
+		return true
 	}
+
+	return graph.integrity == ResurrectVertices && graph.isResurrected(value, tv)
 }
 
-func (graph *LWWGraphImpl) IsComponentExist(add, remove int64) bool {
+func (graph *LWWGraphImplOf[ID]) IsComponentExist(add, remove Component) bool {
+
+	order := CompareComponents(add, remove)
+
+	bias := graph.bias
+	if _, ok := remove.(LWWEdgeOf[ID]); ok {
+		bias = graph.edgeBias
+	}
 
-	switch graph.bias {
+	switch bias {
 	case Removal:
-		// when vertex adds timestamp is greater than removal timestamp, it exists
-		return add > remove
+		// when adds record is after removal record, it exists
+		return order > 0
 	default: // Adds
-		// when vertex adds timestamp is greater than or equal to removal timestamp, it exists
-		return add >= remove
+		// when adds record is after or the same as removal record, it exists
+		return order >= 0
 	}
 }
 
-func (graph *LWWGraphImpl) GetVertex(value VertexValue) LWWVertex {
+func (graph *LWWGraphImplOf[ID]) GetVertex(value ID) LWWVertexOf[ID] {
 
 	if graph.IsVertexExist(value) {
-		return graph.vertices[value]
+		v, _ := graph.vertices.Get(value)
+		return v
 	}
 
 	return nil
 }
 
-func (graph *LWWGraphImpl) GetConnectedVertices(value VertexValue) []LWWVertex {
+func (graph *LWWGraphImplOf[ID]) GetConnectedVertices(value ID) []LWWVertexOf[ID] {
 
 	if !graph.IsVertexExist(value) {
 		return nil
 	}
 
-	var (
-		matrix = graph.GetAdjacencyVerticesList()
-		arr    = []LWWVertex{}
-	)
+	arr := []LWWVertexOf[ID]{}
 
-	for _, v := range matrix[value] {
+	for _, v := range graph.neighbours(value) {
 		arr = append(arr, graph.GetVertex(v))
 	}
 
 	return arr
 }
 
-func (graph *LWWGraphImpl) RemoveVertex(value VertexValue) {
+func (graph *LWWGraphImplOf[ID]) RemoveVertex(value ID) {
 
 	// normally, it is not a metter to append of update the remove set
 	// but as the vertex itself might has dependences(edges) in other
@@ -200,21 +327,40 @@ func (graph *LWWGraphImpl) RemoveVertex(value VertexValue) {
 	}
 
 	vertices := graph.GetConnectedVertices(value)
-	graph.tombstoneVertices[value] = NewLWWVertex(value, graph.clock)
+	graph.tombstoneVertices.Set(NewLWWVertexOf(value, graph.clock, graph.replica))
+	graph.writeVersion(vertexOwner(value))
 
 	for i := 0; i < len(vertices); i++ {
-		edgeVertices := graph.edgesMatrix[value][vertices[i].GetValue()].GetVertices()
-		removeEdge := NewLWWEdgeImpl([]LWWVertex{edgeVertices[0], edgeVertices[1]}, graph.clock)
-		graph.tombstoneEdgesMatrix[edgeVertices[0].GetValue()][edgeVertices[1].GetValue()] = removeEdge
-		graph.tombstoneEdgesMatrix[edgeVertices[1].GetValue()][edgeVertices[0].GetValue()] = removeEdge
+		edge, _ := graph.edgesMatrix.Get(value, vertices[i].GetValue())
+		edgeVertices := edge.GetVertices()
+		removeEdge := NewLWWEdgeImplOf([]LWWVertexOf[ID]{edgeVertices[0], edgeVertices[1]}, graph.clock, graph.replica)
+		graph.tombstoneEdgesMatrix.Set(edgeVertices[0].GetValue(), edgeVertices[1].GetValue(), removeEdge)
+		graph.tombstoneEdgesMatrix.Set(edgeVertices[1].GetValue(), edgeVertices[0].GetValue(), removeEdge)
+		graph.writeVersion(graph.edgeOwner(edgeVertices[0].GetValue(), edgeVertices[1].GetValue()))
 	}
 
+	graph.refreshVertex(value)
+
 	return
 }
 
-func (graph *LWWGraphImpl) AddEdge(v1, v2 LWWVertex) LWWEdge {
+func (graph *LWWGraphImplOf[ID]) AddEdge(v1, v2 LWWVertexOf[ID]) LWWEdgeOf[ID] {
+	return graph.addEdge(v1, v2, nil)
+}
+
+func (graph *LWWGraphImplOf[ID]) AddWeightedEdge(v1, v2 LWWVertexOf[ID], weight float64) LWWEdgeOf[ID] {
+
+	if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return nil
+	}
+
+	return graph.addEdge(v1, v2, &weight)
+}
+
+// addEdge add the edge with the weight, or without weight when it is nil
+func (graph *LWWGraphImplOf[ID]) addEdge(v1, v2 LWWVertexOf[ID], weight *float64) LWWEdgeOf[ID] {
 
-	if v1.GetValue().IsEqual(v2.GetValue()) {
+	if v1.GetValue() == v2.GetValue() {
 		return nil
 	}
 
@@ -226,64 +372,69 @@ func (graph *LWWGraphImpl) AddEdge(v1, v2 LWWVertex) LWWEdge {
 		v2 = graph.AddVertex(v2.GetValue())
 	}
 
-	edge := NewLWWEdgeImpl([]LWWVertex{v1, v2}, graph.clock)
+	var edge LWWEdgeOf[ID]
+	if weight != nil {
+		edge = NewWeightedLWWEdgeImplOf([]LWWVertexOf[ID]{v1, v2}, *weight, graph.clock, graph.replica)
+	} else {
+		edge = NewLWWEdgeImplOf([]LWWVertexOf[ID]{v1, v2}, graph.clock, graph.replica)
+	}
+
+	graph.edgesMatrix.Set(v1.GetValue(), v2.GetValue(), edge)
+	graph.edgesMatrix.Set(v2.GetValue(), v1.GetValue(), edge)
 
-	graph.edgesMatrix[v1.GetValue()][v2.GetValue()] = edge
-	graph.edgesMatrix[v2.GetValue()][v1.GetValue()] = edge
+	graph.tombstoneEdgesMatrix.Delete(v1.GetValue(), v2.GetValue())
+	graph.tombstoneEdgesMatrix.Delete(v2.GetValue(), v1.GetValue())
+	graph.writeVersion(graph.edgeOwner(v1.GetValue(), v2.GetValue()))
 
-	graph.tombstoneEdgesMatrix[v1.GetValue()][v2.GetValue()] = nil
-	graph.tombstoneEdgesMatrix[v2.GetValue()][v1.GetValue()] = nil
+	graph.refreshEdges(v1.GetValue(), v2.GetValue())
 
 	return edge
 }
 
-func (graph *LWWGraphImpl) GetEdge(v1, v2 VertexValue) LWWEdge {
-
-	if !graph.IsVertexExist(v1) || !graph.IsVertexExist(v2) {
-		return nil
-	}
-
-	dict := graph.GetAdjacencyVerticesList()
-	if _, ok := dict[v1]; !ok {
-		return nil
-	}
+func (graph *LWWGraphImplOf[ID]) GetEdge(v1, v2 ID) LWWEdgeOf[ID] {
 
-	if _, ok := dict[v2]; !ok {
+	if _, ok := graph.index[v1][v2]; !ok {
 		return nil
 	}
 
-	return graph.edgesMatrix[v1][v2]
+	edge, _ := graph.edgesMatrix.Get(v1, v2)
+	return edge
 }
 
-func (graph *LWWGraphImpl) GetEdges(value VertexValue) []LWWEdge {
+func (graph *LWWGraphImplOf[ID]) GetEdges(value ID) []LWWEdgeOf[ID] {
 
 	if !graph.IsVertexExist(value) {
 		return nil
 	}
 
-	edges := []LWWEdge{}
+	edges := []LWWEdgeOf[ID]{}
 
-	dict := graph.GetAdjacencyVerticesList()
-	if adj, ok := dict[value]; !ok || len(adj) == 0 {
+	adj := graph.neighbours(value)
+	if len(adj) == 0 {
 		return nil
 	}
 
-	for i := 0; i < len(dict[value]); i++ {
-		edge := graph.edgesMatrix[value][dict[value][i]]
+	for i := 0; i < len(adj); i++ {
+		edge, _ := graph.edgesMatrix.Get(value, adj[i])
 		edges = append(edges, edge)
 	}
 
 	return edges
 }
 
-func (graph *LWWGraphImpl) GetPaths(v1, v2 VertexValue) [][]VertexValue {
+func (graph *LWWGraphImplOf[ID]) GetPaths(v1, v2 ID) [][]ID {
 	dfs := graph.NewDFS(v1, v2)
 	return dfs.Search()
 }
 
-func (graph *LWWGraphImpl) RemoveEdgeByVertices(v1, v2 VertexValue) {
+func (graph *LWWGraphImplOf[ID]) WalkPaths(ctx context.Context, start, end ID, maxLength, maxResults int, f func(path []ID) bool) error {
+	dfs := graph.NewDFS(start, end)
+	return dfs.Walk(ctx, maxLength, maxResults, f)
+}
+
+func (graph *LWWGraphImplOf[ID]) RemoveEdgeByVertices(v1, v2 ID) {
 
-	if v1.IsEqual(v2) {
+	if v1 == v2 {
 		return
 	}
 
@@ -294,61 +445,131 @@ func (graph *LWWGraphImpl) RemoveEdgeByVertices(v1, v2 VertexValue) {
 		return
 	}
 
-	if _, ok := graph.edgesMatrix[v1][v2]; !ok {
+	// the tombstone is written even when the edge has not been added, which is what the dense
+	// matrix did with the empty cells of the vertices, as the edge might be added by the other
+	// replica before the removal, and the record is not merged yet
+	edge := NewLWWEdgeImplOf([]LWWVertexOf[ID]{vertex1, vertex2}, graph.clock, graph.replica)
+
+	if te, ok := graph.tombstoneEdgesMatrix.Get(v1, v2); ok && CompareComponents(te, edge) >= 0 {
 		return
 	}
 
-	edge := NewLWWEdgeImpl([]LWWVertex{vertex1, vertex2}, graph.clock)
+	graph.tombstoneEdgesMatrix.Set(v1, v2, edge)
+	graph.tombstoneEdgesMatrix.Set(v2, v1, edge)
+	graph.writeVersion(graph.edgeOwner(v1, v2))
+
+	graph.refreshEdges(v1, v2)
+}
+
+func (graph *LWWGraphImplOf[ID]) Merge(other LWWGraphOf[ID]) {
+	graph.merge(other, nil)
+}
 
-	if te, ok := graph.tombstoneEdgesMatrix[v1][v2]; !ok || te == nil {
-		graph.tombstoneEdgesMatrix[v1][v2] = edge
-		graph.tombstoneEdgesMatrix[v2][v1] = edge
+// merge merge the other graph, and fill the report of the changes when it is not nil
+func (graph *LWWGraphImplOf[ID]) merge(other LWWGraphOf[ID], report *MergeReportOf[ID]) {
+	// let the clock know the latest write of the other replica, so that the
+	// following local writes are ordered after the merged state
+	if clock, ok := graph.clock.(ObservingClock); ok {
+		clock.Observe(latestTimestamp(other))
+	}
+	records := newMergedRecords(other)
+	// the version vectors are merged first, so the conflicts have the records of the
+	// replica before the merge
+	conflicts := graph.mergeVersions(other.GetVersions(), records, graph.report != nil || report != nil)
+	var before map[PropertyOwnerOf[ID]]componentBefore
+	if report != nil {
+		before = graph.recordsBefore(records)
+	}
+	vertices := mergeVertices(graph.vertices, records.vertices)
+	vertices = append(vertices, mergeVertices(graph.tombstoneVertices, records.tombstoneVertices)...)
+	cells := mergeEdgesMatrix(graph.edgesMatrix, records.edges)
+	cells = append(cells, mergeEdgesMatrix(graph.tombstoneEdgesMatrix, records.tombstoneEdges)...)
+	mergeProperties(graph.properties, other.GetProperties(), graph.propertyBias)
+
+	// the index is refreshed after all of the records are merged,
+	// as the existence of the edges depends on the vertices
+	for _, v := range vertices {
+		graph.refreshVertex(v)
+	}
+	for _, cell := range cells {
+		graph.refreshCell(cell[0], cell[1])
+	}
+
+	for i := range conflicts {
+		conflicts[i].Exists = graph.isOwnerExist(conflicts[i].Component)
+	}
+	if report != nil {
+		report.Changes = graph.reportChanges(vertices, cells, before, records)
+		if len(conflicts) > 0 {
+			report.Conflicts = conflicts
+		}
+	}
+	if len(conflicts) == 0 || graph.report == nil {
 		return
 	}
+	graph.report(conflicts)
+}
 
-	if graph.tombstoneEdgesMatrix[v1][v2].GetTimestamp() < edge.GetTimestamp() {
-		graph.tombstoneEdgesMatrix[v1][v2] = edge
-		graph.tombstoneEdgesMatrix[v2][v1] = edge
-		return
+// latestTimestamp return the greatest timestamp of the components of the graph
+func latestTimestamp[ID comparable](graph LWWGraphOf[ID]) int64 {
+
+	var latest int64
+
+	for _, vertices := range []map[ID]LWWVertexOf[ID]{graph.GetVertices(), graph.GetTombstoneVertices()} {
+		for _, v := range vertices {
+			if v != nil && v.GetTimestamp() > latest {
+				latest = v.GetTimestamp()
+			}
+		}
 	}
 
-	return
-}
+	for _, matrix := range []map[ID]map[ID]LWWEdgeOf[ID]{graph.GetEdgesMatrix(), graph.GetTombstoneEdgesMatrix()} {
+		for m := range matrix {
+			for _, e := range matrix[m] {
+				if e != nil && e.GetTimestamp() > latest {
+					latest = e.GetTimestamp()
+				}
+			}
+		}
+	}
+
+	properties := graph.GetProperties()
+	for owner := range properties {
+		for _, p := range properties[owner] {
+			if p != nil && p.GetTimestamp() > latest {
+				latest = p.GetTimestamp()
+			}
+		}
+	}
 
-func (graph *LWWGraphImpl) Merge(other LWWGraph) {
-	graph.vertices = mergeVertices(graph.vertices, other.GetVertices())
-	graph.tombstoneVertices = mergeVertices(graph.tombstoneVertices, other.GetTombstoneVertices())
-	graph.edgesMatrix = mergeEdgesMatrix(graph.edgesMatrix, other.GetEdgesMatrix())
-	graph.tombstoneEdgesMatrix = mergeEdgesMatrix(graph.tombstoneEdgesMatrix, other.GetTombstoneEdgesMatrix())
+	return latest
 }
 
-func mergeVertices(source, mergeWith map[VertexValue]LWWVertex) map[VertexValue]LWWVertex {
+// mergeVertices merge the copies of the records into source when they are after the
+// records of source, and return the values of the merged records, the records are
+// copied as the existing records are updated in place by the following writes
+func mergeVertices[ID comparable](source VertexStoreOf[ID], mergeWith map[ID]LWWVertexOf[ID]) []ID {
 
-	if mergeWith == nil {
-		return source
-	}
+	merged := []ID{}
 
 	for k := range mergeWith {
 		if mergeWith[k] == nil {
 			continue
 		}
-		if _, ok := source[k]; !ok || source[k] == nil {
-			source[k] = mergeWith[k]
-			continue
-		}
-		if source[k].GetTimestamp() < mergeWith[k].GetTimestamp() {
-			source[k] = mergeWith[k]
+		if v, ok := source.Get(k); !ok || CompareComponents(v, mergeWith[k]) < 0 {
+			source.Set(copyVertex(mergeWith[k]))
+			merged = append(merged, k)
 		}
 	}
 
-	return source
+	return merged
 }
 
-func mergeEdgesMatrix(source, mergeWith map[VertexValue]map[VertexValue]LWWEdge) map[VertexValue]map[VertexValue]LWWEdge {
+// mergeEdgesMatrix merge the copies of the records into source when they are after
+// the records of source, and return the cells of the merged records
+func mergeEdgesMatrix[ID comparable](source EdgeStoreOf[ID], mergeWith map[ID]map[ID]LWWEdgeOf[ID]) [][2]ID {
 
-	if mergeWith == nil {
-		return source
-	}
+	merged := [][2]ID{}
 
 	for m := range mergeWith {
 		if mergeWith[m] == nil {
@@ -358,94 +579,68 @@ func mergeEdgesMatrix(source, mergeWith map[VertexValue]map[VertexValue]LWWEdge)
 			if mergeWith[m][n] == nil {
 				continue
 			}
-			if _, ok := source[m]; !ok || source[m] == nil {
-				source[m] = mergeWith[m]
-				continue
-			}
-			if _, ok := source[m][n]; !ok || source[m][n] == nil {
-				source[m][n] = mergeWith[m][n]
-				continue
-			}
-			if source[m][n].GetTimestamp() < mergeWith[m][n].GetTimestamp() {
-				source[m][n] = mergeWith[m][n]
+			if e, ok := source.Get(m, n); !ok || CompareComponents(e, mergeWith[m][n]) < 0 {
+				source.Set(m, n, copyEdge(mergeWith[m][n]))
+				merged = append(merged, [2]ID{m, n})
 			}
 		}
 	}
 
-	return source
+	return merged
 }
 
-func (graph *LWWGraphImpl) GetAdjacencyVerticesList() map[VertexValue][]VertexValue {
-
-	dict := make(map[VertexValue][]VertexValue)
-
-	for k := range graph.vertices {
-		if !graph.IsVertexExist(k) {
-			continue
-		}
-		dict[k] = []VertexValue{}
+// setEdge set the edge of the matrix cell and create the row when it is not exist,
+// as the rows of the matrix are not always there for the vertices received by merge
+func setEdge[ID comparable](matrix map[ID]map[ID]LWWEdgeOf[ID], m, n ID, edge LWWEdgeOf[ID]) {
+	if _, ok := matrix[m]; !ok || matrix[m] == nil {
+		matrix[m] = make(map[ID]LWWEdgeOf[ID])
 	}
+	matrix[m][n] = edge
+}
+
+func (graph *LWWGraphImplOf[ID]) GetAdjacencyVerticesList() map[ID][]ID {
 
-	if len(dict) == 0 {
+	if len(graph.index) == 0 {
 		return nil
 	}
 
-	for m, v := range graph.edgesMatrix {
-		tombstoneVertexM, ok := graph.tombstoneVertices[m]
-		if ok && tombstoneVertexM != nil {
-			if !graph.IsComponentExist(graph.vertices[m].GetTimestamp(), tombstoneVertexM.GetTimestamp()) {
-				continue
-			}
-		}
-		for n, edge := range v {
-			if edge == nil {
-				continue
-			}
-			tombstoneVertexN, ok := graph.tombstoneVertices[n]
-			if ok && tombstoneVertexN != nil {
-				if !graph.IsComponentExist(graph.vertices[n].GetTimestamp(), tombstoneVertexN.GetTimestamp()) {
-					continue
-				}
-			}
-			tombstoneEdge, ok := graph.tombstoneEdgesMatrix[m][n]
-			if ok && tombstoneEdge != nil {
-				if !graph.IsComponentExist(edge.GetTimestamp(), tombstoneEdge.GetTimestamp()) {
-					continue
-				}
-			}
-			if _, ok := dict[m]; ok {
-				dict[m] = append(dict[m], n)
-			}
-		}
+	dict := make(map[ID][]ID, len(graph.index))
 
-		sort.Slice(dict[m], func(i, j int) bool {
-			return string(dict[m][i]) < string(dict[m][j])
-		})
+	for k := range graph.index {
+		dict[k] = graph.neighbours(k)
 	}
 
 	return dict
 }
 
-func (graph *LWWGraphImpl) GetBias() Bias {
+func (graph *LWWGraphImplOf[ID]) GetBias() Bias {
 	return graph.bias
 }
 
-func (graph *LWWGraphImpl) GetClock() Clock {
+func (graph *LWWGraphImplOf[ID]) GetEdgeBias() Bias {
+	return graph.edgeBias
+}
+
+func (graph *LWWGraphImplOf[ID]) GetClock() Clock {
 	return graph.clock
 }
 
-func (graph *LWWGraphImpl) GetVertices() map[VertexValue]LWWVertex {
-	return graph.vertices
+func (graph *LWWGraphImplOf[ID]) GetReplica() ReplicaID {
+	return graph.replica
+}
+
+func (graph *LWWGraphImplOf[ID]) GetVertices() map[ID]LWWVertexOf[ID] {
+	return vertexMap(graph.vertices)
 }
 
-func (graph *LWWGraphImpl) GetTombstoneVertices() map[VertexValue]LWWVertex {
-	return graph.tombstoneVertices
+func (graph *LWWGraphImplOf[ID]) GetTombstoneVertices() map[ID]LWWVertexOf[ID] {
+	return vertexMap(graph.tombstoneVertices)
 }
 
-func (graph *LWWGraphImpl) GetEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
-	return graph.edgesMatrix
+func (graph *LWWGraphImplOf[ID]) GetEdgesMatrix() map[ID]map[ID]LWWEdgeOf[ID] {
+	return edgeMatrix(graph.edgesMatrix)
 }
 
-func (graph *LWWGraphImpl) GetTombstoneEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
-	return graph.tombstoneEdgesMatrix
+func (graph *LWWGraphImplOf[ID]) GetTombstoneEdgesMatrix() map[ID]map[ID]LWWEdgeOf[ID] {
+	return edgeMatrix(graph.tombstoneEdgesMatrix)
 }
@@ -0,0 +1,104 @@
+package undirect
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+var benchmarkSizes = []int{10000, 100000}
+
+func newBenchmarkVertices(n int) []VertexValue {
+	values := make([]VertexValue, n)
+	for i := range values {
+		values[i] = NewVertexValue(strconv.Itoa(i))
+	}
+	return values
+}
+
+// newBenchmarkGraph return a ring of the vertices
+func newBenchmarkGraph(values []VertexValue, replica ReplicaID) LWWGraph {
+
+	graph := NewLWWGraph(Adds, NewHLC(nil), replica)
+
+	for i := range values {
+		v1 := NewLWWVertex(values[i], graph.GetClock(), replica)
+		v2 := NewLWWVertex(values[(i+1)%len(values)], graph.GetClock(), replica)
+		graph.AddEdge(v1, v2)
+	}
+
+	return graph
+}
+
+func BenchmarkLWWGraphImpl_AddVertex(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		values := newBenchmarkVertices(n)
+		b.Run(fmt.Sprintf("%d vertices", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				graph := NewLWWGraph(Adds, NewHLC(nil), "")
+				for _, v := range values {
+					graph.AddVertex(v)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLWWGraphImpl_AddEdge(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		values := newBenchmarkVertices(n)
+		b.Run(fmt.Sprintf("%d vertices", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				newBenchmarkGraph(values, "")
+			}
+		})
+	}
+}
+
+func BenchmarkLWWGraphImpl_Merge(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		values := newBenchmarkVertices(n)
+		other := newBenchmarkGraph(values, "y")
+		b.Run(fmt.Sprintf("%d vertices", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				graph := NewLWWGraph(Adds, NewHLC(nil), "x")
+				b.StartTimer()
+				graph.Merge(other)
+			}
+		})
+	}
+}
+
+// BenchmarkLWWGraphImpl_GetAdjacencyVerticesList add an edge then query the adjacency vertices
+// list, by the incremental index and by rebuilding the list from the whole matrix, which is
+// the way the list was generated before the index
+func BenchmarkLWWGraphImpl_GetAdjacencyVerticesList(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		values := newBenchmarkVertices(n)
+		for _, rebuild := range []bool{false, true} {
+			name := fmt.Sprintf("%d vertices index", n)
+			if rebuild {
+				name = fmt.Sprintf("%d vertices rebuild", n)
+			}
+			b.Run(name, func(b *testing.B) {
+				graph := newBenchmarkGraph(values, "").(*LWWGraphImpl)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					v1 := NewLWWVertex(values[i%n], graph.GetClock(), "")
+					v2 := NewLWWVertex(values[(i+n/2)%n], graph.GetClock(), "")
+					graph.AddEdge(v1, v2)
+					if rebuild {
+						graph.rebuildAdjacencyVerticesList()
+						continue
+					}
+					graph.GetAdjacencyVerticesList()
+				}
+			})
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package undirect
 
 import (
+	"bytes"
+	"encoding/json"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -23,6 +25,10 @@ type mockFields struct {
 
 // Check is the graph associative for merge
 func TestLWWGraphImpl_Merge_Check_Associative(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_Merge_Check_Associative)
+}
+
+func testLWWGraphImpl_Merge_Check_Associative(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -72,13 +78,13 @@ func TestLWWGraphImpl_Merge_Check_Associative(t *testing.T) {
 		clock: &testCkock{},
 	}
 
-	graphXLeft := NewMockGraph(graphXMock)
-	graphYLeft := NewMockGraph(graphYMock)
-	graphZLeft := NewMockGraph(graphZMock)
+	graphXLeft := NewMockGraph(graphXMock, WithStorage(newStorage(t)))
+	graphYLeft := NewMockGraph(graphYMock, WithStorage(newStorage(t)))
+	graphZLeft := NewMockGraph(graphZMock, WithStorage(newStorage(t)))
 
-	graphXRight := NewMockGraph(graphXMock)
-	graphYRight := NewMockGraph(graphYMock)
-	graphZRight := NewMockGraph(graphZMock)
+	graphXRight := NewMockGraph(graphXMock, WithStorage(newStorage(t)))
+	graphYRight := NewMockGraph(graphYMock, WithStorage(newStorage(t)))
+	graphZRight := NewMockGraph(graphZMock, WithStorage(newStorage(t)))
 
 	// 1. X' = X U Y
 	graphXLeft.Merge(graphYLeft)
@@ -101,6 +107,10 @@ func TestLWWGraphImpl_Merge_Check_Associative(t *testing.T) {
 
 // Check is the graph commutative for merge
 func TestLWWGraphImpl_Merge_Check_Commutative(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_Merge_Check_Commutative)
+}
+
+func testLWWGraphImpl_Merge_Check_Commutative(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -136,11 +146,11 @@ func TestLWWGraphImpl_Merge_Check_Commutative(t *testing.T) {
 		clock: &testCkock{},
 	}
 
-	graphXLeft := NewMockGraph(graphXMock)
-	graphYLeft := NewMockGraph(graphYMock)
+	graphXLeft := NewMockGraph(graphXMock, WithStorage(newStorage(t)))
+	graphYLeft := NewMockGraph(graphYMock, WithStorage(newStorage(t)))
 
-	graphXRight := NewMockGraph(graphXMock)
-	graphYRight := NewMockGraph(graphYMock)
+	graphXRight := NewMockGraph(graphXMock, WithStorage(newStorage(t)))
+	graphYRight := NewMockGraph(graphYMock, WithStorage(newStorage(t)))
 
 	// X' = X U Y
 	graphXLeft.Merge(graphYLeft)
@@ -158,6 +168,10 @@ func TestLWWGraphImpl_Merge_Check_Commutative(t *testing.T) {
 
 // Check is the graph Idempotent for merge
 func TestLWWGraphImpl_Merge_Check_Idempotent(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_Merge_Check_Idempotent)
+}
+
+func testLWWGraphImpl_Merge_Check_Idempotent(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -177,10 +191,10 @@ func TestLWWGraphImpl_Merge_Check_Idempotent(t *testing.T) {
 		clock: &testCkock{},
 	}
 
-	graphX := NewMockGraph(graphXMock)
+	graphX := NewMockGraph(graphXMock, WithStorage(newStorage(t)))
 
-	graphX1 := NewMockGraph(graphXMock)
-	graphX2 := NewMockGraph(graphXMock)
+	graphX1 := NewMockGraph(graphXMock, WithStorage(newStorage(t)))
+	graphX2 := NewMockGraph(graphXMock, WithStorage(newStorage(t)))
 
 	// X U X
 	graphX1.Merge(graphX2)
@@ -194,14 +208,14 @@ func TestLWWGraphImpl_Merge_Check_Idempotent(t *testing.T) {
 	}
 }
 
-func NewMockGraph(fields mockFields) LWWGraph {
+func NewMockGraph(fields mockFields, options ...Option) LWWGraph {
 
-	graph := NewLWWGraph(fields.bias, fields.clock)
+	graph := NewLWWGraph(fields.bias, fields.clock, "", options...)
 
 	for i := 0; i < len(fields.verticesPaths); i++ {
 		vertices := []LWWVertex{}
 		for j := 0; j < len(fields.verticesPaths[i]); j++ {
-			vertices = append(vertices, NewLWWVertex(fields.verticesPaths[i][j], graph.GetClock()))
+			vertices = append(vertices, NewLWWVertex(fields.verticesPaths[i][j], graph.GetClock(), ""))
 		}
 		if len(vertices) > 1 {
 			for j := 0; j < len(vertices); j++ {
@@ -221,6 +235,10 @@ func NewMockGraph(fields mockFields) LWWGraph {
 }
 
 func TestNewLWWGraph(t *testing.T) {
+	runWithStorages(t, testNewLWWGraph)
+}
+
+func testNewLWWGraph(t *testing.T, newStorage func(t *testing.T) Storage) {
 	type args struct {
 		bias Bias
 	}
@@ -233,22 +251,22 @@ func TestNewLWWGraph(t *testing.T) {
 		{
 			name: "test unknown case",
 			args: args{Bias(10)},
-			want: NewLWWGraph(Adds, nil),
+			want: NewLWWGraph(Adds, nil, "", WithStorage(newStorage(t))),
 		},
 		{
 			name: "test adds case",
 			args: args{Adds},
-			want: NewLWWGraph(Adds, nil),
+			want: NewLWWGraph(Adds, nil, "", WithStorage(newStorage(t))),
 		},
 		{
 			name: "test removal case",
 			args: args{Removal},
-			want: NewLWWGraph(Removal, nil),
+			want: NewLWWGraph(Removal, nil, "", WithStorage(newStorage(t))),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewLWWGraph(tt.args.bias, nil); !reflect.DeepEqual(got, tt.want) {
+			if got := NewLWWGraph(tt.args.bias, nil, "", WithStorage(newStorage(t))); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewLWWGraph() = %v, want %v", got, tt.want)
 			}
 		})
@@ -256,10 +274,14 @@ func TestNewLWWGraph(t *testing.T) {
 }
 
 func TestLWWGraphImpl_AddVertex(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_AddVertex)
+}
+
+func testLWWGraphImpl_AddVertex(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	t.Run("test add one element", func(t *testing.T) {
 
-		graph := NewLWWGraph(Adds, nil)
+		graph := NewLWWGraph(Adds, nil, "", WithStorage(newStorage(t)))
 
 		vertexValueA := NewVertexValue("A")
 
@@ -280,30 +302,21 @@ func TestLWWGraphImpl_AddVertex(t *testing.T) {
 			t.Errorf("LWWGraphImpl.AddVertex() fail, TombstoneVertices contain = %v", got)
 		}
 
+		// the matrices only keep the cells of the edges
 		edgeMatrix := graph.GetEdgesMatrix()
-		if len(edgeMatrix) == 0 {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, edgeMatrix cannot initialize")
-		}
-		if v, ok := edgeMatrix[vertexValueA]; !ok && v == nil {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, edgeMatrix m dimension does not contain = %v", vertexValueA)
-		} else if v, ok := edgeMatrix[vertexValueA][vertexValueA]; !ok && v == nil {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, edgeMatrix n dimension does not contain = %v", vertexValueA)
+		if len(edgeMatrix) != 0 {
+			t.Errorf("LWWGraphImpl.AddVertex() fail, edgeMatrix contain cells without edge = %v", edgeMatrix)
 		}
 
 		tombstoneEdgeMatrix := graph.GetTombstoneEdgesMatrix()
-		if len(tombstoneEdgeMatrix) == 0 {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, tombstoneEdgeMatrix cannot initialize")
-		}
-		if v, ok := tombstoneEdgeMatrix[vertexValueA]; !ok && v == nil {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, tombstoneEdgeMatrix m dimension does not contain = %v", vertexValueA)
-		} else if v, ok := tombstoneEdgeMatrix[vertexValueA][vertexValueA]; !ok && v == nil {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, tombstoneEdgeMatrix n dimension does not contain = %v", vertexValueA)
+		if len(tombstoneEdgeMatrix) != 0 {
+			t.Errorf("LWWGraphImpl.AddVertex() fail, tombstoneEdgeMatrix contain cells without edge = %v", tombstoneEdgeMatrix)
 		}
 	})
 
 	t.Run("test add elements for matrix", func(t *testing.T) {
 
-		graph := NewLWWGraph(Adds, nil)
+		graph := NewLWWGraph(Adds, nil, "", WithStorage(newStorage(t)))
 		vertexValuesDict := map[string]VertexValue{}
 		verticesDict := map[string]LWWVertex{}
 
@@ -346,13 +359,13 @@ func TestLWWGraphImpl_AddVertex(t *testing.T) {
 		}
 
 		edgeMatrix := graph.GetEdgesMatrix()
-		if len(edgeMatrix) == 0 {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, edgeMatrix cannot initialize")
+		if len(edgeMatrix) != 0 {
+			t.Errorf("LWWGraphImpl.AddVertex() fail, edgeMatrix contain cells without edge = %v", edgeMatrix)
 		}
 
-		tombstoneEdgeMatrix := graph.GetEdgesMatrix()
-		if len(tombstoneEdgeMatrix) == 0 {
-			t.Errorf("LWWGraphImpl.AddVertex() fail, tombstoneEdgeMatrix cannot initialize")
+		tombstoneEdgeMatrix := graph.GetTombstoneEdgesMatrix()
+		if len(tombstoneEdgeMatrix) != 0 {
+			t.Errorf("LWWGraphImpl.AddVertex() fail, tombstoneEdgeMatrix contain cells without edge = %v", tombstoneEdgeMatrix)
 		}
 	})
 
@@ -391,6 +404,10 @@ func TestVertexValue_IsEqual(t *testing.T) {
 }
 
 func TestLWWGraphImpl_IsVertexExist(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_IsVertexExist)
+}
+
+func testLWWGraphImpl_IsVertexExist(t *testing.T, newStorage func(t *testing.T) Storage) {
 	type fields struct {
 		vertex string
 		bias   Bias
@@ -434,7 +451,7 @@ func TestLWWGraphImpl_IsVertexExist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			var (
-				graph  = NewLWWGraph(tt.fields.bias, tt.fields.clock)
+				graph  = NewLWWGraph(tt.fields.bias, tt.fields.clock, "", WithStorage(newStorage(t)))
 				vertex = NewVertexValue(tt.fields.vertex)
 			)
 
@@ -448,6 +465,10 @@ func TestLWWGraphImpl_IsVertexExist(t *testing.T) {
 }
 
 func TestLWWGraphImpl_IsVertexExist_When_Vertex_Removed(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_IsVertexExist_When_Vertex_Removed)
+}
+
+func testLWWGraphImpl_IsVertexExist_When_Vertex_Removed(t *testing.T, newStorage func(t *testing.T) Storage) {
 	type fields struct {
 		vertex string
 		bias   Bias
@@ -503,7 +524,7 @@ func TestLWWGraphImpl_IsVertexExist_When_Vertex_Removed(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			var (
-				graph  = NewLWWGraph(tt.fields.bias, tt.fields.clock)
+				graph  = NewLWWGraph(tt.fields.bias, tt.fields.clock, "", WithStorage(newStorage(t)))
 				vertex = NewVertexValue(tt.fields.vertex)
 			)
 
@@ -519,6 +540,10 @@ func TestLWWGraphImpl_IsVertexExist_When_Vertex_Removed(t *testing.T) {
 }
 
 func TestLWWGraphImpl_GetConnectedVertices(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_GetConnectedVertices)
+}
+
+func testLWWGraphImpl_GetConnectedVertices(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -594,7 +619,7 @@ func TestLWWGraphImpl_GetConnectedVertices(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			graph := NewMockGraph(tt.fields)
+			graph := NewMockGraph(tt.fields, WithStorage(newStorage(t)))
 
 			cv := graph.GetConnectedVertices(tt.args.value)
 
@@ -611,6 +636,10 @@ func TestLWWGraphImpl_GetConnectedVertices(t *testing.T) {
 }
 
 func TestLWWGraphImpl_RemoveVertex(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_RemoveVertex)
+}
+
+func testLWWGraphImpl_RemoveVertex(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -691,7 +720,7 @@ func TestLWWGraphImpl_RemoveVertex(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			graph := NewMockGraph(tt.fields)
+			graph := NewMockGraph(tt.fields, WithStorage(newStorage(t)))
 			for i := 0; i < len(tt.args.values); i++ {
 				graph.RemoveVertex(tt.args.values[i])
 			}
@@ -716,6 +745,10 @@ func TestLWWGraphImpl_RemoveVertex(t *testing.T) {
 }
 
 func TestLWWGraphImpl_RemoveVertex_of_Graph_With_Edge(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_RemoveVertex_of_Graph_With_Edge)
+}
+
+func testLWWGraphImpl_RemoveVertex_of_Graph_With_Edge(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -770,7 +803,7 @@ func TestLWWGraphImpl_RemoveVertex_of_Graph_With_Edge(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			graph := NewMockGraph(tt.fields)
+			graph := NewMockGraph(tt.fields, WithStorage(newStorage(t)))
 			for i := 0; i < len(tt.args.values); i++ {
 				graph.RemoveVertex(tt.args.values[i])
 			}
@@ -795,6 +828,10 @@ func TestLWWGraphImpl_RemoveVertex_of_Graph_With_Edge(t *testing.T) {
 }
 
 func TestLWWGraphImpl_GetEdges(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_GetEdges)
+}
+
+func testLWWGraphImpl_GetEdges(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -875,7 +912,7 @@ func TestLWWGraphImpl_GetEdges(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			graph := NewMockGraph(tt.fields)
+			graph := NewMockGraph(tt.fields, WithStorage(newStorage(t)))
 
 			got := [][]VertexValue{}
 
@@ -893,6 +930,10 @@ func TestLWWGraphImpl_GetEdges(t *testing.T) {
 }
 
 func TestLWWGraphImpl_GetPaths(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_GetPaths)
+}
+
+func testLWWGraphImpl_GetPaths(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -945,14 +986,41 @@ func TestLWWGraphImpl_GetPaths(t *testing.T) {
 				A, E,
 			},
 			want: [][]VertexValue{
-				{A, B, A, C, D, E},
 				{A, B, C, D, E},
 				{A, B, D, E},
-				{A, C, A, B, D, E},
 				{A, C, B, D, E},
 				{A, C, D, E},
 			},
 		},
+		{
+			name: "test get path to vertex in the middle",
+			fields: mockFields{
+				/*
+					   A - B - D - E
+						\ /  /
+						 C -
+				*/
+				verticesPaths: [][]VertexValue{
+					{A, B},
+					{A, C},
+					{B, D},
+					{C, D},
+					{D, E},
+					{B, C},
+				},
+				bias:  Adds,
+				clock: nil,
+			},
+			args: args{
+				A, D,
+			},
+			want: [][]VertexValue{
+				{A, B, C, D},
+				{A, B, D},
+				{A, C, B, D},
+				{A, C, D},
+			},
+		},
 		{
 			name: "test get path with edges (removed edge)",
 			fields: mockFields{
@@ -984,7 +1052,7 @@ func TestLWWGraphImpl_GetPaths(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			graph := NewMockGraph(tt.fields)
+			graph := NewMockGraph(tt.fields, WithStorage(newStorage(t)))
 
 			if got := graph.GetPaths(tt.args.v1, tt.args.v2); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("LWWGraphImpl.GetPaths() = %v, want %v", got, tt.want)
@@ -995,6 +1063,10 @@ func TestLWWGraphImpl_GetPaths(t *testing.T) {
 }
 
 func TestLWWGraphImpl_RemoveEdgeByVertices(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_RemoveEdgeByVertices)
+}
+
+func testLWWGraphImpl_RemoveEdgeByVertices(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -1041,7 +1113,7 @@ func TestLWWGraphImpl_RemoveEdgeByVertices(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			graph := NewMockGraph(tt.fields)
+			graph := NewMockGraph(tt.fields, WithStorage(newStorage(t)))
 			graph.RemoveEdgeByVertices(tt.args.v1, tt.args.v2)
 			if got := graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, tt.want, deep.Equal(got, tt.want))
@@ -1062,11 +1134,11 @@ type mockGraphArgument struct {
 	vertices []mockOperation
 }
 
-func NewMockGraphByOperations(args mockGraphArgument) (LWWGraph, testCkock) {
+func NewMockGraphByOperations(args mockGraphArgument, options ...Option) (LWWGraph, testCkock) {
 
 	clock := testCkock{}
 
-	graph := NewLWWGraph(args.bias, &clock)
+	graph := NewLWWGraph(args.bias, &clock, "", options...)
 
 	var currentTimeline time.Duration = 0
 
@@ -1082,8 +1154,8 @@ func NewMockGraphByOperations(args mockGraphArgument) (LWWGraph, testCkock) {
 		case mockGraphAddAction:
 			if args.vertices[i].connectedVertices != nil {
 				for _, v := range args.vertices[i].connectedVertices {
-					v1 := NewLWWVertex(vertex, graph.GetClock())
-					v2 := NewLWWVertex(v, graph.GetClock())
+					v1 := NewLWWVertex(vertex, graph.GetClock(), "")
+					v2 := NewLWWVertex(v, graph.GetClock(), "")
 					graph.AddEdge(v1, v2)
 				}
 			} else {
@@ -1104,6 +1176,10 @@ func NewMockGraphByOperations(args mockGraphArgument) (LWWGraph, testCkock) {
 }
 
 func TestLWWGraphImpl_GetAdjacencyVerticesList(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_GetAdjacencyVerticesList)
+}
+
+func testLWWGraphImpl_GetAdjacencyVerticesList(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -1241,7 +1317,7 @@ func TestLWWGraphImpl_GetAdjacencyVerticesList(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			graph := NewMockGraph(tt.fields)
+			graph := NewMockGraph(tt.fields, WithStorage(newStorage(t)))
 			if got := graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, tt.want, deep.Equal(got, tt.want))
 			}
@@ -1250,6 +1326,10 @@ func TestLWWGraphImpl_GetAdjacencyVerticesList(t *testing.T) {
 }
 
 func TestLWWGraphImpl_Merge(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_Merge)
+}
+
+func testLWWGraphImpl_Merge(t *testing.T, newStorage func(t *testing.T) Storage) {
 
 	A := NewVertexValue("A")
 	B := NewVertexValue("B")
@@ -1489,12 +1569,33 @@ func TestLWWGraphImpl_Merge(t *testing.T) {
 				B: {},
 			},
 		},
+		{
+			name:        "check with removed edge that is not merged yet",
+			description: "the removal is written before the edge added earlier by the other replica is merged",
+			args: args{
+				bias: Adds,
+				XVertices: []mockOperation{
+					{A, mockGraphAddAction, 0, nil},
+					{B, mockGraphAddAction, 0, nil},
+					{A, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{B}},
+				},
+				YVertices: []mockOperation{
+					{A, mockGraphAddAction, 0, nil},
+					{B, mockGraphAddAction, 0, nil},
+					{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B}},
+				},
+			},
+			want: map[VertexValue][]VertexValue{
+				A: {},
+				B: {},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			xGraph, xClock := NewMockGraphByOperations(mockGraphArgument{tt.args.bias, tt.args.XVertices})
-			yGraph, yClock := NewMockGraphByOperations(mockGraphArgument{tt.args.bias, tt.args.YVertices})
+			xGraph, xClock := NewMockGraphByOperations(mockGraphArgument{tt.args.bias, tt.args.XVertices}, WithStorage(newStorage(t)))
+			yGraph, yClock := NewMockGraphByOperations(mockGraphArgument{tt.args.bias, tt.args.YVertices}, WithStorage(newStorage(t)))
 
 			xClock.SyncWith(&yClock)
 
@@ -1507,3 +1608,123 @@ func TestLWWGraphImpl_Merge(t *testing.T) {
 	}
 
 }
+
+// Check the vertices and the edges written at the same time are merged by the biases of
+// their own for every combination of the biases
+func TestLWWGraphImpl_Merge_Vertex_And_Edge_Bias(t *testing.T) {
+	runWithStorages(t, testLWWGraphImpl_Merge_Vertex_And_Edge_Bias)
+}
+
+func testLWWGraphImpl_Merge_Vertex_And_Edge_Bias(t *testing.T, newStorage func(t *testing.T) Storage) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	tests := []struct {
+		name       string
+		vertexBias Bias
+		edgeBias   Bias
+		want       map[VertexValue][]VertexValue
+	}{
+		{
+			name:       "adds bias of the vertices and the edges",
+			vertexBias: Adds,
+			edgeBias:   Adds,
+			want:       map[VertexValue][]VertexValue{A: {B}, B: {A}, C: {}},
+		},
+		{
+			name:       "adds bias of the vertices and removal bias of the edges",
+			vertexBias: Adds,
+			edgeBias:   Removal,
+			want:       map[VertexValue][]VertexValue{A: {}, B: {}, C: {}},
+		},
+		{
+			name:       "removal bias of the vertices and adds bias of the edges",
+			vertexBias: Removal,
+			edgeBias:   Adds,
+			want:       map[VertexValue][]VertexValue{A: {B}, B: {A}},
+		},
+		{
+			name:       "removal bias of the vertices and the edges",
+			vertexBias: Removal,
+			edgeBias:   Removal,
+			want:       map[VertexValue][]VertexValue{A: {}, B: {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			xClock, yClock := &testCkock{}, &testCkock{}
+			xClock.Now()
+			yClock.Now()
+			// both of the replicas have the same id, so the records written at the
+			// same time are ordered by the biases only
+			x := NewLWWGraph(tt.vertexBias, xClock, "x", WithEdgeBias(tt.edgeBias), WithStorage(newStorage(t)))
+			y := NewLWWGraph(tt.vertexBias, yClock, "x", WithEdgeBias(tt.edgeBias), WithStorage(newStorage(t)))
+
+			xClock.AddDuration(time.Minute)
+			x.AddEdge(NewLWWVertex(A, xClock, "x"), NewLWWVertex(B, xClock, "x"))
+			x.AddVertex(C)
+			y.Merge(x)
+
+			// x removes C and the edge while y adds them again at the same time
+			xClock.AddDuration(time.Minute)
+			yClock.SyncWith(xClock)
+			x.RemoveVertex(C)
+			x.RemoveEdgeByVertices(A, B)
+			y.AddVertex(C)
+			y.AddEdge(NewLWWVertex(A, yClock, "x"), NewLWWVertex(B, yClock, "x"))
+
+			x.Merge(y)
+			y.Merge(x)
+
+			for _, graph := range []LWWGraph{x, y} {
+				if got := graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%v: LWWGraphImpl.Merge() = %v, want %v, diff: %v", graph.GetReplica(), got, tt.want, deep.Equal(got, tt.want))
+				}
+				if got := graph.GetBias(); got != tt.vertexBias {
+					t.Errorf("LWWGraphImpl.GetBias() = %v, want %v", got, tt.vertexBias)
+				}
+				if got := graph.GetEdgeBias(); got != tt.edgeBias {
+					t.Errorf("LWWGraphImpl.GetEdgeBias() = %v, want %v", got, tt.edgeBias)
+				}
+			}
+
+			edge, tombstoneEdge := x.GetEdgesMatrix()[A][B], x.GetTombstoneEdgesMatrix()[A][B]
+			if got, want := x.IsComponentExist(edge, tombstoneEdge), tt.edgeBias == Adds; got != want {
+				t.Errorf("LWWGraphImpl.IsComponentExist() of the edge = %v, want %v", got, want)
+			}
+			vertex, tombstoneVertex := x.GetVertices()[C], x.GetTombstoneVertices()[C]
+			if got, want := x.IsComponentExist(vertex, tombstoneVertex), tt.vertexBias == Adds; got != want {
+				t.Errorf("LWWGraphImpl.IsComponentExist() of the vertex = %v, want %v", got, want)
+			}
+			if got, want := x.IsVertexExist(C), tt.vertexBias == Adds; got != want {
+				t.Errorf("LWWGraphImpl.IsVertexExist() = %v, want %v", got, want)
+			}
+
+			// the biases are kept by the JSON and the snapshot
+			data, err := json.Marshal(x)
+			if err != nil {
+				t.Fatalf("LWWGraphImpl.MarshalJSON() error = %v", err)
+			}
+			unmarshaled := &LWWGraphImpl{}
+			if err := json.Unmarshal(data, unmarshaled); err != nil {
+				t.Fatalf("LWWGraphImpl.UnmarshalJSON() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := WriteSnapshot(&buf, x); err != nil {
+				t.Fatalf("WriteSnapshot() error = %v", err)
+			}
+			read, err := ReadSnapshot(&buf, &testCkock{})
+			if err != nil {
+				t.Fatalf("ReadSnapshot() error = %v", err)
+			}
+			for _, graph := range []LWWGraph{unmarshaled, read} {
+				if got, want := stateOf(graph), stateOf(x); !reflect.DeepEqual(got, want) {
+					t.Errorf("decoded graph = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+				}
+			}
+		})
+	}
+}
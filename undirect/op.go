@@ -0,0 +1,778 @@
+package undirect
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// OpLWWGraph is the op-based replica of the graph, the local mutations are sent as the
+// operations of the records written by them, and the operations of the other replicas are
+// applied by Apply in the causal order. It is not safe for the concurrent use.
+type OpLWWGraph struct {
+	graph *LWWGraphImpl
+	send  func(op Op)
+	// changes keeps the records written by the local mutation, it is nil while the
+	// operations of the others are applied
+	changes  *LWWGraphImpl
+	recorded int
+	// delivered is the number of the operations of every replica applied, including the
+	// operations of the replica itself
+	delivered map[ReplicaID]uint64
+	pending   []Op
+	// maxPending is the maximum number of the operations kept, it is not limited when it is zero
+	maxPending int
+}
+
+// OpKind is the kind of the local mutation of the operation
+type OpKind byte
+
+const (
+	OpAddVertex OpKind = iota + 1
+	OpRemoveVertex
+	OpAddEdge
+	OpRemoveEdge
+	OpSetProperty
+	OpDeleteProperty
+	// OpMerge is the operation of the records of the state merged into the replica
+	OpMerge
+)
+
+// Op is the operation of the local mutation of the replica, it can be encoded by
+// MarshalBinary and decoded by UnmarshalBinary to be sent to the other replicas
+type Op struct {
+	Kind    OpKind
+	Replica ReplicaID
+	// Seq is the sequence number of the operation of the replica, starting from 1
+	Seq uint64
+	// Deps is the number of the operations of the other replicas applied by the replica
+	// before the operation
+	Deps map[ReplicaID]uint64
+	// records is the records written by the mutation, they are merged by the replicas
+	records *LWWGraphImpl
+}
+
+var (
+	// ErrOpCorrupt is returned when the operation can not be decoded
+	ErrOpCorrupt = errors.New("undirect: operation corrupt")
+	// ErrOpPendingFull is returned by Apply when the operation is dropped, as the number of
+	// the operations kept reaches the limit of SetMaxPending
+	ErrOpPendingFull = errors.New("undirect: too many pending operations")
+)
+
+// NewOpLWWGraph return the op-based replica of the graph, send is called with the operation
+// of every local mutation that writes any record, after the mutation is applied
+func NewOpLWWGraph(bias Bias, clockImpl Clock, replica ReplicaID, send func(op Op), options ...Option) *OpLWWGraph {
+
+	graph := &OpLWWGraph{
+		send:      send,
+		delivered: make(map[ReplicaID]uint64),
+	}
+
+	// the stores of the graph, which are the ones of WithStorage when it is given, are
+	// wrapped to record the records written by the local mutations
+	graph.graph = NewLWWGraph(bias, clockImpl, replica, options...).(*LWWGraphImpl)
+	WithStorage(&recordingStorage{
+		vertices: recordingVertexStore{graph.graph.vertices, func(v LWWVertex) {
+			graph.record(func(changes *LWWGraphImpl) { changes.vertices.Set(copyVertex(v)) })
+		}},
+		tombstoneVertices: recordingVertexStore{graph.graph.tombstoneVertices, func(v LWWVertex) {
+			graph.record(func(changes *LWWGraphImpl) { changes.tombstoneVertices.Set(copyVertex(v)) })
+		}},
+		edges: recordingEdgeStore{graph.graph.edgesMatrix, func(m, n VertexValue, e LWWEdge) {
+			graph.record(func(changes *LWWGraphImpl) { changes.edgesMatrix.Set(m, n, copyEdge(e)) })
+		}},
+		tombstoneEdges: recordingEdgeStore{graph.graph.tombstoneEdgesMatrix, func(m, n VertexValue, e LWWEdge) {
+			graph.record(func(changes *LWWGraphImpl) { changes.tombstoneEdgesMatrix.Set(m, n, copyEdge(e)) })
+		}},
+		// the registers of the properties are not updated in place, so they are not copied
+		properties: recordingPropertyStore{graph.graph.properties, func(owner PropertyOwner, key string, p LWWProperty) {
+			graph.record(func(changes *LWWGraphImpl) { changes.properties.Set(owner, key, p) })
+		}},
+		// the version vectors are only written by the merge, as the graph does not write them
+		versions: recordingVersionStore{graph.graph.versions, func(component PropertyOwner, vv VersionVector) {
+			graph.record(func(changes *LWWGraphImpl) { changes.versions.Set(component, vv) })
+		}},
+	})(graph.graph)
+
+	return graph
+}
+
+// record keep the record written by the local mutation
+func (graph *OpLWWGraph) record(set func(changes *LWWGraphImpl)) {
+	if graph.changes == nil {
+		return
+	}
+	set(graph.changes)
+	graph.recorded++
+}
+
+// mutate apply the local mutation and send the records written by it as the operation
+func (graph *OpLWWGraph) mutate(kind OpKind, mutation func()) {
+
+	graph.changes = newOpRecords(graph.graph.replica)
+	graph.recorded = 0
+	mutation()
+
+	changes := graph.changes
+	graph.changes = nil
+	if graph.recorded == 0 {
+		return
+	}
+	changes.buildIndex()
+
+	deps := make(map[ReplicaID]uint64, len(graph.delivered))
+	for replica, n := range graph.delivered {
+		if replica != graph.graph.replica {
+			deps[replica] = n
+		}
+	}
+
+	graph.delivered[graph.graph.replica]++
+
+	graph.send(Op{
+		Kind:    kind,
+		Replica: graph.graph.replica,
+		Seq:     graph.delivered[graph.graph.replica],
+		Deps:    deps,
+		records: changes,
+	})
+}
+
+// newOpRecords return the graph keeping the records of the operation in memory
+func newOpRecords(replica ReplicaID) *LWWGraphImpl {
+	return NewLWWGraphOf[VertexValue](Adds, nil, replica).(*LWWGraphImpl)
+}
+
+// Apply apply the operation of the other replica, the operation is kept until all of the
+// operations it depends on are applied, and the operations applied already are ignored.
+// ErrOpPendingFull is returned when the operation can not be applied yet and the operations
+// kept reach the limit, the operation dropped should be sent again.
+func (graph *OpLWWGraph) Apply(op Op) error {
+
+	if op.records == nil || op.Seq <= graph.delivered[op.Replica] {
+		return nil
+	}
+
+	for _, p := range graph.pending {
+		if p.Replica == op.Replica && p.Seq == op.Seq {
+			return nil
+		}
+	}
+
+	if !graph.isReady(op) && graph.maxPending > 0 && len(graph.pending) >= graph.maxPending {
+		return ErrOpPendingFull
+	}
+
+	graph.pending = append(graph.pending, op)
+
+	// applying one operation might make the others kept before it ready
+	for i := 0; i < len(graph.pending); {
+		op := graph.pending[i]
+		if !graph.isReady(op) {
+			i++
+			continue
+		}
+		graph.pending = append(graph.pending[:i], graph.pending[i+1:]...)
+		graph.graph.Merge(op.records)
+		graph.delivered[op.Replica] = op.Seq
+		i = 0
+	}
+
+	return nil
+}
+
+// isReady check the operation is the next operation of the replica, and all of the
+// operations it depends on are applied
+func (graph *OpLWWGraph) isReady(op Op) bool {
+
+	if op.Seq != graph.delivered[op.Replica]+1 {
+		return false
+	}
+
+	for replica, n := range op.Deps {
+		if graph.delivered[replica] < n {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pending return the number of the operations kept for the operations they depend on
+func (graph *OpLWWGraph) Pending() int {
+	return len(graph.pending)
+}
+
+// SetMaxPending limit the number of the operations kept for the operations they depend on,
+// the number is not limited when n is not greater than zero
+func (graph *OpLWWGraph) SetMaxPending(n int) {
+	graph.maxPending = n
+}
+
+// Missing return the sequence numbers of the operations of every replica that the operations
+// kept depend on but are not received, so they can be asked from the replicas again
+func (graph *OpLWWGraph) Missing() map[ReplicaID][]uint64 {
+
+	// the latest operation of every replica that the operations kept depend on
+	needed := make(map[ReplicaID]uint64)
+	kept := make(map[ReplicaID]map[uint64]bool)
+
+	for _, op := range graph.pending {
+		if op.Seq-1 > needed[op.Replica] {
+			needed[op.Replica] = op.Seq - 1
+		}
+		for replica, n := range op.Deps {
+			if n > needed[replica] {
+				needed[replica] = n
+			}
+		}
+		if kept[op.Replica] == nil {
+			kept[op.Replica] = make(map[uint64]bool)
+		}
+		kept[op.Replica][op.Seq] = true
+	}
+
+	missing := make(map[ReplicaID][]uint64)
+
+	for replica, n := range needed {
+		for seq := graph.delivered[replica] + 1; seq <= n; seq++ {
+			if !kept[replica][seq] {
+				missing[replica] = append(missing[replica], seq)
+			}
+		}
+	}
+
+	return missing
+}
+
+// Delivered return the number of the operations of every replica applied, including the
+// operations sent by the replica itself
+func (graph *OpLWWGraph) Delivered() map[ReplicaID]uint64 {
+
+	delivered := make(map[ReplicaID]uint64, len(graph.delivered))
+	for replica, n := range graph.delivered {
+		delivered[replica] = n
+	}
+
+	return delivered
+}
+
+func (graph *OpLWWGraph) AddVertex(value VertexValue) (vertex LWWVertex) {
+	graph.mutate(OpAddVertex, func() {
+		vertex = graph.graph.AddVertex(value)
+	})
+	return vertex
+}
+
+func (graph *OpLWWGraph) RemoveVertex(value VertexValue) {
+	graph.mutate(OpRemoveVertex, func() {
+		graph.graph.RemoveVertex(value)
+	})
+}
+
+func (graph *OpLWWGraph) AddEdge(v1, v2 LWWVertex) (edge LWWEdge) {
+	graph.mutate(OpAddEdge, func() {
+		edge = graph.graph.AddEdge(v1, v2)
+	})
+	return edge
+}
+
+func (graph *OpLWWGraph) AddWeightedEdge(v1, v2 LWWVertex, weight float64) (edge LWWEdge) {
+	graph.mutate(OpAddEdge, func() {
+		edge = graph.graph.AddWeightedEdge(v1, v2, weight)
+	})
+	return edge
+}
+
+func (graph *OpLWWGraph) RemoveEdgeByVertices(v1, v2 VertexValue) {
+	graph.mutate(OpRemoveEdge, func() {
+		graph.graph.RemoveEdgeByVertices(v1, v2)
+	})
+}
+
+func (graph *OpLWWGraph) SetVertexProperty(value VertexValue, key, property string) (p LWWProperty) {
+	graph.mutate(OpSetProperty, func() {
+		p = graph.graph.SetVertexProperty(value, key, property)
+	})
+	return p
+}
+
+func (graph *OpLWWGraph) DeleteVertexProperty(value VertexValue, key string) {
+	graph.mutate(OpDeleteProperty, func() {
+		graph.graph.DeleteVertexProperty(value, key)
+	})
+}
+
+func (graph *OpLWWGraph) SetEdgeProperty(v1, v2 VertexValue, key, property string) (p LWWProperty) {
+	graph.mutate(OpSetProperty, func() {
+		p = graph.graph.SetEdgeProperty(v1, v2, key, property)
+	})
+	return p
+}
+
+func (graph *OpLWWGraph) DeleteEdgeProperty(v1, v2 VertexValue, key string) {
+	graph.mutate(OpDeleteProperty, func() {
+		graph.graph.DeleteEdgeProperty(v1, v2, key)
+	})
+}
+
+// Merge merge the state of the other graph, the records changed by it are sent as the
+// operation of OpMerge
+func (graph *OpLWWGraph) Merge(other LWWGraph) {
+	graph.mutate(OpMerge, func() {
+		graph.graph.Merge(other)
+	})
+}
+
+// MergeWithReport merge the state of the other graph like Merge, and return the changes
+func (graph *OpLWWGraph) MergeWithReport(other LWWGraph) MergeReport {
+	var report MergeReport
+	graph.mutate(OpMerge, func() {
+		report = graph.graph.MergeWithReport(other)
+	})
+	return report
+}
+
+// GarbageCollect purge the records of the replica only, as the records purged are not
+// written, nothing is sent
+func (graph *OpLWWGraph) GarbageCollect(stable int64) {
+	graph.graph.GarbageCollect(stable)
+}
+
+// MarshalBinary encode the operation, the records are encoded in the same way as the
+// records of the write-ahead log
+func (op Op) MarshalBinary() ([]byte, error) {
+
+	if op.records == nil {
+		return nil, ErrOpCorrupt
+	}
+
+	buf := []byte{byte(op.Kind)}
+	buf = appendString(buf, string(op.Replica))
+	buf = appendUvarint(buf, op.Seq)
+
+	replicas := make([]ReplicaID, 0, len(op.Deps))
+	for replica := range op.Deps {
+		replicas = append(replicas, replica)
+	}
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i] < replicas[j]
+	})
+	buf = appendUvarint(buf, uint64(len(replicas)))
+	for _, replica := range replicas {
+		buf = appendString(buf, string(replica))
+		buf = appendUvarint(buf, op.Deps[replica])
+	}
+
+	for _, store := range []VertexStore{op.records.vertices, op.records.tombstoneVertices} {
+		buf = appendUvarint(buf, uint64(store.Len()))
+		store.Range(func(v LWWVertex) bool {
+			buf = appendLogVertex(buf, v)
+			return true
+		})
+	}
+
+	for _, store := range []EdgeStore{op.records.edgesMatrix, op.records.tombstoneEdgesMatrix} {
+		var cells uint64
+		store.Range(func(_, _ VertexValue, _ LWWEdge) bool {
+			cells++
+			return true
+		})
+		buf = appendUvarint(buf, cells)
+		store.Range(func(m, n VertexValue, e LWWEdge) bool {
+			buf = appendString(buf, string(m))
+			buf = appendString(buf, string(n))
+			buf = appendLogEdge(buf, e)
+			return true
+		})
+	}
+
+	var properties uint64
+	op.records.properties.Range(func(_ PropertyOwner, _ string, _ LWWProperty) bool {
+		properties++
+		return true
+	})
+	buf = appendUvarint(buf, properties)
+	op.records.properties.Range(func(owner PropertyOwner, key string, p LWWProperty) bool {
+		buf = appendPropertyOwner(buf, owner)
+		buf = appendString(buf, key)
+		buf = appendLogProperty(buf, p)
+		return true
+	})
+
+	var versions uint64
+	op.records.versions.Range(func(_ PropertyOwner, _ VersionVector) bool {
+		versions++
+		return true
+	})
+	buf = appendUvarint(buf, versions)
+	op.records.versions.Range(func(component PropertyOwner, vv VersionVector) bool {
+		buf = appendPropertyOwner(buf, component)
+		buf = appendVersionVector(buf, vv)
+		return true
+	})
+
+	return buf, nil
+}
+
+// UnmarshalBinary decode the operation encoded by MarshalBinary, ErrOpCorrupt is returned
+// when the data is not a valid operation
+func (op *Op) UnmarshalBinary(data []byte) error {
+
+	decoded, err := decodeOp(&payloadDecoder{buf: data})
+	if err != nil {
+		return ErrOpCorrupt
+	}
+
+	*op = decoded
+
+	return nil
+}
+
+func decodeOp(d *payloadDecoder) (Op, error) {
+
+	kind, err := d.byte()
+	if err != nil {
+		return Op{}, err
+	}
+	if OpKind(kind) < OpAddVertex || OpKind(kind) > OpMerge {
+		return Op{}, errFrameCorrupt
+	}
+
+	replica, err := d.string()
+	if err != nil {
+		return Op{}, err
+	}
+
+	seq, err := d.uvarint()
+	if err != nil {
+		return Op{}, err
+	}
+	if seq == 0 {
+		return Op{}, errFrameCorrupt
+	}
+
+	op := Op{
+		Kind:    OpKind(kind),
+		Replica: ReplicaID(replica),
+		Seq:     seq,
+		Deps:    make(map[ReplicaID]uint64),
+		records: newOpRecords(ReplicaID(replica)),
+	}
+
+	deps, err := d.uvarint()
+	if err != nil {
+		return Op{}, err
+	}
+	for i := uint64(0); i < deps; i++ {
+		replica, err := d.string()
+		if err != nil {
+			return Op{}, err
+		}
+		n, err := d.uvarint()
+		if err != nil {
+			return Op{}, err
+		}
+		op.Deps[ReplicaID(replica)] = n
+	}
+
+	for _, store := range []VertexStore{op.records.vertices, op.records.tombstoneVertices} {
+		count, err := d.uvarint()
+		if err != nil {
+			return Op{}, err
+		}
+		for i := uint64(0); i < count; i++ {
+			v, err := decodeLogVertex(d)
+			if err != nil {
+				return Op{}, err
+			}
+			store.Set(v)
+		}
+	}
+
+	for _, store := range []EdgeStore{op.records.edgesMatrix, op.records.tombstoneEdgesMatrix} {
+		count, err := d.uvarint()
+		if err != nil {
+			return Op{}, err
+		}
+		for i := uint64(0); i < count; i++ {
+			m, err := d.string()
+			if err != nil {
+				return Op{}, err
+			}
+			n, err := d.string()
+			if err != nil {
+				return Op{}, err
+			}
+			e, err := decodeLogEdge(d)
+			if err != nil {
+				return Op{}, err
+			}
+			// the edge is the edge of the vertices of the cell
+			vertices := e.GetVertices()
+			if m == n || EdgeOwner(VertexValue(m), VertexValue(n)) != EdgeOwner(vertices[0].GetValue(), vertices[1].GetValue()) {
+				return Op{}, errFrameCorrupt
+			}
+			store.Set(VertexValue(m), VertexValue(n), e)
+		}
+	}
+
+	count, err := d.uvarint()
+	if err != nil {
+		return Op{}, err
+	}
+	for i := uint64(0); i < count; i++ {
+		owner, err := decodePropertyOwner(d)
+		if err != nil {
+			return Op{}, err
+		}
+		key, err := d.string()
+		if err != nil {
+			return Op{}, err
+		}
+		p, err := decodeLogProperty(d)
+		if err != nil {
+			return Op{}, err
+		}
+		op.records.properties.Set(owner, key, p)
+	}
+
+	if count, err = d.uvarint(); err != nil {
+		return Op{}, err
+	}
+	for i := uint64(0); i < count; i++ {
+		component, err := decodePropertyOwner(d)
+		if err != nil {
+			return Op{}, err
+		}
+		vv, err := decodeVersionVector(d)
+		if err != nil {
+			return Op{}, err
+		}
+		op.records.versions.Set(component, vv)
+	}
+
+	if err := d.done(); err != nil {
+		return Op{}, err
+	}
+
+	op.records.buildIndex()
+
+	return op, nil
+}
+
+// recordingStorage is the storage of the op-based graph, the records set to the stores are
+// passed to the functions of the stores after they are set
+type recordingStorage struct {
+	vertices          recordingVertexStore
+	tombstoneVertices recordingVertexStore
+	edges             recordingEdgeStore
+	tombstoneEdges    recordingEdgeStore
+	properties        recordingPropertyStore
+	versions          recordingVersionStore
+}
+
+func (storage *recordingStorage) Vertices() VertexStore {
+	return storage.vertices
+}
+
+func (storage *recordingStorage) TombstoneVertices() VertexStore {
+	return storage.tombstoneVertices
+}
+
+func (storage *recordingStorage) Edges() EdgeStore {
+	return storage.edges
+}
+
+func (storage *recordingStorage) TombstoneEdges() EdgeStore {
+	return storage.tombstoneEdges
+}
+
+func (storage *recordingStorage) Properties() PropertyStore {
+	return storage.properties
+}
+
+func (storage *recordingStorage) Versions() VersionStore {
+	return storage.versions
+}
+
+type recordingVertexStore struct {
+	VertexStore
+	record func(vertex LWWVertex)
+}
+
+func (store recordingVertexStore) Set(vertex LWWVertex) {
+	store.VertexStore.Set(vertex)
+	store.record(vertex)
+}
+
+type recordingEdgeStore struct {
+	EdgeStore
+	record func(m, n VertexValue, edge LWWEdge)
+}
+
+func (store recordingEdgeStore) Set(m, n VertexValue, edge LWWEdge) {
+	store.EdgeStore.Set(m, n, edge)
+	store.record(m, n, edge)
+}
+
+type recordingPropertyStore struct {
+	PropertyStore
+	record func(owner PropertyOwner, key string, property LWWProperty)
+}
+
+func (store recordingPropertyStore) Set(owner PropertyOwner, key string, property LWWProperty) {
+	store.PropertyStore.Set(owner, key, property)
+	store.record(owner, key, property)
+}
+
+type recordingVersionStore struct {
+	VersionStore
+	record func(component PropertyOwner, version VersionVector)
+}
+
+func (store recordingVersionStore) Set(component PropertyOwner, version VersionVector) {
+	store.VersionStore.Set(component, version)
+	store.record(component, version)
+}
+
+func (graph *OpLWWGraph) IsVertexExist(value VertexValue) bool {
+	return graph.graph.IsVertexExist(value)
+}
+
+func (graph *OpLWWGraph) GetVertex(value VertexValue) LWWVertex {
+	return graph.graph.GetVertex(value)
+}
+
+func (graph *OpLWWGraph) GetEdge(v1, v2 VertexValue) LWWEdge {
+	return graph.graph.GetEdge(v1, v2)
+}
+
+func (graph *OpLWWGraph) GetEdges(value VertexValue) []LWWEdge {
+	return graph.graph.GetEdges(value)
+}
+
+func (graph *OpLWWGraph) GetPaths(start, end VertexValue) [][]VertexValue {
+	return graph.graph.GetPaths(start, end)
+}
+
+func (graph *OpLWWGraph) WalkPaths(ctx context.Context, start, end VertexValue, maxLength, maxResults int, f func(path []VertexValue) bool) error {
+	return graph.graph.WalkPaths(ctx, start, end, maxLength, maxResults, f)
+}
+
+func (graph *OpLWWGraph) ShortestPath(start, end VertexValue) ([]VertexValue, float64) {
+	return graph.graph.ShortestPath(start, end)
+}
+
+func (graph *OpLWWGraph) ShortestPathHops(start, end VertexValue) []VertexValue {
+	return graph.graph.ShortestPathHops(start, end)
+}
+
+func (graph *OpLWWGraph) Distances(from VertexValue) map[VertexValue]int {
+	return graph.graph.Distances(from)
+}
+
+func (graph *OpLWWGraph) ConnectedComponents() [][]VertexValue {
+	return graph.graph.ConnectedComponents()
+}
+
+func (graph *OpLWWGraph) ComponentOf(value VertexValue) []VertexValue {
+	return graph.graph.ComponentOf(value)
+}
+
+func (graph *OpLWWGraph) IsReachable(v1, v2 VertexValue) bool {
+	return graph.graph.IsReachable(v1, v2)
+}
+
+func (graph *OpLWWGraph) HasCycle() bool {
+	return graph.graph.HasCycle()
+}
+
+func (graph *OpLWWGraph) FindCycle() []VertexValue {
+	return graph.graph.FindCycle()
+}
+
+func (graph *OpLWWGraph) SpanningForest(mode SpanningMode) [][2]VertexValue {
+	return graph.graph.SpanningForest(mode)
+}
+
+func (graph *OpLWWGraph) Bridges() [][2]VertexValue {
+	return graph.graph.Bridges()
+}
+
+func (graph *OpLWWGraph) ArticulationPoints() []VertexValue {
+	return graph.graph.ArticulationPoints()
+}
+
+func (graph *OpLWWGraph) GetAdjacencyVerticesList() map[VertexValue][]VertexValue {
+	return graph.graph.GetAdjacencyVerticesList()
+}
+
+func (graph *OpLWWGraph) IsComponentExist(add, remove Component) bool {
+	return graph.graph.IsComponentExist(add, remove)
+}
+
+func (graph *OpLWWGraph) GetConnectedVertices(value VertexValue) []LWWVertex {
+	return graph.graph.GetConnectedVertices(value)
+}
+
+func (graph *OpLWWGraph) Delta(since int64) LWWGraph {
+	return graph.graph.Delta(since)
+}
+
+func (graph *OpLWWGraph) GetBias() Bias {
+	return graph.graph.GetBias()
+}
+
+func (graph *OpLWWGraph) GetEdgeBias() Bias {
+	return graph.graph.GetEdgeBias()
+}
+
+func (graph *OpLWWGraph) GetClock() Clock {
+	return graph.graph.GetClock()
+}
+
+func (graph *OpLWWGraph) GetReplica() ReplicaID {
+	return graph.graph.GetReplica()
+}
+
+func (graph *OpLWWGraph) GetVertices() map[VertexValue]LWWVertex {
+	return graph.graph.GetVertices()
+}
+
+func (graph *OpLWWGraph) GetTombstoneVertices() map[VertexValue]LWWVertex {
+	return graph.graph.GetTombstoneVertices()
+}
+
+func (graph *OpLWWGraph) GetEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
+	return graph.graph.GetEdgesMatrix()
+}
+
+func (graph *OpLWWGraph) GetTombstoneEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
+	return graph.graph.GetTombstoneEdgesMatrix()
+}
+
+func (graph *OpLWWGraph) GetProperties() map[PropertyOwner]map[string]LWWProperty {
+	return graph.graph.GetProperties()
+}
+
+func (graph *OpLWWGraph) GetVersions() map[PropertyOwner]VersionVector {
+	return graph.graph.GetVersions()
+}
+
+func (graph *OpLWWGraph) GetVertexProperty(value VertexValue, key string) (string, bool) {
+	return graph.graph.GetVertexProperty(value, key)
+}
+
+func (graph *OpLWWGraph) GetVertexProperties(value VertexValue) map[string]string {
+	return graph.graph.GetVertexProperties(value)
+}
+
+func (graph *OpLWWGraph) GetEdgeProperty(v1, v2 VertexValue, key string) (string, bool) {
+	return graph.graph.GetEdgeProperty(v1, v2, key)
+}
+
+func (graph *OpLWWGraph) GetEdgeProperties(v1, v2 VertexValue) map[string]string {
+	return graph.graph.GetEdgeProperties(v1, v2)
+}
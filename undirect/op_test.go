@@ -0,0 +1,380 @@
+package undirect
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// opNetwork keeps the operations sent by the op-based replicas for every other replica
+type opNetwork struct {
+	replicas []*OpLWWGraph
+	inbox    map[ReplicaID][][]byte
+}
+
+func newOpNetwork(bias Bias, replicas ...ReplicaID) *opNetwork {
+
+	network := &opNetwork{inbox: make(map[ReplicaID][][]byte)}
+
+	for _, replica := range replicas {
+		replica := replica
+		clock := &testCkock{}
+		clock.Now()
+		network.replicas = append(network.replicas, NewOpLWWGraph(bias, clock, replica, func(op Op) {
+			data, err := op.MarshalBinary()
+			if err != nil {
+				panic(err)
+			}
+			for _, to := range replicas {
+				if to != replica {
+					network.inbox[to] = append(network.inbox[to], data)
+				}
+			}
+		}))
+	}
+
+	return network
+}
+
+// deliver apply the i-th operation sent to the replica, the operation is kept in the inbox
+// when it is sent again
+func (network *opNetwork) deliver(t *testing.T, graph *OpLWWGraph, i int, again bool) {
+
+	data := network.inbox[graph.GetReplica()][i]
+	if !again {
+		network.inbox[graph.GetReplica()] = append(network.inbox[graph.GetReplica()][:i], network.inbox[graph.GetReplica()][i+1:]...)
+	}
+
+	var op Op
+	if err := op.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Op.UnmarshalBinary() error = %v", err)
+	}
+
+	graph.Apply(op)
+}
+
+func TestOpLWWGraph_Apply(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	network := newOpNetwork(Adds, "x", "y", "z")
+	x, y, z := network.replicas[0], network.replicas[1], network.replicas[2]
+
+	x.AddVertex(A)
+	x.AddVertex(B)
+	x.AddEdge(x.GetVertex(A), x.GetVertex(B))
+
+	// the edge and the vertex B are kept until the vertex A is applied
+	network.deliver(t, y, 2, false)
+	network.deliver(t, y, 1, false)
+	if got, want := y.Pending(), 2; got != want {
+		t.Errorf("OpLWWGraph.Pending() = %v, want %v", got, want)
+	}
+	if got := y.GetAdjacencyVerticesList(); got != nil {
+		t.Errorf("OpLWWGraph.GetAdjacencyVerticesList() = %v, want %v", got, nil)
+	}
+
+	first := network.inbox["y"][0]
+	network.deliver(t, y, 0, false)
+	if got, want := y.Pending(), 0; got != want {
+		t.Errorf("OpLWWGraph.Pending() = %v, want %v", got, want)
+	}
+	if got, want := y.GetAdjacencyVerticesList(), x.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.GetAdjacencyVerticesList() = %v, want %v", got, want)
+	}
+
+	// the edge of y depends on the vertex A of x, which z has not applied yet
+	y.AddEdge(y.GetVertex(A), NewLWWVertex(C, y.GetClock(), "y"))
+	network.deliver(t, z, 3, false)
+	if got := z.GetAdjacencyVerticesList(); got != nil {
+		t.Errorf("OpLWWGraph.GetAdjacencyVerticesList() = %v, want %v", got, nil)
+	}
+
+	for len(network.inbox["z"]) > 0 {
+		network.deliver(t, z, len(network.inbox["z"])-1, false)
+	}
+	if got, want := z.GetAdjacencyVerticesList(), y.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.GetAdjacencyVerticesList() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+	if got, want := z.Delivered(), map[ReplicaID]uint64{"x": 3, "y": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.Delivered() = %v, want %v", got, want)
+	}
+
+	// the operations applied already are ignored
+	network.inbox["y"] = append(network.inbox["y"], first)
+	network.deliver(t, y, 0, false)
+	if got, want := y.Delivered(), map[ReplicaID]uint64{"x": 3, "y": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.Delivered() = %v, want %v", got, want)
+	}
+
+	// the mutation that does not write any record is not sent
+	x.AddVertex(A)
+	x.RemoveEdgeByVertices(A, C)
+	if got, want := len(network.inbox["y"]), 0; got != want {
+		t.Errorf("OpLWWGraph operations sent = %v, want %v", got, want)
+	}
+}
+
+// Check the options are applied to the op-based replica, so it keeps the records in the
+// storage given and resolves them in the same way as the state-based replica
+func TestOpLWWGraph_Options(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	ops := []Op{}
+	storage := NewMapStorage()
+	options := []Option{WithEdgeBias(Removal), WithVersionVectors(nil)}
+
+	x := NewOpLWWGraph(Adds, &testCkock{}, "x", func(op Op) { ops = append(ops, op) }, append(options, WithStorage(storage))...)
+	y := NewOpLWWGraph(Adds, &testCkock{}, "y", func(op Op) {}, options...)
+	state := NewLWWGraph(Adds, &testCkock{}, "z", options...)
+
+	// the edge is removed at the same time as it is added
+	x.AddEdge(NewLWWVertex(A, x.GetClock(), "x"), NewLWWVertex(B, x.GetClock(), "x"))
+	x.RemoveEdgeByVertices(A, B)
+
+	for _, op := range ops {
+		if err := y.Apply(op); err != nil {
+			t.Fatalf("OpLWWGraph.Apply() error = %v", err)
+		}
+	}
+	state.Merge(x)
+
+	if _, ok := storage.TombstoneEdges().Get(A, B); !ok {
+		t.Errorf("Storage.TombstoneEdges().Get() of %v and %v not found", A, B)
+	}
+	want := map[VertexValue][]VertexValue{A: {}, B: {}}
+	for _, graph := range []LWWGraph{x, y, state} {
+		if got := graph.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetAdjacencyVerticesList() of %v = %v, want %v", graph.GetReplica(), got, want)
+		}
+	}
+	if got, want := y.GetVersions(), state.GetVersions(); len(got) == 0 || !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.GetVersions() = %v, want %v", got, want)
+	}
+}
+
+// Check the operations not received are reported, and the operations are dropped when too
+// many of them are kept
+func TestOpLWWGraph_Missing(t *testing.T) {
+
+	ops := []Op{}
+	x := NewOpLWWGraph(Adds, &testCkock{}, "x", func(op Op) { ops = append(ops, op) })
+	y := NewOpLWWGraph(Adds, &testCkock{}, "y", func(op Op) {})
+	y.SetMaxPending(1)
+
+	for _, v := range []string{"A", "B", "C"} {
+		x.AddVertex(NewVertexValue(v))
+	}
+
+	if err := y.Apply(ops[2]); err != nil {
+		t.Fatalf("OpLWWGraph.Apply() error = %v", err)
+	}
+	if got, want := y.Missing(), map[ReplicaID][]uint64{"x": {1, 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.Missing() = %v, want %v", got, want)
+	}
+
+	if err := y.Apply(ops[1]); err != ErrOpPendingFull {
+		t.Errorf("OpLWWGraph.Apply() error = %v, want %v", err, ErrOpPendingFull)
+	}
+	// the next operation is applied even when the operations kept reach the limit
+	if err := y.Apply(ops[0]); err != nil {
+		t.Fatalf("OpLWWGraph.Apply() error = %v", err)
+	}
+	if got, want := y.Missing(), map[ReplicaID][]uint64{"x": {2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.Missing() = %v, want %v", got, want)
+	}
+
+	if err := y.Apply(ops[1]); err != nil {
+		t.Fatalf("OpLWWGraph.Apply() error = %v", err)
+	}
+	if got, want := y.Missing(), map[ReplicaID][]uint64{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.Missing() = %v, want %v", got, want)
+	}
+	if got, want := y.Delivered(), map[ReplicaID]uint64{"x": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OpLWWGraph.Delivered() = %v, want %v", got, want)
+	}
+}
+
+func TestOp_UnmarshalBinary(t *testing.T) {
+
+	ops := []Op{}
+	x := NewOpLWWGraph(Removal, &testCkock{}, "x", func(op Op) {
+		ops = append(ops, op)
+	})
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	x.AddVertex(A)
+	x.AddWeightedEdge(x.GetVertex(A), NewLWWVertex(B, x.GetClock(), "x"), 2.5)
+	x.SetEdgeProperty(A, B, "color", "red")
+	x.GetClock().(*testCkock).AddDuration(time.Second)
+	x.DeleteEdgeProperty(B, A, "color")
+	x.RemoveVertex(B)
+
+	for _, op := range ops {
+
+		data, err := op.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Op.MarshalBinary() error = %v", err)
+		}
+
+		var decoded Op
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Op.UnmarshalBinary() error = %v", err)
+		}
+
+		if decoded.Kind != op.Kind || decoded.Replica != op.Replica || decoded.Seq != op.Seq || !reflect.DeepEqual(decoded.Deps, op.Deps) {
+			t.Errorf("Op.UnmarshalBinary() = %v, want %v", decoded, op)
+		}
+		if got, want := stateOf(decoded.records), stateOf(op.records); !reflect.DeepEqual(got, want) {
+			t.Errorf("Op.UnmarshalBinary() records = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+		}
+
+		// the operation cut at any offset is corrupt
+		for i := 0; i < len(data); i++ {
+			if err := decoded.UnmarshalBinary(data[:i]); !errors.Is(err, ErrOpCorrupt) {
+				t.Fatalf("Op.UnmarshalBinary() of %v bytes error = %v, want %v", i, err, ErrOpCorrupt)
+			}
+		}
+	}
+
+	if got, want := len(ops), 5; got != want {
+		t.Errorf("OpLWWGraph operations sent = %v, want %v", got, want)
+	}
+}
+
+// applyRandomMutation apply the same random local mutation to the graphs of the same replica
+// and the same clock, and return the name of the mutation
+func applyRandomMutation(r *rand.Rand, graphs []LWWGraph, values []VertexValue) string {
+
+	clock := graphs[0].GetClock().(*testCkock)
+	clock.AddDuration(time.Duration(r.Intn(3)) * time.Second)
+
+	v1, v2 := values[r.Intn(len(values))], values[r.Intn(len(values))]
+	property := fmt.Sprint(r.Intn(3))
+
+	var (
+		name   string
+		mutate func(graph LWWGraph)
+	)
+
+	switch n := r.Intn(100); {
+	case n < 25:
+		name, mutate = "add vertex", func(graph LWWGraph) { graph.AddVertex(v1) }
+	case n < 40:
+		name, mutate = "remove vertex", func(graph LWWGraph) { graph.RemoveVertex(v1) }
+	case n < 60:
+		name, mutate = "add edge", func(graph LWWGraph) {
+			graph.AddEdge(NewLWWVertex(v1, clock, graph.GetReplica()), NewLWWVertex(v2, clock, graph.GetReplica()))
+		}
+	case n < 70:
+		name, mutate = "add weighted edge", func(graph LWWGraph) {
+			graph.AddWeightedEdge(NewLWWVertex(v1, clock, graph.GetReplica()), NewLWWVertex(v2, clock, graph.GetReplica()), 1)
+		}
+	case n < 85:
+		name, mutate = "remove edge", func(graph LWWGraph) { graph.RemoveEdgeByVertices(v1, v2) }
+	case n < 95:
+		name, mutate = "set property", func(graph LWWGraph) { graph.SetVertexProperty(v1, "p", property) }
+	default:
+		name, mutate = "delete property", func(graph LWWGraph) { graph.DeleteVertexProperty(v1, "p") }
+	}
+
+	for _, graph := range graphs {
+		mutate(graph)
+	}
+
+	return name
+}
+
+// Check the op-based replicas are the same as the state-based replicas merged with the states
+// the operations were sent from, when the operations are applied in any order
+func TestOpLWWGraph_Random_Operations(t *testing.T) {
+
+	values := newRandomValues(5)
+
+	for _, bias := range []Bias{Adds, Removal} {
+		for seed := int64(1); seed <= 4; seed++ {
+			t.Run(fmt.Sprintf("bias %v seed %v", bias, seed), func(t *testing.T) {
+
+				r := rand.New(rand.NewSource(seed))
+				replicas := []ReplicaID{"x", "y", "z"}
+
+				// the state of the state-based replica when the operation is sent
+				sent := map[ReplicaID]map[uint64]LWWGraph{}
+				network := newOpNetwork(bias, replicas...)
+				states := []LWWGraph{}
+
+				for i, replica := range replicas {
+					sent[replica] = map[uint64]LWWGraph{}
+					states = append(states, NewLWWGraph(bias, network.replicas[i].GetClock(), replica))
+				}
+
+				for step := 0; step < 600; step++ {
+
+					i := r.Intn(len(replicas))
+					op, state := network.replicas[i], states[i]
+					delivered := op.Delivered()
+
+					var name string
+					if inbox := network.inbox[op.GetReplica()]; len(inbox) > 0 && r.Intn(2) == 0 {
+						name = "apply"
+						network.deliver(t, op, r.Intn(len(inbox)), r.Intn(5) == 0)
+					} else {
+						name = applyRandomMutation(r, []LWWGraph{op, state}, values)
+					}
+
+					for replica, n := range op.Delivered() {
+						for seq := delivered[replica] + 1; seq <= n; seq++ {
+							if replica == op.GetReplica() {
+								snapshot := NewLWWGraph(bias, &testCkock{}, replica)
+								snapshot.Merge(state)
+								sent[replica][seq] = snapshot
+								continue
+							}
+							state.Merge(sent[replica][seq])
+						}
+					}
+
+					// the records might not be the same, as the tombstones of the edges are deleted by
+					// the edges added, while the state-based replica merges them again from the states
+					if got, want := op.GetAdjacencyVerticesList(), state.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+						t.Fatalf("step %v %v of %v: OpLWWGraph.GetAdjacencyVerticesList() = %v, want %v, diff: %v", step, name, op.GetReplica(), got, want, deep.Equal(got, want))
+					}
+					if got, want := copyProperties(op.GetProperties()), copyProperties(state.GetProperties()); !reflect.DeepEqual(got, want) {
+						t.Fatalf("step %v %v of %v: OpLWWGraph.GetProperties() = %v, want %v, diff: %v", step, name, op.GetReplica(), got, want, deep.Equal(got, want))
+					}
+				}
+
+				// all of the replicas converge when all of the operations are applied
+				for _, op := range network.replicas {
+					for len(network.inbox[op.GetReplica()]) > 0 {
+						network.deliver(t, op, r.Intn(len(network.inbox[op.GetReplica()])), false)
+					}
+					if got := op.Pending(); got != 0 {
+						t.Errorf("OpLWWGraph.Pending() = %v, want %v", got, 0)
+					}
+				}
+
+				want := NewLWWGraph(bias, &testCkock{}, "w")
+				for _, state := range states {
+					want.Merge(state)
+				}
+				for _, op := range network.replicas {
+					if got, want := op.GetAdjacencyVerticesList(), want.GetAdjacencyVerticesList(); !reflect.DeepEqual(got, want) {
+						t.Errorf("%v: OpLWWGraph.GetAdjacencyVerticesList() = %v, want %v, diff: %v", op.GetReplica(), got, want, deep.Equal(got, want))
+					}
+				}
+			})
+		}
+	}
+}
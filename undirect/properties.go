@@ -0,0 +1,246 @@
+package undirect
+
+// The properties of the vertices and the edges are the key/value maps, every key of the map
+// is a register of its own, which is the latest record of the key, so the keys set by the
+// different replicas are merged separately and none of them is lost. Deleting the property
+// writes the record of the key as well, which is the tombstone of the register.
+//
+// The properties are kept apart from the records of the vertices and the edges, so they are
+// only visible when the owner exists, and they come back along with the owner when the owner
+// is added again.
+
+// PropertyOwnerOf is the vertex or the edge of the properties, the vertices of the edge are
+// sorted, so both directions of the edge have the same properties
+type PropertyOwnerOf[ID comparable] struct {
+	Edge     bool
+	Vertices [2]ID
+}
+
+// PropertyOwner is the owner of the properties of the graph of the string values
+type PropertyOwner = PropertyOwnerOf[VertexValue]
+
+// VertexOwner return the owner of the properties of the vertex
+func VertexOwner(value VertexValue) PropertyOwner {
+	return PropertyOwner{Vertices: [2]VertexValue{value}}
+}
+
+// EdgeOwner return the owner of the properties of the edge of the two
+func EdgeOwner(v1, v2 VertexValue) PropertyOwner {
+	return PropertyOwner{Edge: true, Vertices: orderedPair[VertexValue](orderedIDs[VertexValue]{}, v1, v2)}
+}
+
+func vertexOwner[ID comparable](value ID) PropertyOwnerOf[ID] {
+	return PropertyOwnerOf[ID]{Vertices: [2]ID{value}}
+}
+
+func (graph *LWWGraphImplOf[ID]) edgeOwner(v1, v2 ID) PropertyOwnerOf[ID] {
+	return PropertyOwnerOf[ID]{Edge: true, Vertices: graph.pair(v1, v2)}
+}
+
+// lessOwner return true when the owner a is before b, the vertices are before the edges
+func (graph *LWWGraphImplOf[ID]) lessOwner(a, b PropertyOwnerOf[ID]) bool {
+	if a.Edge != b.Edge {
+		return !a.Edge
+	}
+	if a.Vertices[0] != b.Vertices[0] {
+		return graph.less(a.Vertices[0], b.Vertices[0])
+	}
+	return graph.less(a.Vertices[1], b.Vertices[1])
+}
+
+type LWWProperty interface {
+	GetValue() string
+	// IsDeleted return true when the record is the deletion of the property
+	IsDeleted() bool
+	GetTimestamp() int64
+	GetReplica() ReplicaID
+}
+
+type LWWPropertyImpl struct {
+	value     string
+	deleted   bool
+	timestamp int64
+	replica   ReplicaID
+}
+
+// NewLWWProperty return the record of the property, the value is empty for the deletion
+func NewLWWProperty(value string, deleted bool, clock Clock, replica ReplicaID) LWWProperty {
+	return &LWWPropertyImpl{
+		value:     value,
+		deleted:   deleted,
+		timestamp: clock.Now().UnixNano(),
+		replica:   replica,
+	}
+}
+
+func (property *LWWPropertyImpl) GetValue() string {
+	return property.value
+}
+
+func (property *LWWPropertyImpl) IsDeleted() bool {
+	return property.deleted
+}
+
+func (property *LWWPropertyImpl) GetTimestamp() int64 {
+	return property.timestamp
+}
+
+func (property *LWWPropertyImpl) GetReplica() ReplicaID {
+	return property.replica
+}
+
+// compareProperties return the order of the records of the same key like CompareComponents,
+// the records written at the same time by the same replica are ordered by the bias, then
+// by the value, so every replica keeps the same record
+func compareProperties(a, b LWWProperty, bias Bias) int {
+
+	if order := CompareComponents(a, b); order != 0 {
+		return order
+	}
+
+	if a.IsDeleted() != b.IsDeleted() {
+		// the deletion is after the value with the removal bias
+		if a.IsDeleted() == (bias == Removal) {
+			return 1
+		}
+		return -1
+	}
+
+	switch {
+	case a.GetValue() < b.GetValue():
+		return -1
+	case a.GetValue() > b.GetValue():
+		return 1
+	}
+
+	return 0
+}
+
+// propertyBias return the bias of the properties of the owner, which is the bias of the
+// vertices or the edges
+func (graph *LWWGraphImplOf[ID]) propertyBias(owner PropertyOwnerOf[ID]) Bias {
+	if owner.Edge {
+		return graph.edgeBias
+	}
+	return graph.bias
+}
+
+// isOwnerExist check the vertex or the edge of the properties exists
+func (graph *LWWGraphImplOf[ID]) isOwnerExist(owner PropertyOwnerOf[ID]) bool {
+	if owner.Edge {
+		_, ok := graph.index[owner.Vertices[0]][owner.Vertices[1]]
+		return ok
+	}
+	return graph.IsVertexExist(owner.Vertices[0])
+}
+
+// setProperty write the record of the key when the owner exists
+func (graph *LWWGraphImplOf[ID]) setProperty(owner PropertyOwnerOf[ID], key string, property LWWProperty) LWWProperty {
+
+	if !graph.isOwnerExist(owner) {
+		return nil
+	}
+
+	// the existing record might be merged from the replica of which the clock is ahead
+	if existing, ok := graph.properties.Get(owner, key); ok && compareProperties(existing, property, graph.propertyBias(owner)) >= 0 {
+		return nil
+	}
+
+	graph.properties.Set(owner, key, property)
+
+	return property
+}
+
+// deleteProperty write the deletion of the key when the owner has the property
+func (graph *LWWGraphImplOf[ID]) deleteProperty(owner PropertyOwnerOf[ID], key string) {
+
+	if _, ok := graph.getProperty(owner, key); !ok {
+		return
+	}
+
+	graph.setProperty(owner, key, NewLWWProperty("", true, graph.clock, graph.replica))
+}
+
+func (graph *LWWGraphImplOf[ID]) getProperty(owner PropertyOwnerOf[ID], key string) (string, bool) {
+
+	if !graph.isOwnerExist(owner) {
+		return "", false
+	}
+
+	property, ok := graph.properties.Get(owner, key)
+	if !ok || property.IsDeleted() {
+		return "", false
+	}
+
+	return property.GetValue(), true
+}
+
+func (graph *LWWGraphImplOf[ID]) getProperties(owner PropertyOwnerOf[ID]) map[string]string {
+
+	if !graph.isOwnerExist(owner) {
+		return nil
+	}
+
+	properties := make(map[string]string)
+
+	graph.properties.RangeOwner(owner, func(key string, property LWWProperty) bool {
+		if !property.IsDeleted() {
+			properties[key] = property.GetValue()
+		}
+		return true
+	})
+
+	return properties
+}
+
+func (graph *LWWGraphImplOf[ID]) SetVertexProperty(value ID, key, property string) LWWProperty {
+	return graph.setProperty(vertexOwner(value), key, NewLWWProperty(property, false, graph.clock, graph.replica))
+}
+
+func (graph *LWWGraphImplOf[ID]) DeleteVertexProperty(value ID, key string) {
+	graph.deleteProperty(vertexOwner(value), key)
+}
+
+func (graph *LWWGraphImplOf[ID]) GetVertexProperty(value ID, key string) (string, bool) {
+	return graph.getProperty(vertexOwner(value), key)
+}
+
+func (graph *LWWGraphImplOf[ID]) GetVertexProperties(value ID) map[string]string {
+	return graph.getProperties(vertexOwner(value))
+}
+
+func (graph *LWWGraphImplOf[ID]) SetEdgeProperty(v1, v2 ID, key, property string) LWWProperty {
+	return graph.setProperty(graph.edgeOwner(v1, v2), key, NewLWWProperty(property, false, graph.clock, graph.replica))
+}
+
+func (graph *LWWGraphImplOf[ID]) DeleteEdgeProperty(v1, v2 ID, key string) {
+	graph.deleteProperty(graph.edgeOwner(v1, v2), key)
+}
+
+func (graph *LWWGraphImplOf[ID]) GetEdgeProperty(v1, v2 ID, key string) (string, bool) {
+	return graph.getProperty(graph.edgeOwner(v1, v2), key)
+}
+
+func (graph *LWWGraphImplOf[ID]) GetEdgeProperties(v1, v2 ID) map[string]string {
+	return graph.getProperties(graph.edgeOwner(v1, v2))
+}
+
+func (graph *LWWGraphImplOf[ID]) GetProperties() map[PropertyOwnerOf[ID]]map[string]LWWProperty {
+	return propertyMap(graph.properties)
+}
+
+// mergeProperties merge the records of the keys into source when they are after the
+// records of source, the records are not updated in place, so they are not copied, bias
+// return the bias of the properties of the owner
+func mergeProperties[ID comparable](source PropertyStoreOf[ID], mergeWith map[PropertyOwnerOf[ID]]map[string]LWWProperty, bias func(owner PropertyOwnerOf[ID]) Bias) {
+	for owner := range mergeWith {
+		for key, property := range mergeWith[owner] {
+			if property == nil {
+				continue
+			}
+			if p, ok := source.Get(owner, key); !ok || compareProperties(p, property, bias(owner)) < 0 {
+				source.Set(owner, key, property)
+			}
+		}
+	}
+}
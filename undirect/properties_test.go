@@ -0,0 +1,351 @@
+package undirect
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestLWWGraphImpl_Properties(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	type propertyOperation func(graph LWWGraph)
+
+	set := func(v VertexValue, key, value string) propertyOperation {
+		return func(graph LWWGraph) { graph.SetVertexProperty(v, key, value) }
+	}
+	setEdge := func(v1, v2 VertexValue, key, value string) propertyOperation {
+		return func(graph LWWGraph) { graph.SetEdgeProperty(v1, v2, key, value) }
+	}
+
+	tests := []struct {
+		name          string
+		fields        weightedMockFields
+		operations    []propertyOperation
+		wantA         map[string]string
+		wantAB        map[string]string
+		wantNameOfA   string
+		wantNameOfAOk bool
+	}{
+		{
+			name:          "test set properties",
+			fields:        weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}}},
+			operations:    []propertyOperation{set(A, "name", "a"), set(A, "color", "red"), setEdge(B, A, "kind", "link")},
+			wantA:         map[string]string{"name": "a", "color": "red"},
+			wantAB:        map[string]string{"kind": "link"},
+			wantNameOfA:   "a",
+			wantNameOfAOk: true,
+		},
+		{
+			name:          "test set the property again",
+			fields:        weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}}},
+			operations:    []propertyOperation{set(A, "name", "a"), set(A, "name", "b")},
+			wantA:         map[string]string{"name": "b"},
+			wantAB:        map[string]string{},
+			wantNameOfA:   "b",
+			wantNameOfAOk: true,
+		},
+		{
+			name:   "test delete the property",
+			fields: weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}}},
+			operations: []propertyOperation{
+				set(A, "name", "a"),
+				set(A, "color", "red"),
+				setEdge(A, B, "kind", "link"),
+				func(graph LWWGraph) { graph.DeleteVertexProperty(A, "name") },
+				func(graph LWWGraph) { graph.DeleteEdgeProperty(B, A, "kind") },
+			},
+			wantA:  map[string]string{"color": "red"},
+			wantAB: map[string]string{},
+		},
+		{
+			name:       "test the properties of the removed vertex are hidden",
+			fields:     weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}}},
+			operations: []propertyOperation{set(A, "name", "a"), setEdge(A, B, "kind", "link"), func(graph LWWGraph) { graph.RemoveVertex(A) }},
+			wantA:      nil,
+			wantAB:     nil,
+		},
+		{
+			name:   "test the properties come back with the vertex",
+			fields: weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}}},
+			operations: []propertyOperation{
+				set(A, "name", "a"),
+				func(graph LWWGraph) { graph.RemoveVertex(A) },
+				func(graph LWWGraph) { graph.AddVertex(A) },
+			},
+			wantA:         map[string]string{"name": "a"},
+			wantAB:        nil,
+			wantNameOfA:   "a",
+			wantNameOfAOk: true,
+		},
+		{
+			name:       "test set the properties of the owners not exist",
+			fields:     weightedMockFields{bias: Adds, edges: []weightedEdge{{B, C, -1}}},
+			operations: []propertyOperation{set(A, "name", "a"), setEdge(A, B, "kind", "link"), setEdge(B, B, "kind", "loop")},
+			wantA:      nil,
+			wantAB:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph, clock := NewWeightedMockGraph(tt.fields, "")
+
+			for _, op := range tt.operations {
+				clock.AddDuration(time.Second)
+				op(graph)
+			}
+
+			if got := graph.GetVertexProperties(A); !reflect.DeepEqual(got, tt.wantA) {
+				t.Errorf("LWWGraphImpl.GetVertexProperties() = %v, want %v", got, tt.wantA)
+			}
+			if got := graph.GetEdgeProperties(A, B); !reflect.DeepEqual(got, tt.wantAB) {
+				t.Errorf("LWWGraphImpl.GetEdgeProperties() = %v, want %v", got, tt.wantAB)
+			}
+			if got := graph.GetEdgeProperties(B, A); !reflect.DeepEqual(got, tt.wantAB) {
+				t.Errorf("LWWGraphImpl.GetEdgeProperties() of the reverse = %v, want %v", got, tt.wantAB)
+			}
+			if got, ok := graph.GetVertexProperty(A, "name"); got != tt.wantNameOfA || ok != tt.wantNameOfAOk {
+				t.Errorf("LWWGraphImpl.GetVertexProperty() = %v, %v, want %v, %v", got, ok, tt.wantNameOfA, tt.wantNameOfAOk)
+			}
+		})
+	}
+}
+
+// Check the keys are merged separately, so the keys set by the replicas at the same time
+// are all kept, and the latest record of the same key wins
+func TestLWWGraphImpl_Properties_Merge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	x, xClock := NewWeightedMockGraph(weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}}}, "x")
+	y, yClock := NewWeightedMockGraph(weightedMockFields{bias: Adds}, "y")
+	y.Merge(x)
+	yClock.Now()
+	yClock.SyncWith(xClock)
+
+	xClock.AddDuration(time.Second)
+	yClock.AddDuration(time.Second)
+	x.SetVertexProperty(A, "name", "a")
+	x.SetEdgeProperty(A, B, "kind", "link")
+	y.SetVertexProperty(A, "color", "red")
+	y.SetEdgeProperty(A, B, "cost", "3")
+
+	// the same key written later by x wins, the key written at the same time is resolved
+	// by the replica id
+	xClock.AddDuration(time.Second)
+	yClock.AddDuration(time.Second)
+	x.SetVertexProperty(A, "size", "x")
+	y.SetVertexProperty(A, "size", "y")
+	xClock.AddDuration(time.Second)
+	x.SetVertexProperty(A, "color", "blue")
+
+	x.Merge(y)
+	y.Merge(x)
+
+	wantA := map[string]string{"name": "a", "color": "blue", "size": "y"}
+	wantAB := map[string]string{"kind": "link", "cost": "3"}
+
+	for _, graph := range []LWWGraph{x, y} {
+		if got := graph.GetVertexProperties(A); !reflect.DeepEqual(got, wantA) {
+			t.Errorf("LWWGraphImpl.GetVertexProperties() of %v = %v, want %v", graph.GetReplica(), got, wantA)
+		}
+		if got := graph.GetEdgeProperties(A, B); !reflect.DeepEqual(got, wantAB) {
+			t.Errorf("LWWGraphImpl.GetEdgeProperties() of %v = %v, want %v", graph.GetReplica(), got, wantAB)
+		}
+	}
+
+	// the deletion is merged like the value
+	yClock.SyncWith(xClock)
+	yClock.AddDuration(time.Second)
+	y.DeleteVertexProperty(A, "name")
+	x.Merge(y.Delta(xClock.Now().UnixNano()))
+
+	if got, ok := x.GetVertexProperty(A, "name"); ok {
+		t.Errorf("LWWGraphImpl.GetVertexProperty() = %v, want the deleted property", got)
+	}
+}
+
+// Check the value and the deletion written at the same time by the same replica id are
+// resolved by the bias
+func TestLWWGraphImpl_Properties_Merge_Bias(t *testing.T) {
+
+	A := NewVertexValue("A")
+
+	tests := []struct {
+		name   string
+		bias   Bias
+		want   string
+		wantOk bool
+	}{
+		{name: "test adds bias keeps the value", bias: Adds, want: "a", wantOk: true},
+		{name: "test removal bias keeps the deletion", bias: Removal, want: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			x, xClock := NewWeightedMockGraph(weightedMockFields{bias: tt.bias}, "")
+			x.AddVertex(A)
+			xClock.AddDuration(time.Second)
+			x.SetVertexProperty(A, "name", "old")
+
+			yClock := &testCkock{}
+			yClock.Now()
+			yClock.SyncWith(xClock)
+			y := NewLWWGraph(tt.bias, yClock, "")
+			y.Merge(x)
+
+			xClock.AddDuration(time.Second)
+			yClock.AddDuration(time.Second)
+			x.SetVertexProperty(A, "name", "a")
+			y.DeleteVertexProperty(A, "name")
+
+			x.Merge(y)
+			y.Merge(x)
+
+			for _, graph := range []LWWGraph{x, y} {
+				if got, ok := graph.GetVertexProperty(A, "name"); got != tt.want || ok != tt.wantOk {
+					t.Errorf("LWWGraphImpl.GetVertexProperty() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+				}
+			}
+		})
+	}
+}
+
+// Check the deletions are purged, and the properties of the purged owners are kept
+func TestLWWGraphImpl_Properties_GarbageCollect(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	graph, clock := NewWeightedMockGraph(weightedMockFields{bias: Adds, edges: []weightedEdge{{A, B, -1}, {B, C, -1}}}, "")
+
+	clock.AddDuration(time.Second)
+	graph.SetVertexProperty(A, "name", "a")
+	graph.SetVertexProperty(A, "color", "red")
+	graph.SetVertexProperty(C, "name", "c")
+	graph.SetEdgeProperty(A, B, "kind", "link")
+	clock.AddDuration(time.Second)
+	graph.DeleteVertexProperty(A, "color")
+	graph.RemoveVertex(C)
+	clock.AddDuration(time.Second)
+
+	graph.GarbageCollect(clock.Now().UnixNano())
+
+	want := map[PropertyOwner][]string{
+		VertexOwner(A):  {"name"},
+		VertexOwner(C):  {"name"},
+		EdgeOwner(A, B): {"kind"},
+	}
+	got := map[PropertyOwner][]string{}
+	for owner, properties := range graph.GetProperties() {
+		for key := range properties {
+			got[owner] = append(got[owner], key)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GarbageCollect() properties = %v, want %v", got, want)
+	}
+}
+
+// Check the replicas have the same properties of the owner added again after one of them
+// purged the records of the owner
+func TestLWWGraphImpl_Properties_GarbageCollect_Converge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	x, xClock := NewWeightedMockGraph(weightedMockFields{bias: Adds}, "x")
+	y, yClock := NewWeightedMockGraph(weightedMockFields{bias: Adds}, "y")
+	xClock.Now()
+	yClock.Now()
+
+	xClock.AddDuration(time.Second)
+	x.AddVertex(A)
+	x.AddVertex(B)
+	x.SetVertexProperty(A, "name", "a")
+	x.AddEdge(NewLWWVertex(A, xClock, "x"), NewLWWVertex(B, xClock, "x"))
+	x.SetEdgeProperty(A, B, "kind", "link")
+	xClock.AddDuration(time.Second)
+	x.RemoveVertex(A)
+	x.RemoveEdgeByVertices(A, B)
+
+	// both of the replicas acknowledged every record before the garbage collection
+	y.Merge(x)
+	xClock.AddDuration(time.Second)
+	yClock.SyncWith(xClock)
+	stable := xClock.Now().UnixNano()
+	x.GarbageCollect(stable)
+
+	xClock.AddDuration(time.Second)
+	yClock.AddDuration(time.Second)
+	x.AddVertex(A)
+	y.AddEdge(NewLWWVertex(A, yClock, "y"), NewLWWVertex(B, yClock, "y"))
+
+	// the replicas exchange the records after the stable timestamp only
+	x.Merge(y.Delta(stable))
+	y.Merge(x.Delta(stable))
+
+	wantA := map[string]string{"name": "a"}
+	wantAB := map[string]string{"kind": "link"}
+
+	for _, graph := range []LWWGraph{x, y} {
+		if got := graph.GetVertexProperties(A); !reflect.DeepEqual(got, wantA) {
+			t.Errorf("LWWGraphImpl.GetVertexProperties() of %v = %v, want %v", graph.GetReplica(), got, wantA)
+		}
+		if got := graph.GetEdgeProperties(A, B); !reflect.DeepEqual(got, wantAB) {
+			t.Errorf("LWWGraphImpl.GetEdgeProperties() of %v = %v, want %v", graph.GetReplica(), got, wantAB)
+		}
+	}
+}
+
+// Check the replicas have the same properties after they exchange the records following
+// the random operations
+func TestLWWGraphImpl_Properties_Random_Operations(t *testing.T) {
+
+	for _, bias := range []Bias{Adds, Removal} {
+
+		r := rand.New(rand.NewSource(int64(bias) + 19))
+		replicas := newRandomReplicas(bias, "x", "y", "z")
+		values := newRandomValues(4)
+
+		for step := 0; step < 1000; step++ {
+			applyRandomOperation(r, replicas, values)
+		}
+
+		for _, graph := range replicas {
+			for _, other := range replicas {
+				graph.Merge(other.Delta(math.MinInt64))
+			}
+		}
+
+		visible := func(graph LWWGraph) map[string]interface{} {
+			state := map[string]interface{}{}
+			for _, v1 := range values {
+				state[string(v1)] = graph.GetVertexProperties(v1)
+				for _, v2 := range values {
+					state[string(v1+"-"+v2)] = graph.GetEdgeProperties(v1, v2)
+				}
+			}
+			return state
+		}
+
+		for _, graph := range replicas[1:] {
+			if got, want := copyProperties(graph.GetProperties()), copyProperties(replicas[0].GetProperties()); !reflect.DeepEqual(got, want) {
+				t.Errorf("LWWGraphImpl.GetProperties() of %v = %v, want %v, diff: %v", graph.GetReplica(), got, want, deep.Equal(got, want))
+			}
+			if got, want := visible(graph), visible(replicas[0]); !reflect.DeepEqual(got, want) {
+				t.Errorf("LWWGraphImpl.GetVertexProperties() of %v = %v, want %v", graph.GetReplica(), got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package undirect
+
+// ReplicaID is the identifier of the replica that a component is originated from
+type ReplicaID string
+
+// Component is the record of the adds or removal set of the vertices and edges,
+// the records are ordered by timestamp, then the replica id for the records
+// written by different replicas at the same time
+type Component interface {
+	GetTimestamp() int64
+	GetReplica() ReplicaID
+}
+
+// CompareComponents return the total order of two records, it returns
+//   - -1 when a is before b
+//   - 0 when a and b are written at the same time by the same replica
+//   - 1 when a is after b
+func CompareComponents(a, b Component) int {
+
+	switch {
+	case a.GetTimestamp() < b.GetTimestamp():
+		return -1
+	case a.GetTimestamp() > b.GetTimestamp():
+		return 1
+	case a.GetReplica() < b.GetReplica():
+		return -1
+	case a.GetReplica() > b.GetReplica():
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,222 @@
+package undirect
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestCompareComponents(t *testing.T) {
+
+	clock := &testCkock{}
+	later := &testCkock{}
+	later.AddDuration(time.Second)
+
+	tests := []struct {
+		name string
+		a, b Component
+		want int
+	}{
+		{
+			name: "earlier timestamp",
+			a:    NewLWWVertex("A", clock, "z"),
+			b:    NewLWWVertex("A", later, "a"),
+			want: -1,
+		},
+		{
+			name: "later timestamp",
+			a:    NewLWWVertex("A", later, "a"),
+			b:    NewLWWVertex("A", clock, "z"),
+			want: 1,
+		},
+		{
+			name: "same timestamp with smaller replica",
+			a:    NewLWWVertex("A", clock, "x"),
+			b:    NewLWWVertex("A", clock, "y"),
+			want: -1,
+		},
+		{
+			name: "same timestamp with greater replica",
+			a:    NewLWWVertex("A", clock, "y"),
+			b:    NewLWWVertex("A", clock, "x"),
+			want: 1,
+		},
+		{
+			name: "same timestamp and replica",
+			a:    NewLWWVertex("A", clock, "x"),
+			b:    NewLWWEdgeImpl([]LWWVertex{NewLWWVertex("A", clock, "x"), NewLWWVertex("B", clock, "x")}, clock, "x"),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareComponents(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareComponents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Check the replicas converge regardless of the merge order when the records are written at the same time
+func TestLWWGraphImpl_Merge_With_Same_Timestamp(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	type operation func(graph LWWGraph)
+
+	addEdge := func(v1, v2 VertexValue) operation {
+		return func(graph LWWGraph) {
+			graph.AddEdge(NewLWWVertex(v1, graph.GetClock(), graph.GetReplica()), NewLWWVertex(v2, graph.GetClock(), graph.GetReplica()))
+		}
+	}
+	addVertex := func(v VertexValue) operation {
+		return func(graph LWWGraph) { graph.AddVertex(v) }
+	}
+	removeVertex := func(v VertexValue) operation {
+		return func(graph LWWGraph) { graph.RemoveVertex(v) }
+	}
+	advance := func(d time.Duration) operation {
+		return func(graph LWWGraph) { graph.GetClock().(*testCkock).AddDuration(d) }
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		bias        Bias
+		xReplica    ReplicaID
+		yReplica    ReplicaID
+		xOperations []operation
+		yOperations []operation
+		want        map[VertexValue][]VertexValue
+		wantReplica map[VertexValue]ReplicaID
+	}{
+		{
+			name:        "concurrent adds of vertices",
+			description: "the record of the greater replica id is kept by both replicas",
+			bias:        Adds,
+			xReplica:    "x",
+			yReplica:    "y",
+			xOperations: []operation{addVertex(A), addVertex(B)},
+			yOperations: []operation{addVertex(A)},
+			want: map[VertexValue][]VertexValue{
+				A: {},
+				B: {},
+			},
+			wantReplica: map[VertexValue]ReplicaID{A: "y", B: "x"},
+		},
+		{
+			name:        "concurrent adds of edges",
+			description: "the record of the greater replica id is kept by both replicas",
+			bias:        Adds,
+			xReplica:    "y",
+			yReplica:    "x",
+			xOperations: []operation{addEdge(A, B)},
+			yOperations: []operation{addEdge(A, B)},
+			want: map[VertexValue][]VertexValue{
+				A: {B},
+				B: {A},
+			},
+			wantReplica: map[VertexValue]ReplicaID{A: "y", B: "y"},
+		},
+		{
+			name:        "concurrent add and remove of vertex with adds bias",
+			description: "the remove of the greater replica id is after the add, even with adds bias",
+			bias:        Adds,
+			xReplica:    "x",
+			yReplica:    "y",
+			xOperations: []operation{advance(time.Minute), addVertex(A), addVertex(B)},
+			yOperations: []operation{addVertex(A), advance(time.Minute), removeVertex(A)},
+			want: map[VertexValue][]VertexValue{
+				B: {},
+			},
+			wantReplica: map[VertexValue]ReplicaID{A: "x", B: "x"},
+		},
+		{
+			name:        "concurrent add and remove of vertex with removal bias",
+			description: "the add of the greater replica id is after the remove, even with removal bias",
+			bias:        Removal,
+			xReplica:    "z",
+			yReplica:    "y",
+			xOperations: []operation{advance(time.Minute), addVertex(A), addVertex(B)},
+			yOperations: []operation{addVertex(A), advance(time.Minute), removeVertex(A)},
+			want: map[VertexValue][]VertexValue{
+				A: {},
+				B: {},
+			},
+			wantReplica: map[VertexValue]ReplicaID{A: "z", B: "z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			newReplica := func(replica ReplicaID, operations []operation) LWWGraph {
+				// all of the replicas start at the same time
+				graph := NewLWWGraph(tt.bias, &testCkock{}, replica)
+				for _, op := range operations {
+					op(graph)
+				}
+				return graph
+			}
+
+			xLeft := newReplica(tt.xReplica, tt.xOperations)
+			yLeft := newReplica(tt.yReplica, tt.yOperations)
+			xRight := newReplica(tt.xReplica, tt.xOperations)
+			yRight := newReplica(tt.yReplica, tt.yOperations)
+
+			// X' = X U Y
+			xLeft.Merge(yLeft)
+			// Y' = Y U X
+			yRight.Merge(xRight)
+
+			gotLeft := xLeft.GetAdjacencyVerticesList()
+			gotRight := yRight.GetAdjacencyVerticesList()
+
+			if !reflect.DeepEqual(gotLeft, gotRight) {
+				t.Errorf("LWWGraphImpl.Merge() replicas diverged, got %v, want %v, diff: %v", gotLeft, gotRight, deep.Equal(gotLeft, gotRight))
+			}
+			if !reflect.DeepEqual(gotLeft, tt.want) {
+				t.Errorf("LWWGraphImpl.GetAdjacencyVerticesList() = %v, want %v, diff: %v", gotLeft, tt.want, deep.Equal(gotLeft, tt.want))
+			}
+
+			for value, replica := range tt.wantReplica {
+				left, right := xLeft.GetVertices()[value], yRight.GetVertices()[value]
+				if left.GetReplica() != replica || right.GetReplica() != replica {
+					t.Errorf("LWWGraphImpl.Merge() vertex %v replica = %v and %v, want %v", value, left.GetReplica(), right.GetReplica(), replica)
+				}
+			}
+		})
+	}
+}
+
+// Check adding the vertex again does not move the record merged from the replica of
+// which the clock is ahead back before the tombstone
+func TestLWWGraphImpl_AddVertex_After_Merge_From_Clock_Ahead(t *testing.T) {
+
+	A := NewVertexValue("A")
+
+	xClock, yClock := &testCkock{}, &testCkock{}
+	xClock.Now()
+	yClock.AddDuration(3 * time.Minute)
+
+	x := NewLWWGraph(Adds, xClock, "x")
+	xClock.AddDuration(4 * time.Minute)
+	x.AddVertex(A)
+	xClock.AddDuration(time.Minute)
+	x.RemoveVertex(A)
+	xClock.AddDuration(5 * time.Minute)
+	x.AddVertex(A)
+
+	y := NewLWWGraph(Adds, yClock, "y")
+	y.Merge(x)
+	y.AddVertex(A)
+
+	if got := y.IsVertexExist(A); !got {
+		t.Errorf("LWWGraphImpl.IsVertexExist() = %v, want %v", got, true)
+	}
+	if got, want := y.GetVertex(A).GetTimestamp(), xClock.Now().UnixNano(); got != want {
+		t.Errorf("LWWVertex.GetTimestamp() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,209 @@
+package undirect
+
+import "sort"
+
+// The report of the merge is the list of the vertices and the edges of which the records
+// are changed by the merge, or which are shown or hidden by the merge, such as the edge of
+// the vertex removed by the merge.
+
+// MergeChangeKind is the way the merge changed the component
+type MergeChangeKind int
+
+const (
+	// MergeAdded is the component that had no record before the merge
+	MergeAdded MergeChangeKind = iota
+	// MergeRemoved is the component that was removed by the merge
+	MergeRemoved
+	// MergeResurrected is the component removed or hidden before the merge that exists again
+	MergeResurrected
+	// MergeOverwritten is the component of which the records are written by the merge, but
+	// it exists or not as it was before the merge, such as the tombstone of the component
+	// that was not added
+	MergeOverwritten
+)
+
+// Resolution is the record of the component that decides it after the merge, the edge
+// of which the add record wins is still hidden when the vertex of it is removed
+type Resolution int
+
+const (
+	// AddWins is the add record that is after the remove record or that has no remove record
+	AddWins Resolution = iota
+	// TombstoneWins is the remove record that is after the add record or that has no add record
+	TombstoneWins
+)
+
+// MergeChangeOf is the vertex or the edge changed by the merge
+type MergeChangeOf[ID comparable] struct {
+	// Component is the vertex or the edge, which is identified as the owner of the properties
+	Component PropertyOwnerOf[ID]
+	Kind      MergeChangeKind
+	// LocalTimestamp and RemoteTimestamp are the timestamps of the latest records of the
+	// component of the replica before the merge and of the graph merged, which are zero
+	// when there is no record
+	LocalTimestamp, RemoteTimestamp int64
+	Resolution                      Resolution
+	// ByBias is true when the add and the remove records are at the same timestamp of the
+	// same replica, so the resolution is decided by the bias of the graph
+	ByBias bool
+}
+
+// MergeChange is the change of the graph of the string values
+type MergeChange = MergeChangeOf[VertexValue]
+
+// MergeReportOf is the changes of the merge
+type MergeReportOf[ID comparable] struct {
+	// Changes is sorted by the components, the vertices are before the edges
+	Changes []MergeChangeOf[ID]
+	// Conflicts is the components of which the version vectors are concurrent, as they are
+	// reported to the graph created with WithVersionVectors
+	Conflicts []ConflictOf[ID]
+}
+
+// MergeReport is the report of the graph of the string values
+type MergeReport = MergeReportOf[VertexValue]
+
+// MergeWithReport merge the other graph like Merge, and return the changes of the merge
+func (graph *LWWGraphImplOf[ID]) MergeWithReport(other LWWGraphOf[ID]) MergeReportOf[ID] {
+
+	report := MergeReportOf[ID]{}
+
+	graph.merge(other, &report)
+
+	return report
+}
+
+// componentBefore is the records of the replica of the component and whether it existed
+// before the merge
+type componentBefore struct {
+	add, remove Component
+	existed     bool
+}
+
+// recordsBefore return the records and the existence of the components that have the
+// records merged, the edges of the vertices of them, and the vertices of the edges, as the
+// existence of them depends on each other, the records are not updated in place by the
+// merge, so they are not copied
+func (graph *LWWGraphImplOf[ID]) recordsBefore(other mergedRecords[ID]) map[PropertyOwnerOf[ID]]componentBefore {
+
+	before := make(map[PropertyOwnerOf[ID]]componentBefore)
+
+	keep := func(component PropertyOwnerOf[ID]) {
+		if _, ok := before[component]; ok {
+			return
+		}
+		add, remove := graph.localRecords(component)
+		before[component] = componentBefore{add: add, remove: remove, existed: graph.isOwnerExist(component)}
+	}
+
+	vertices := make(map[ID]struct{})
+
+	for _, records := range []map[ID]LWWVertexOf[ID]{other.vertices, other.tombstoneVertices} {
+		for value := range records {
+			vertices[value] = struct{}{}
+			// the vertices connected might be resurrected by the edges only when the vertex exists
+			if graph.integrity != ResurrectVertices {
+				continue
+			}
+			graph.edgesMatrix.RangeRow(value, func(n ID, _ LWWEdgeOf[ID]) bool {
+				vertices[n] = struct{}{}
+				return true
+			})
+		}
+	}
+
+	for _, matrix := range []map[ID]map[ID]LWWEdgeOf[ID]{other.edges, other.tombstoneEdges} {
+		for m := range matrix {
+			vertices[m] = struct{}{}
+			for n := range matrix[m] {
+				vertices[n] = struct{}{}
+				keep(graph.edgeOwner(m, n))
+			}
+		}
+	}
+
+	for value := range vertices {
+		keep(vertexOwner(value))
+		graph.edgesMatrix.RangeRow(value, func(n ID, _ LWWEdgeOf[ID]) bool {
+			keep(graph.edgeOwner(value, n))
+			return true
+		})
+	}
+
+	return before
+}
+
+// reportChanges return the changes of the components of which the vertices and the matrix
+// cells are merged, or of which the existence is changed by the merge, before is the records
+// and the existence of the components before the merge
+func (graph *LWWGraphImplOf[ID]) reportChanges(vertices []ID, cells [][2]ID, before map[PropertyOwnerOf[ID]]componentBefore, other mergedRecords[ID]) []MergeChangeOf[ID] {
+
+	merged := make(map[PropertyOwnerOf[ID]]struct{})
+	for _, v := range vertices {
+		merged[vertexOwner(v)] = struct{}{}
+	}
+	for _, cell := range cells {
+		merged[graph.edgeOwner(cell[0], cell[1])] = struct{}{}
+	}
+
+	changes := []MergeChangeOf[ID]{}
+
+	for component, records := range before {
+
+		exists := graph.isOwnerExist(component)
+		if _, ok := merged[component]; !ok && exists == records.existed {
+			continue
+		}
+
+		add, remove := graph.localRecords(component)
+
+		change := MergeChangeOf[ID]{
+			Component:       component,
+			Kind:            MergeOverwritten,
+			LocalTimestamp:  latestRecordTimestamp(records.add, records.remove),
+			RemoteTimestamp: latestRecordTimestamp(other.get(component)),
+			Resolution:      AddWins,
+			ByBias:          add != nil && remove != nil && CompareComponents(add, remove) == 0,
+		}
+
+		switch {
+		case !records.existed && exists && (records.add != nil || records.remove != nil):
+			change.Kind = MergeResurrected
+		case !records.existed && exists:
+			change.Kind = MergeAdded
+		case records.existed && !exists:
+			change.Kind = MergeRemoved
+		}
+
+		if graph.isRemoved(add, remove) || add == nil {
+			change.Resolution = TombstoneWins
+		}
+
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return graph.lessOwner(changes[i].Component, changes[j].Component)
+	})
+
+	return changes
+}
+
+// latestRecordTimestamp return the timestamp of the later of the records, zero is
+// returned when there is no record
+func latestRecordTimestamp(add, remove Component) int64 {
+
+	var latest int64
+
+	for _, record := range []Component{add, remove} {
+		if record != nil && (latest == 0 || record.GetTimestamp() > latest) {
+			latest = record.GetTimestamp()
+		}
+	}
+
+	return latest
+}
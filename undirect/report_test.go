@@ -0,0 +1,329 @@
+package undirect
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestLWWGraphImpl_MergeWithReport(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	second := int64(time.Second)
+
+	tests := []struct {
+		name    string
+		bias    Bias
+		options []Option
+		operate func(x, y LWWGraph, xClock, yClock *testCkock)
+		want    []MergeChange
+	}{
+		{
+			name: "test added",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				yClock.AddDuration(time.Second)
+				y.AddVertex(A)
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(A), Kind: MergeAdded, RemoteTimestamp: second, Resolution: AddWins},
+			},
+		},
+		{
+			name: "test removed with the edges",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				xClock.AddDuration(time.Second)
+				x.AddEdge(NewLWWVertex(A, xClock, "x"), NewLWWVertex(B, xClock, "x"))
+				y.Merge(x)
+				yClock.AddDuration(2 * time.Second)
+				y.RemoveVertex(B)
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(B), Kind: MergeRemoved, LocalTimestamp: second, RemoteTimestamp: 2 * second, Resolution: TombstoneWins},
+				{Component: EdgeOwner(A, B), Kind: MergeRemoved, LocalTimestamp: second, RemoteTimestamp: 2 * second, Resolution: TombstoneWins},
+			},
+		},
+		{
+			name: "test removed by the vertex of the edge",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				xClock.AddDuration(time.Second)
+				x.AddVertex(A)
+				x.AddVertex(B)
+				y.Merge(x)
+				yClock.AddDuration(2 * time.Second)
+				y.RemoveVertex(B)
+				// the edge has no record merged, but it is hidden by the vertex removed
+				xClock.AddDuration(2 * time.Second)
+				x.AddEdge(x.GetVertex(A), x.GetVertex(B))
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(B), Kind: MergeRemoved, LocalTimestamp: second, RemoteTimestamp: 2 * second, Resolution: TombstoneWins},
+				{Component: EdgeOwner(A, B), Kind: MergeRemoved, LocalTimestamp: 3 * second, Resolution: AddWins},
+			},
+		},
+		{
+			name: "test resurrected",
+			bias: Removal,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				xClock.AddDuration(time.Second)
+				x.AddVertex(A)
+				xClock.AddDuration(time.Second)
+				x.RemoveVertex(A)
+				yClock.AddDuration(3 * time.Second)
+				y.AddVertex(A)
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(A), Kind: MergeResurrected, LocalTimestamp: 2 * second, RemoteTimestamp: 3 * second, Resolution: AddWins},
+			},
+		},
+		{
+			name:    "test resurrected by the edge",
+			bias:    Adds,
+			options: []Option{WithIntegrity(ResurrectVertices)},
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				xClock.AddDuration(time.Second)
+				x.AddVertex(A)
+				x.AddVertex(B)
+				y.Merge(x)
+				xClock.AddDuration(time.Second)
+				x.RemoveVertex(B)
+				// the vertex has no record merged, but it is resurrected by the edge
+				yClock.AddDuration(3 * time.Second)
+				y.AddEdge(y.GetVertex(A), y.GetVertex(B))
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(B), Kind: MergeResurrected, LocalTimestamp: 2 * second, RemoteTimestamp: second, Resolution: TombstoneWins},
+				{Component: EdgeOwner(A, B), Kind: MergeAdded, RemoteTimestamp: 3 * second, Resolution: AddWins},
+			},
+		},
+		{
+			name:    "test removed by the vertex connected",
+			bias:    Adds,
+			options: []Option{WithIntegrity(ResurrectVertices)},
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				zClock := &testCkock{}
+				zClock.Now()
+				z := NewLWWGraph(Adds, zClock, "x", WithIntegrity(ResurrectVertices))
+				xClock.AddDuration(time.Second)
+				x.AddVertex(A)
+				y.Merge(x)
+				x.AddVertex(B)
+				z.Merge(x)
+				xClock.AddDuration(time.Second)
+				x.RemoveVertex(B)
+				zClock.AddDuration(3 * time.Second)
+				z.AddEdge(z.GetVertex(A), z.GetVertex(B))
+				x.Merge(z)
+				// the vertex resurrected by the edge is removed along with the other vertex
+				yClock.AddDuration(4 * time.Second)
+				y.RemoveVertex(A)
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(A), Kind: MergeRemoved, LocalTimestamp: second, RemoteTimestamp: 4 * second, Resolution: TombstoneWins},
+				{Component: VertexOwner(B), Kind: MergeRemoved, LocalTimestamp: 2 * second, Resolution: TombstoneWins},
+				{Component: EdgeOwner(A, B), Kind: MergeRemoved, LocalTimestamp: 3 * second, Resolution: AddWins},
+			},
+		},
+		{
+			name: "test overwritten",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				xClock.AddDuration(time.Second)
+				x.AddVertex(A)
+				yClock.AddDuration(2 * time.Second)
+				y.AddVertex(A)
+				// the record of the replica is after the record merged
+				xClock.AddDuration(2 * time.Second)
+				x.AddVertex(B)
+				y.AddVertex(B)
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(A), Kind: MergeOverwritten, LocalTimestamp: second, RemoteTimestamp: 2 * second, Resolution: AddWins},
+			},
+		},
+		{
+			name: "test add wins by the adds bias",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				yClock.AddDuration(time.Second)
+				y.AddVertex(A)
+				y.RemoveVertex(A)
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(A), Kind: MergeAdded, RemoteTimestamp: second, Resolution: AddWins, ByBias: true},
+			},
+		},
+		{
+			name: "test tombstone wins by the removal bias",
+			bias: Removal,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				xClock.AddDuration(time.Second)
+				x.AddVertex(A)
+				y.Merge(x)
+				yClock.SyncWith(xClock)
+				y.RemoveVertex(A)
+			},
+			want: []MergeChange{
+				{Component: VertexOwner(A), Kind: MergeRemoved, LocalTimestamp: second, RemoteTimestamp: second, Resolution: TombstoneWins, ByBias: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			xClock, yClock := &testCkock{}, &testCkock{}
+			xClock.Now()
+			yClock.Now()
+			x := NewLWWGraph(tt.bias, xClock, "x", tt.options...)
+			y := NewLWWGraph(tt.bias, yClock, "x", tt.options...)
+
+			tt.operate(x, y, xClock, yClock)
+
+			if got := x.MergeWithReport(y); !reflect.DeepEqual(got.Changes, tt.want) {
+				t.Errorf("LWWGraphImpl.MergeWithReport() = %v, want %v, diff: %v", got.Changes, tt.want, deep.Equal(got.Changes, tt.want))
+			}
+
+			// the records merged already are not changed
+			if got := x.MergeWithReport(y); !reflect.DeepEqual(got, MergeReport{}) {
+				t.Errorf("LWWGraphImpl.MergeWithReport() = %v, want %v", got, MergeReport{})
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_MergeWithReport_Conflicts(t *testing.T) {
+
+	A := NewVertexValue("A")
+
+	xClock, yClock := &testCkock{}, &testCkock{}
+	xClock.Now()
+	yClock.AddDuration(time.Second)
+
+	// the report has the conflicts when the graph has no report of the version vectors
+	x := NewLWWGraph(Adds, xClock, "x", WithVersionVectors(nil))
+	y := NewLWWGraph(Adds, yClock, "y", WithVersionVectors(nil))
+	x.AddVertex(A)
+	y.AddVertex(A)
+
+	want := MergeReport{
+		Changes: []MergeChange{
+			{Component: VertexOwner(A), Kind: MergeOverwritten, RemoteTimestamp: int64(time.Second), Resolution: AddWins},
+		},
+		Conflicts: []Conflict{
+			{Component: VertexOwner(A), Local: VersionVector{"x": 1}, Remote: VersionVector{"y": 1}, Exists: true},
+		},
+	}
+	if got := x.MergeWithReport(y); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.MergeWithReport() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+}
+
+// Check the changes reported match the records of the graph, and the graphs wrapping
+// the graph report the same changes
+func TestLWWGraphImpl_MergeWithReport_Random_Operations(t *testing.T) {
+
+	values := newRandomValues(5)
+
+	for _, integrity := range []IntegrityPolicy{HideDanglingEdges, ResurrectVertices} {
+		for _, bias := range []Bias{Adds, Removal} {
+			for seed := int64(1); seed <= 5; seed++ {
+				t.Run(fmt.Sprintf("integrity %v bias %v seed %v", integrity, bias, seed), func(t *testing.T) {
+
+					option := WithIntegrity(integrity)
+					r := rand.New(rand.NewSource(seed))
+					replicas := []LWWGraph{}
+					for _, replica := range []ReplicaID{"x", "y", "z"} {
+						graph := NewLWWGraph(bias, &testCkock{}, replica, WithVersionVectors(nil), option)
+						graph.GetClock().Now()
+						replicas = append(replicas, graph)
+					}
+
+					for step := 0; step < 300; step++ {
+						applyRandomOperation(r, replicas, values)
+						if step%50 != 49 {
+							continue
+						}
+
+						graph, other := replicas[r.Intn(len(replicas))].(*LWWGraphImpl), replicas[r.Intn(len(replicas))]
+
+						logged, err := OpenLoggedLWWGraph(t.TempDir(), bias, NewHLC(&testCkock{}), graph.GetReplica(), option)
+						if err != nil {
+							t.Fatalf("OpenLoggedLWWGraph() error = %v", err)
+						}
+						logged.Merge(graph)
+						concurrent := NewConcurrentLWWGraph(NewLWWGraph(bias, &testCkock{}, graph.GetReplica(), option))
+						concurrent.Merge(graph)
+						op := NewOpLWWGraph(bias, &testCkock{}, graph.GetReplica(), func(op Op) {}, option)
+						op.Merge(graph)
+
+						before := NewLWWGraph(bias, &testCkock{}, graph.GetReplica(), option).(*LWWGraphImpl)
+						before.Merge(graph)
+						report := graph.MergeWithReport(other)
+
+						reported := make(map[PropertyOwner]struct{})
+						for _, change := range report.Changes {
+							reported[change.Component] = struct{}{}
+							if got, want := graph.isRemoved(graph.localRecords(change.Component)), change.Resolution == TombstoneWins; got != want {
+								t.Errorf("LWWGraphImpl.MergeWithReport() %v removed = %v, want %v", change, got, want)
+							}
+							existed, exists := before.isOwnerExist(change.Component), graph.isOwnerExist(change.Component)
+							switch change.Kind {
+							case MergeAdded, MergeResurrected:
+								if existed || !exists {
+									t.Errorf("LWWGraphImpl.MergeWithReport() %v existed = %v, exists = %v", change, existed, exists)
+								}
+							case MergeRemoved:
+								if !existed || exists {
+									t.Errorf("LWWGraphImpl.MergeWithReport() %v existed = %v, exists = %v", change, existed, exists)
+								}
+							default:
+								if existed != exists {
+									t.Errorf("LWWGraphImpl.MergeWithReport() %v existed = %v, exists = %v", change, existed, exists)
+								}
+							}
+						}
+
+						// the components shown or hidden by the merge are reported
+						for _, component := range graphOwners(graph) {
+							if _, ok := reported[component]; !ok && before.isOwnerExist(component) != graph.isOwnerExist(component) {
+								t.Errorf("LWWGraphImpl.MergeWithReport() %v is not reported", component)
+							}
+						}
+
+						for name, wrapped := range map[string]LWWGraph{"logged": logged, "concurrent": concurrent, "op": op} {
+							if got := wrapped.MergeWithReport(other); !reflect.DeepEqual(got, report) {
+								t.Errorf("%v: MergeWithReport() = %v, want %v, diff: %v", name, got, report, deep.Equal(got, report))
+							}
+						}
+						logged.Close()
+					}
+				})
+			}
+		}
+	}
+}
+
+// graphOwners return the vertices and the edges of which the graph has the records
+func graphOwners(graph *LWWGraphImpl) []PropertyOwner {
+
+	owners := []PropertyOwner{}
+
+	for value := range graph.GetVertices() {
+		owners = append(owners, VertexOwner(value))
+	}
+	for m, row := range graph.GetEdgesMatrix() {
+		for n := range row {
+			owners = append(owners, EdgeOwner(m, n))
+		}
+	}
+
+	return owners
+}
@@ -1,55 +1,128 @@
 package undirect
 
-type DFS struct {
-	LWWGraphImpl
-	start, end VertexValue
-	marked     map[VertexValue]bool
-	dict       map[VertexValue][]VertexValue
-	paths      []VertexValue
+import (
+	"context"
+)
+
+// DFSOf is the depth first search of the paths between two vertices
+type DFSOf[ID comparable] struct {
+	LWWGraphImplOf[ID]
+	start, end ID
+	marked     map[ID]bool
+	dict       map[ID][]ID
+	paths      []ID
 }
 
-func (graph *LWWGraphImpl) NewDFS(start, end VertexValue) *DFS {
-	return &DFS{
+// DFS is the depth first search of the graph of the string values
+type DFS = DFSOf[VertexValue]
+
+func (graph *LWWGraphImplOf[ID]) NewDFS(start, end ID) *DFSOf[ID] {
+	return &DFSOf[ID]{
 		*graph,
 		start, end,
-		make(map[VertexValue]bool),
-		graph.GetAdjacencyVerticesList(),
-		[]VertexValue{},
+		make(map[ID]bool),
+		make(map[ID][]ID),
+		[]ID{},
 	}
 }
 
-func (dfs *DFS) Search() [][]VertexValue {
+// Search return the simple paths between start and end, which are the paths of Walk without
+// the limits
+func (dfs *DFSOf[ID]) Search() [][]ID {
 
-	var (
-		current = []VertexValue{dfs.start}
-		result  = [][]VertexValue{}
-	)
+	result := [][]ID{}
 
-	return *dfs.search(current, &result)
+	dfs.Walk(context.Background(), 0, 0, func(path []ID) bool {
+		result = append(result, path)
+		return true
+	})
+
+	return result
 }
 
-func (dfs *DFS) search(current []VertexValue, result *[][]VertexValue) *[][]VertexValue {
+// Walk go through the simple paths between start and end, the paths are passed to f one by
+// one instead of being kept, and the start is not visited again.
+//
+// The paths longer than maxLength edges are not followed, and the walk stops after
+// maxResults paths, or when f return false, the limits are not applied when they are not
+// greater than zero. The error of the context is returned when it is done before the walk.
+func (dfs *DFSOf[ID]) Walk(ctx context.Context, maxLength, maxResults int, f func(path []ID) bool) error {
 
-	if current[len(current)-1].IsEqual(dfs.end) {
-		*result = append(*result, current)
+	if _, ok := dfs.index[dfs.start]; !ok {
+		return nil
+	}
+
+	walk := &pathWalk[ID]{
+		dfs:        dfs,
+		ctx:        ctx,
+		maxLength:  maxLength,
+		maxResults: maxResults,
+		f:          f,
+	}
+
+	dfs.marked[dfs.start] = true
+	walk.walk([]ID{dfs.start})
+
+	return walk.err
+}
+
+type pathWalk[ID comparable] struct {
+	dfs        *DFSOf[ID]
+	ctx        context.Context
+	maxLength  int
+	maxResults int
+	results    int
+	f          func(path []ID) bool
+	err        error
+}
+
+// walk follow the neighbours of the last vertex of the current path, it return false
+// when the walk is stopped
+func (walk *pathWalk[ID]) walk(current []ID) bool {
+
+	if err := walk.ctx.Err(); err != nil {
+		walk.err = err
+		return false
 	}
 
 	last := current[len(current)-1]
 
-	for i := 0; i < len(dfs.dict[last]); i++ {
+	if last == walk.dfs.end {
+		path := make([]ID, len(current))
+		copy(path, current)
+		walk.results++
+		if !walk.f(path) {
+			return false
+		}
+		if walk.maxResults > 0 && walk.results >= walk.maxResults {
+			return false
+		}
+		// the path cannot go through the end to another path of the end
+		return true
+	}
+
+	if walk.maxLength > 0 && len(current)-1 >= walk.maxLength {
+		return true
+	}
+
+	if _, ok := walk.dfs.dict[last]; !ok {
+		walk.dfs.dict[last] = walk.dfs.neighbours(last)
+	}
+
+	for _, n := range walk.dfs.dict[last] {
 
-		if exist, ok := dfs.marked[dfs.dict[last][i]]; ok && exist {
+		if walk.dfs.marked[n] {
 			continue
 		}
 
-		current = append(current, dfs.dict[last][i])
-		dfs.marked[dfs.dict[last][i]] = true
+		walk.dfs.marked[n] = true
+		ok := walk.walk(append(current, n))
+		walk.dfs.marked[n] = false
 
-		dfs.search(current, result)
-		dfs.marked[dfs.dict[last][i]] = false
-
-		current = current[:len(current)-1]
+		if !ok {
+			return false
+		}
 	}
 
-	return result
+	return true
 }
@@ -0,0 +1,205 @@
+package undirect
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLWWGraphImpl_WalkPaths(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+		   A - B - D - E
+			\ /  /
+			 C -
+	*/
+	paths := [][]VertexValue{
+		{A, B},
+		{A, C},
+		{B, C},
+		{B, D},
+		{C, D},
+		{D, E},
+	}
+
+	type args struct {
+		v1, v2     VertexValue
+		maxLength  int
+		maxResults int
+		stopAfter  int
+	}
+	tests := []struct {
+		name   string
+		fields mockFields
+		args   args
+		want   [][]VertexValue
+	}{
+		{
+			name:   "test walk without limits",
+			fields: mockFields{bias: Adds, verticesPaths: paths},
+			args:   args{v1: A, v2: E},
+			want: [][]VertexValue{
+				{A, B, C, D, E},
+				{A, B, D, E},
+				{A, C, B, D, E},
+				{A, C, D, E},
+			},
+		},
+		{
+			name:   "test walk with max length",
+			fields: mockFields{bias: Adds, verticesPaths: paths},
+			args:   args{v1: A, v2: E, maxLength: 3},
+			want: [][]VertexValue{
+				{A, B, D, E},
+				{A, C, D, E},
+			},
+		},
+		{
+			name:   "test walk with max results",
+			fields: mockFields{bias: Adds, verticesPaths: paths},
+			args:   args{v1: A, v2: E, maxResults: 3},
+			want: [][]VertexValue{
+				{A, B, C, D, E},
+				{A, B, D, E},
+				{A, C, B, D, E},
+			},
+		},
+		{
+			name:   "test walk stopped by the callback",
+			fields: mockFields{bias: Adds, verticesPaths: paths},
+			args:   args{v1: A, v2: E, stopAfter: 2},
+			want: [][]VertexValue{
+				{A, B, C, D, E},
+				{A, B, D, E},
+			},
+		},
+		{
+			name:   "test walk with removed vertex",
+			fields: mockFields{bias: Adds, verticesPaths: paths, removedVertices: []VertexValue{C}},
+			args:   args{v1: A, v2: E},
+			want: [][]VertexValue{
+				{A, B, D, E},
+			},
+		},
+		{
+			name:   "test walk without edges",
+			fields: mockFields{bias: Adds, verticesPaths: [][]VertexValue{{A, B}, {D, E}}},
+			args:   args{v1: A, v2: E},
+			want:   [][]VertexValue{},
+		},
+		{
+			name:   "test walk from the vertex to itself",
+			fields: mockFields{bias: Adds, verticesPaths: paths},
+			args:   args{v1: A, v2: A},
+			want:   [][]VertexValue{{A}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph := NewMockGraph(tt.fields)
+
+			got := [][]VertexValue{}
+			err := graph.WalkPaths(context.Background(), tt.args.v1, tt.args.v2, tt.args.maxLength, tt.args.maxResults, func(path []VertexValue) bool {
+				got = append(got, path)
+				return len(got) != tt.args.stopAfter
+			})
+			if err != nil {
+				t.Errorf("LWWGraphImpl.WalkPaths() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.WalkPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newCompleteGraph return the graph of which every vertex is connected to every other
+// vertex, the number of the simple paths between two vertices is too many to enumerate
+func newCompleteGraph(size int) (LWWGraph, []VertexValue) {
+
+	graph := NewLWWGraph(Adds, nil, "")
+	clock := graph.GetClock()
+
+	values := []VertexValue{}
+	for i := 0; i < size; i++ {
+		values = append(values, NewVertexValue(fmt.Sprintf("%03d", i)))
+	}
+	for i := range values {
+		for j := i + 1; j < len(values); j++ {
+			graph.AddEdge(NewLWWVertex(values[i], clock, ""), NewLWWVertex(values[j], clock, ""))
+		}
+	}
+
+	return graph, values
+}
+
+func TestLWWGraphImpl_WalkPaths_Cancel(t *testing.T) {
+
+	graph, values := newCompleteGraph(30)
+
+	t.Run("test walk cancelled", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		count := 0
+		err := graph.WalkPaths(ctx, values[0], values[29], 0, 0, func(path []VertexValue) bool {
+			count++
+			if count == 10 {
+				cancel()
+			}
+			return true
+		})
+		if err != context.Canceled {
+			t.Errorf("LWWGraphImpl.WalkPaths() error = %v, want %v", err, context.Canceled)
+		}
+		if count != 10 {
+			t.Errorf("LWWGraphImpl.WalkPaths() passed %v paths, want 10", count)
+		}
+	})
+
+	t.Run("test walk timeout", func(t *testing.T) {
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := graph.WalkPaths(ctx, values[0], values[29], 0, 0, func(path []VertexValue) bool {
+			return true
+		})
+		if err != context.DeadlineExceeded {
+			t.Errorf("LWWGraphImpl.WalkPaths() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("LWWGraphImpl.WalkPaths() returned after %v", elapsed)
+		}
+	})
+
+	t.Run("test walk with limits", func(t *testing.T) {
+
+		count := 0
+		err := graph.WalkPaths(context.Background(), values[0], values[29], 2, 0, func(path []VertexValue) bool {
+			if len(path) > 3 {
+				t.Errorf("LWWGraphImpl.WalkPaths() path = %v, want at most 2 edges", path)
+			}
+			count++
+			return true
+		})
+		if err != nil {
+			t.Errorf("LWWGraphImpl.WalkPaths() error = %v", err)
+		}
+		// the direct edge and the paths through every other vertex
+		if count != 29 {
+			t.Errorf("LWWGraphImpl.WalkPaths() passed %v paths, want 29", count)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package undirect
+
+import (
+	"container/heap"
+)
+
+// The shortest paths go through the neighbours of the index only, so the removed vertices
+// and edges are never a part of the path, no matter how short the path would be with them.
+
+// edgeWeight return the weight of the edge of the cell, the edge without weight weighs 1
+func (graph *LWWGraphImplOf[ID]) edgeWeight(m, n ID) float64 {
+
+	edge, ok := graph.edgesMatrix.Get(m, n)
+	if !ok {
+		return 1
+	}
+
+	if weight, ok := edge.GetWeight(); ok {
+		return weight
+	}
+
+	return 1
+}
+
+// ShortestPath search the path by Dijkstra, the vertices of the same distance are visited
+// by the order of the values, so the same path is returned for the same graph
+func (graph *LWWGraphImplOf[ID]) ShortestPath(start, end ID) ([]ID, float64) {
+
+	if _, ok := graph.index[start]; !ok {
+		return nil, 0
+	}
+	if _, ok := graph.index[end]; !ok {
+		return nil, 0
+	}
+
+	distances := map[ID]float64{start: 0}
+	previous := make(map[ID]ID)
+	visited := make(map[ID]bool)
+
+	queue := &distanceQueue[ID]{items: []distanceItem[ID]{{value: start}}, less: graph.less}
+
+	for queue.Len() > 0 {
+
+		current := heap.Pop(queue).(distanceItem[ID])
+		if visited[current.value] {
+			continue
+		}
+		visited[current.value] = true
+
+		if current.value == end {
+			break
+		}
+
+		for _, n := range graph.neighbours(current.value) {
+			if visited[n] {
+				continue
+			}
+			distance := current.distance + graph.edgeWeight(current.value, n)
+			if d, ok := distances[n]; ok && d <= distance {
+				continue
+			}
+			distances[n] = distance
+			previous[n] = current.value
+			heap.Push(queue, distanceItem[ID]{value: n, distance: distance})
+		}
+	}
+
+	if !visited[end] {
+		return nil, 0
+	}
+
+	path := []ID{end}
+	for v := end; v != start; {
+		v = previous[v]
+		path = append(path, v)
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path, distances[end]
+}
+
+// ShortestPathHops search the path by BFS, the neighbours are visited by the order of
+// the values, so the same path is returned for the same graph
+func (graph *LWWGraphImplOf[ID]) ShortestPathHops(start, end ID) []ID {
+
+	if _, ok := graph.index[end]; !ok {
+		return nil
+	}
+
+	previous, found := graph.bfs(start, end)
+	if !found {
+		return nil
+	}
+
+	path := []ID{end}
+	for v := end; v != start; {
+		v = previous[v]
+		path = append(path, v)
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+func (graph *LWWGraphImplOf[ID]) Distances(from ID) map[ID]int {
+
+	if _, ok := graph.index[from]; !ok {
+		return nil
+	}
+
+	distances := map[ID]int{from: 0}
+	queue := []ID{from}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for n := range graph.index[current] {
+			if _, ok := distances[n]; ok {
+				continue
+			}
+			distances[n] = distances[current] + 1
+			queue = append(queue, n)
+		}
+	}
+
+	return distances
+}
+
+// bfs visit the vertices from start until end is visited, and return the previous vertex
+// of every vertex visited on the way from start
+func (graph *LWWGraphImplOf[ID]) bfs(start, end ID) (map[ID]ID, bool) {
+
+	if _, ok := graph.index[start]; !ok {
+		return nil, false
+	}
+
+	previous := map[ID]ID{start: start}
+	queue := []ID{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == end {
+			return previous, true
+		}
+		for _, n := range graph.neighbours(current) {
+			if _, ok := previous[n]; ok {
+				continue
+			}
+			previous[n] = current
+			queue = append(queue, n)
+		}
+	}
+
+	return previous, false
+}
+
+type distanceItem[ID comparable] struct {
+	value    ID
+	distance float64
+}
+
+// distanceQueue is the min heap of the vertices by the distance then the value
+type distanceQueue[ID comparable] struct {
+	items []distanceItem[ID]
+	less  lessFunc[ID]
+}
+
+func (q *distanceQueue[ID]) Len() int {
+	return len(q.items)
+}
+
+func (q *distanceQueue[ID]) Less(i, j int) bool {
+	if q.items[i].distance != q.items[j].distance {
+		return q.items[i].distance < q.items[j].distance
+	}
+	return q.less(q.items[i].value, q.items[j].value)
+}
+
+func (q *distanceQueue[ID]) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+}
+
+func (q *distanceQueue[ID]) Push(x interface{}) {
+	q.items = append(q.items, x.(distanceItem[ID]))
+}
+
+func (q *distanceQueue[ID]) Pop() interface{} {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
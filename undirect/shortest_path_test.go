@@ -0,0 +1,431 @@
+package undirect
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type weightedEdge struct {
+	v1, v2 VertexValue
+	weight float64
+}
+
+type weightedMockFields struct {
+	bias            Bias
+	edges           []weightedEdge
+	removedEdges    [][2]VertexValue
+	removedVertices []VertexValue
+}
+
+// NewWeightedMockGraph add the edges, then remove the edges and the vertices, the clock
+// moves for every operation, so the removals are after the edges
+func NewWeightedMockGraph(fields weightedMockFields, replica ReplicaID) (LWWGraph, *testCkock) {
+
+	clock := &testCkock{}
+	graph := NewLWWGraph(fields.bias, clock, replica)
+
+	for _, e := range fields.edges {
+		clock.AddDuration(time.Second)
+		v1 := NewLWWVertex(e.v1, clock, replica)
+		v2 := NewLWWVertex(e.v2, clock, replica)
+		if e.weight < 0 {
+			graph.AddEdge(v1, v2)
+			continue
+		}
+		graph.AddWeightedEdge(v1, v2, e.weight)
+	}
+
+	for _, e := range fields.removedEdges {
+		clock.AddDuration(time.Second)
+		graph.RemoveEdgeByVertices(e[0], e[1])
+	}
+
+	for _, v := range fields.removedVertices {
+		clock.AddDuration(time.Second)
+		graph.RemoveVertex(v)
+	}
+
+	return graph, clock
+}
+
+func TestLWWGraphImpl_ShortestPath(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+	   A -10- B
+	   |      |
+	   1      1
+	   |      |
+	   C --1- D -5- E
+	*/
+	edges := []weightedEdge{
+		{A, B, 10},
+		{A, C, 1},
+		{C, D, 1},
+		{D, B, 1},
+		{D, E, 5},
+	}
+
+	type args struct {
+		start, end VertexValue
+	}
+	tests := []struct {
+		name     string
+		fields   weightedMockFields
+		args     args
+		wantPath []VertexValue
+		wantCost float64
+	}{
+		{
+			name:     "test the lighter path of more edges",
+			fields:   weightedMockFields{bias: Adds, edges: edges},
+			args:     args{A, B},
+			wantPath: []VertexValue{A, C, D, B},
+			wantCost: 3,
+		},
+		{
+			name:     "test the path from the vertex to itself",
+			fields:   weightedMockFields{bias: Adds, edges: edges},
+			args:     args{A, A},
+			wantPath: []VertexValue{A},
+			wantCost: 0,
+		},
+		{
+			name:     "test the removed edge of the lighter path",
+			fields:   weightedMockFields{bias: Adds, edges: edges, removedEdges: [][2]VertexValue{{D, B}}},
+			args:     args{A, B},
+			wantPath: []VertexValue{A, B},
+			wantCost: 10,
+		},
+		{
+			name:     "test the removed vertex of the lighter path",
+			fields:   weightedMockFields{bias: Removal, edges: edges, removedVertices: []VertexValue{C}},
+			args:     args{A, B},
+			wantPath: []VertexValue{A, B},
+			wantCost: 10,
+		},
+		{
+			name:     "test the removed edge of the only path",
+			fields:   weightedMockFields{bias: Adds, edges: edges, removedEdges: [][2]VertexValue{{D, E}}},
+			args:     args{A, E},
+			wantPath: nil,
+			wantCost: 0,
+		},
+		{
+			name:     "test the removed end",
+			fields:   weightedMockFields{bias: Adds, edges: edges, removedVertices: []VertexValue{E}},
+			args:     args{A, E},
+			wantPath: nil,
+			wantCost: 0,
+		},
+		{
+			name: "test the edges without weight",
+			fields: weightedMockFields{bias: Adds, edges: []weightedEdge{
+				{A, B, -1},
+				{B, C, -1},
+				{A, D, 0.5},
+				{D, E, 0.5},
+				{E, C, 0.5},
+			}},
+			args:     args{A, C},
+			wantPath: []VertexValue{A, D, E, C},
+			wantCost: 1.5,
+		},
+		{
+			name: "test the paths of the same cost",
+			fields: weightedMockFields{bias: Adds, edges: []weightedEdge{
+				{A, C, 1},
+				{C, D, 1},
+				{A, B, 1},
+				{B, D, 1},
+			}},
+			args:     args{A, D},
+			wantPath: []VertexValue{A, B, D},
+			wantCost: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph, _ := NewWeightedMockGraph(tt.fields, "")
+
+			gotPath, gotCost := graph.ShortestPath(tt.args.start, tt.args.end)
+			if !reflect.DeepEqual(gotPath, tt.wantPath) {
+				t.Errorf("LWWGraphImpl.ShortestPath() path = %v, want %v", gotPath, tt.wantPath)
+			}
+			if gotCost != tt.wantCost {
+				t.Errorf("LWWGraphImpl.ShortestPath() cost = %v, want %v", gotCost, tt.wantCost)
+			}
+		})
+	}
+}
+
+// Check the weight is resolved along with the edge by merge
+func TestLWWGraphImpl_ShortestPath_Merge(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	fields := weightedMockFields{bias: Adds, edges: []weightedEdge{
+		{A, B, 10},
+		{A, C, 3},
+		{C, B, 3},
+	}}
+
+	tests := []struct {
+		name     string
+		x, y     func(graph LWWGraph, clock *testCkock)
+		wantPath []VertexValue
+		wantCost float64
+	}{
+		{
+			name: "test the later weight",
+			x: func(graph LWWGraph, clock *testCkock) {
+				graph.AddWeightedEdge(NewLWWVertex(A, clock, "x"), NewLWWVertex(B, clock, "x"), 2)
+			},
+			y: func(graph LWWGraph, clock *testCkock) {
+				clock.AddDuration(time.Second)
+				graph.AddWeightedEdge(NewLWWVertex(A, clock, "y"), NewLWWVertex(B, clock, "y"), 8)
+			},
+			wantPath: []VertexValue{A, C, B},
+			wantCost: 6,
+		},
+		{
+			name: "test the later weight of the edge without weight",
+			x: func(graph LWWGraph, clock *testCkock) {
+				clock.AddDuration(time.Second)
+				graph.AddEdge(NewLWWVertex(A, clock, "x"), NewLWWVertex(B, clock, "x"))
+			},
+			y: func(graph LWWGraph, clock *testCkock) {
+				graph.AddWeightedEdge(NewLWWVertex(A, clock, "y"), NewLWWVertex(B, clock, "y"), 8)
+			},
+			wantPath: []VertexValue{A, B},
+			wantCost: 1,
+		},
+		{
+			name: "test the weight of the edge removed later",
+			x: func(graph LWWGraph, clock *testCkock) {
+				graph.AddWeightedEdge(NewLWWVertex(A, clock, "x"), NewLWWVertex(B, clock, "x"), 1)
+			},
+			y: func(graph LWWGraph, clock *testCkock) {
+				clock.AddDuration(time.Second)
+				graph.RemoveEdgeByVertices(A, B)
+			},
+			wantPath: []VertexValue{A, C, B},
+			wantCost: 6,
+		},
+		{
+			name: "test the weight of the removed edge added later",
+			x: func(graph LWWGraph, clock *testCkock) {
+				clock.AddDuration(time.Second)
+				graph.AddWeightedEdge(NewLWWVertex(A, clock, "x"), NewLWWVertex(B, clock, "x"), 1)
+			},
+			y: func(graph LWWGraph, clock *testCkock) {
+				graph.RemoveEdgeByVertices(A, B)
+			},
+			wantPath: []VertexValue{A, B},
+			wantCost: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			x, xClock := NewWeightedMockGraph(fields, "x")
+			y, yClock := NewWeightedMockGraph(fields, "y")
+
+			xClock.AddDuration(time.Second)
+			yClock.AddDuration(time.Second)
+			tt.x(x, xClock)
+			tt.y(y, yClock)
+
+			xy := x.Delta(math.MinInt64)
+			xy.Merge(y)
+			yx := y.Delta(math.MinInt64)
+			yx.Merge(x)
+
+			for _, graph := range []LWWGraph{xy, yx} {
+				gotPath, gotCost := graph.ShortestPath(A, B)
+				if !reflect.DeepEqual(gotPath, tt.wantPath) || gotCost != tt.wantCost {
+					t.Errorf("LWWGraphImpl.ShortestPath() of %v = %v %v, want %v %v", graph.GetReplica(), gotPath, gotCost, tt.wantPath, tt.wantCost)
+				}
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_AddWeightedEdge_Invalid_Weight(t *testing.T) {
+
+	for _, weight := range []float64{-1, math.NaN(), math.Inf(1)} {
+
+		graph := NewLWWGraph(Adds, nil, "")
+		clock := graph.GetClock()
+
+		if got := graph.AddWeightedEdge(NewLWWVertex("A", clock, ""), NewLWWVertex("B", clock, ""), weight); got != nil {
+			t.Errorf("LWWGraphImpl.AddWeightedEdge() of %v = %v, want nil", weight, got)
+		}
+		if got := graph.GetVertices(); len(got) != 0 {
+			t.Errorf("LWWGraphImpl.AddWeightedEdge() of %v added vertices %v", weight, got)
+		}
+	}
+}
+
+func TestLWWGraphImpl_ShortestPathHops(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+	E := NewVertexValue("E")
+
+	/*
+		   A - B - D - E
+			\ /  /
+			 C -
+	*/
+	paths := [][]VertexValue{
+		{A, B},
+		{A, C},
+		{B, C},
+		{B, D},
+		{C, D},
+		{D, E},
+	}
+
+	type args struct {
+		start, end VertexValue
+	}
+	tests := []struct {
+		name          string
+		fields        mockFields
+		args          args
+		want          []VertexValue
+		wantDistances map[VertexValue]int
+	}{
+		{
+			name:          "test the path of the least edges",
+			fields:        mockFields{bias: Adds, verticesPaths: paths},
+			args:          args{A, E},
+			want:          []VertexValue{A, B, D, E},
+			wantDistances: map[VertexValue]int{A: 0, B: 1, C: 1, D: 2, E: 3},
+		},
+		{
+			name:          "test the removed vertex",
+			fields:        mockFields{bias: Adds, verticesPaths: paths, removedVertices: []VertexValue{B}},
+			args:          args{A, E},
+			want:          []VertexValue{A, C, D, E},
+			wantDistances: map[VertexValue]int{A: 0, C: 1, D: 2, E: 3},
+		},
+		{
+			name:          "test the removed vertex of the only path",
+			fields:        mockFields{bias: Adds, verticesPaths: paths, removedVertices: []VertexValue{D}},
+			args:          args{A, E},
+			want:          nil,
+			wantDistances: map[VertexValue]int{A: 0, B: 1, C: 1},
+		},
+		{
+			name:          "test the removed start",
+			fields:        mockFields{bias: Adds, verticesPaths: paths, removedVertices: []VertexValue{A}},
+			args:          args{A, E},
+			want:          nil,
+			wantDistances: nil,
+		},
+		{
+			name:          "test the path from the vertex to itself",
+			fields:        mockFields{bias: Adds, verticesPaths: [][]VertexValue{{A, B}}},
+			args:          args{A, A},
+			want:          []VertexValue{A},
+			wantDistances: map[VertexValue]int{A: 0, B: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph := NewMockGraph(tt.fields)
+
+			if got := graph.ShortestPathHops(tt.args.start, tt.args.end); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.ShortestPathHops() = %v, want %v", got, tt.want)
+			}
+			if got := graph.Distances(tt.args.start); !reflect.DeepEqual(got, tt.wantDistances) {
+				t.Errorf("LWWGraphImpl.Distances() = %v, want %v", got, tt.wantDistances)
+			}
+		})
+	}
+}
+
+// Check the removed edges are not a part of the path, the removed edge is the only
+// short cut of the graph
+func TestLWWGraphImpl_ShortestPathHops_Removed_Edge(t *testing.T) {
+
+	graph, clock := NewWeightedMockGraph(weightedMockFields{bias: Adds}, "")
+
+	values := []VertexValue{}
+	for i := 0; i < 10; i++ {
+		values = append(values, NewVertexValue(string(rune('A'+i))))
+	}
+	for i := 1; i < len(values); i++ {
+		graph.AddEdge(NewLWWVertex(values[i-1], clock, ""), NewLWWVertex(values[i], clock, ""))
+	}
+	graph.AddEdge(NewLWWVertex(values[0], clock, ""), NewLWWVertex(values[9], clock, ""))
+
+	if got, want := graph.ShortestPathHops(values[0], values[9]), []VertexValue{values[0], values[9]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.ShortestPathHops() = %v, want %v", got, want)
+	}
+
+	clock.AddDuration(time.Second)
+	graph.RemoveEdgeByVertices(values[9], values[0])
+
+	if got := graph.ShortestPathHops(values[0], values[9]); !reflect.DeepEqual(got, values) {
+		t.Errorf("LWWGraphImpl.ShortestPathHops() = %v, want %v", got, values)
+	}
+	if got := graph.Distances(values[0])[values[9]]; got != 9 {
+		t.Errorf("LWWGraphImpl.Distances() of %v = %v, want 9", values[9], got)
+	}
+}
+
+// Check the queries return quickly on the complete graph, which has too many paths for GetPaths
+func TestLWWGraphImpl_ShortestPathHops_Dense(t *testing.T) {
+
+	graph := NewLWWGraph(Adds, nil, "")
+	clock := graph.GetClock()
+
+	values := []VertexValue{}
+	for i := 0; i < 200; i++ {
+		values = append(values, NewVertexValue(fmt.Sprintf("%03d", i)))
+	}
+	for i := range values {
+		for j := i + 1; j < len(values); j++ {
+			graph.AddEdge(NewLWWVertex(values[i], clock, ""), NewLWWVertex(values[j], clock, ""))
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		if got, want := graph.ShortestPathHops(values[0], values[199]), []VertexValue{values[0], values[199]}; !reflect.DeepEqual(got, want) {
+			t.Errorf("LWWGraphImpl.ShortestPathHops() = %v, want %v", got, want)
+		}
+
+		distances := graph.Distances(values[0])
+		for _, v := range values[1:] {
+			if distances[v] != 1 {
+				t.Errorf("LWWGraphImpl.Distances() of %v = %v, want 1", v, distances[v])
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("LWWGraphImpl.ShortestPathHops() does not return in time")
+	}
+}
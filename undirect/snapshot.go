@@ -0,0 +1,749 @@
+package undirect
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The snapshot is the binary form of the state of the graph, it is smaller than the JSON
+// and it can be written and read record by record, so the graph is not buffered as a whole.
+//
+// The snapshot starts with the magic and the version, followed by the frames of:
+//   - the header, which is the bias and the replica id
+//   - the records of the vertices, the tombstone vertices, the edges and the tombstone edges
+//   - the registers of the properties
+//   - the version vectors of the vertices and the edges
+//   - the end, which is the number of the records, so a snapshot cut at the frame boundary
+//     is not taken as a complete one
+//
+// The strings are written once, the first reference of a string carries the string itself
+// and the following references are the index of it in the string table. The timestamps are
+// written as the difference to the previous timestamp of the snapshot.
+
+const (
+	snapshotMagic = "LWWG"
+	// SnapshotVersion is the version of the snapshot written and read
+	SnapshotVersion = 1
+)
+
+const (
+	snapshotHeader byte = iota + 1
+	snapshotVertex
+	snapshotTombstoneVertex
+	snapshotEdge
+	snapshotTombstoneEdge
+	snapshotEnd
+	snapshotProperty
+	snapshotVersionVector
+)
+
+var (
+	// ErrSnapshotMagic is returned when the data is not a snapshot
+	ErrSnapshotMagic = errors.New("not a snapshot")
+	// ErrSnapshotVersion is returned when the version of the snapshot is not supported
+	ErrSnapshotVersion = errors.New("unsupported snapshot version")
+	// ErrSnapshotTruncated is returned when the snapshot ends before the end record
+	ErrSnapshotTruncated = errors.New("snapshot truncated")
+	// ErrSnapshotCorrupt is returned when the snapshot is changed or not written by the writer
+	ErrSnapshotCorrupt = errors.New("snapshot corrupt")
+)
+
+// SnapshotError is the error of reading the snapshot, the error is one of the snapshot
+// errors or the error of the reader, and the offset is where the frame of it starts
+type SnapshotError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("undirect: %v at offset %v", e.Err, e.Offset)
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// SnapshotRecord is the record of the snapshot, it is a vertex record when Vertex is
+// set, a record of the cell of Row and Column of the matrix when Edge is set, the
+// register of the Key of the Owner when Property is set, or the version vector of the
+// component of the Owner when Version is set
+type SnapshotRecord struct {
+	Tombstone   bool
+	Vertex      LWWVertex
+	Row, Column VertexValue
+	Edge        LWWEdge
+	Owner       PropertyOwner
+	Key         string
+	Property    LWWProperty
+	Version     VersionVector
+}
+
+type SnapshotWriter struct {
+	w       *bufio.Writer
+	strings map[string]uint64
+	last    int64
+	count   uint64
+	buf     []byte
+}
+
+// NewSnapshotWriter write the header of the snapshot and return the writer for the records,
+// the snapshot is only complete when the writer is closed, the bias is the bias of both of
+// the vertices and the edges
+func NewSnapshotWriter(w io.Writer, bias Bias, replica ReplicaID) (*SnapshotWriter, error) {
+	return newSnapshotWriter(w, bias, bias, replica)
+}
+
+func newSnapshotWriter(w io.Writer, bias, edgeBias Bias, replica ReplicaID) (*SnapshotWriter, error) {
+
+	sw := &SnapshotWriter{
+		w:       bufio.NewWriter(w),
+		strings: make(map[string]uint64),
+	}
+
+	if _, err := sw.w.WriteString(snapshotMagic); err != nil {
+		return nil, err
+	}
+	if err := sw.w.WriteByte(SnapshotVersion); err != nil {
+		return nil, err
+	}
+
+	sw.buf = append(sw.buf[:0], snapshotHeader, byte(bias), byte(edgeBias))
+	sw.buf = appendString(sw.buf, string(replica))
+
+	if err := writeFrame(sw.w, sw.buf); err != nil {
+		return nil, err
+	}
+
+	return sw, nil
+}
+
+func (sw *SnapshotWriter) WriteVertex(vertex LWWVertex, tombstone bool) error {
+
+	kind := snapshotVertex
+	if tombstone {
+		kind = snapshotTombstoneVertex
+	}
+
+	sw.buf = append(sw.buf[:0], kind)
+	sw.appendVertex(vertex)
+
+	sw.count++
+	return writeFrame(sw.w, sw.buf)
+}
+
+// WriteEdge write the record of the cell of m and n of the matrix
+func (sw *SnapshotWriter) WriteEdge(m, n VertexValue, edge LWWEdge, tombstone bool) error {
+
+	kind := snapshotEdge
+	if tombstone {
+		kind = snapshotTombstoneEdge
+	}
+
+	sw.buf = append(sw.buf[:0], kind)
+	sw.appendString(string(m))
+	sw.appendString(string(n))
+	sw.appendTimestamp(edge.GetTimestamp())
+	sw.appendString(string(edge.GetReplica()))
+
+	vertices := edge.GetVertices()
+	sw.buf = appendUvarint(sw.buf, uint64(len(vertices)))
+	for _, v := range vertices {
+		sw.appendVertex(v)
+	}
+	sw.buf = appendWeight(sw.buf, edge)
+
+	sw.count++
+	return writeFrame(sw.w, sw.buf)
+}
+
+// WriteProperty write the register of the key of the owner
+func (sw *SnapshotWriter) WriteProperty(owner PropertyOwner, key string, property LWWProperty) error {
+
+	sw.buf = append(sw.buf[:0], snapshotProperty)
+	sw.appendOwner(owner)
+	sw.appendString(key)
+	sw.appendString(property.GetValue())
+	if property.IsDeleted() {
+		sw.buf = append(sw.buf, 1)
+	} else {
+		sw.buf = append(sw.buf, 0)
+	}
+	sw.appendTimestamp(property.GetTimestamp())
+	sw.appendString(string(property.GetReplica()))
+
+	sw.count++
+	return writeFrame(sw.w, sw.buf)
+}
+
+// WriteVersion write the version vector of the component, the replicas are written sorted
+func (sw *SnapshotWriter) WriteVersion(component PropertyOwner, version VersionVector) error {
+
+	replicas := make([]ReplicaID, 0, len(version))
+	for replica := range version {
+		replicas = append(replicas, replica)
+	}
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i] < replicas[j]
+	})
+
+	sw.buf = append(sw.buf[:0], snapshotVersionVector)
+	sw.appendOwner(component)
+	sw.buf = appendUvarint(sw.buf, uint64(len(replicas)))
+	for _, replica := range replicas {
+		sw.appendString(string(replica))
+		sw.buf = appendUvarint(sw.buf, version[replica])
+	}
+
+	sw.count++
+	return writeFrame(sw.w, sw.buf)
+}
+
+// Close write the end of the snapshot and flush it, the underlying writer is not closed
+func (sw *SnapshotWriter) Close() error {
+
+	sw.buf = append(sw.buf[:0], snapshotEnd)
+	sw.buf = appendUvarint(sw.buf, sw.count)
+
+	if err := writeFrame(sw.w, sw.buf); err != nil {
+		return err
+	}
+
+	return sw.w.Flush()
+}
+
+func (sw *SnapshotWriter) appendVertex(vertex LWWVertex) {
+	sw.appendString(string(vertex.GetValue()))
+	sw.appendTimestamp(vertex.GetTimestamp())
+	sw.appendString(string(vertex.GetReplica()))
+}
+
+// appendOwner append the flag of the edge followed by the values of the owner
+func (sw *SnapshotWriter) appendOwner(owner PropertyOwner) {
+	if owner.Edge {
+		sw.buf = append(sw.buf, 1)
+		sw.appendString(string(owner.Vertices[0]))
+		sw.appendString(string(owner.Vertices[1]))
+	} else {
+		sw.buf = append(sw.buf, 0)
+		sw.appendString(string(owner.Vertices[0]))
+	}
+}
+
+// appendString append the index of the string in the table, or zero followed by the
+// string when it is not in the table yet, so the index is one greater than the position
+func (sw *SnapshotWriter) appendString(s string) {
+
+	if index, ok := sw.strings[s]; ok {
+		sw.buf = appendUvarint(sw.buf, index)
+		return
+	}
+
+	sw.strings[s] = uint64(len(sw.strings)) + 1
+	sw.buf = appendUvarint(sw.buf, 0)
+	sw.buf = appendString(sw.buf, s)
+}
+
+func (sw *SnapshotWriter) appendTimestamp(timestamp int64) {
+	sw.buf = appendVarint(sw.buf, timestamp-sw.last)
+	sw.last = timestamp
+}
+
+type SnapshotReader struct {
+	frames   *frameReader
+	strings  []string
+	last     int64
+	count    uint64
+	bias     Bias
+	edgeBias Bias
+	replica  ReplicaID
+	done     bool
+}
+
+// NewSnapshotReader read the header of the snapshot and return the reader for the records
+func NewSnapshotReader(r io.Reader) (*SnapshotReader, error) {
+
+	header := make([]byte, len(snapshotMagic)+1)
+	if n, err := io.ReadFull(r, header); err == io.EOF || err == io.ErrUnexpectedEOF {
+		// the data is a snapshot cut in the header when it is a part of the magic
+		if !strings.HasPrefix(snapshotMagic, string(header[:n])) {
+			return nil, &SnapshotError{Offset: 0, Err: ErrSnapshotMagic}
+		}
+		return nil, &SnapshotError{Offset: 0, Err: ErrSnapshotTruncated}
+	} else if err != nil {
+		return nil, &SnapshotError{Offset: 0, Err: err}
+	}
+
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, &SnapshotError{Offset: 0, Err: ErrSnapshotMagic}
+	}
+	version := header[len(snapshotMagic)]
+	if version != SnapshotVersion {
+		return nil, &SnapshotError{Offset: int64(len(snapshotMagic)), Err: ErrSnapshotVersion}
+	}
+
+	sr := &SnapshotReader{frames: newFrameReader(r, int64(len(header)))}
+
+	offset := sr.frames.offset
+	payload, err := sr.frames.next()
+	if err != nil {
+		return nil, sr.error(offset, err)
+	}
+
+	d := &payloadDecoder{buf: payload}
+
+	kind, err := d.byte()
+	if err != nil || kind != snapshotHeader {
+		return nil, sr.error(offset, errFrameCorrupt)
+	}
+
+	bias, err := d.byte()
+	if err != nil || (Bias(bias) != Adds && Bias(bias) != Removal) {
+		return nil, sr.error(offset, errFrameCorrupt)
+	}
+
+	edgeBias, err := d.byte()
+	if err != nil || (Bias(edgeBias) != Adds && Bias(edgeBias) != Removal) {
+		return nil, sr.error(offset, errFrameCorrupt)
+	}
+
+	replica, err := d.string()
+	if err != nil {
+		return nil, sr.error(offset, err)
+	}
+	if err := d.done(); err != nil {
+		return nil, sr.error(offset, err)
+	}
+
+	sr.bias = Bias(bias)
+	sr.edgeBias = Bias(edgeBias)
+	sr.replica = ReplicaID(replica)
+
+	return sr, nil
+}
+
+func (sr *SnapshotReader) Bias() Bias {
+	return sr.bias
+}
+
+// EdgeBias return the bias of the edges
+func (sr *SnapshotReader) EdgeBias() Bias {
+	return sr.edgeBias
+}
+
+func (sr *SnapshotReader) Replica() ReplicaID {
+	return sr.replica
+}
+
+// Next return the next record of the snapshot, io.EOF is returned after the end of the
+// snapshot, and a SnapshotError is returned when the snapshot is truncated or corrupt
+func (sr *SnapshotReader) Next() (SnapshotRecord, error) {
+
+	if sr.done {
+		return SnapshotRecord{}, io.EOF
+	}
+
+	offset := sr.frames.offset
+	payload, err := sr.frames.next()
+	if err != nil {
+		return SnapshotRecord{}, sr.error(offset, err)
+	}
+
+	record, err := sr.decode(payload)
+	if err != nil {
+		return SnapshotRecord{}, sr.error(offset, err)
+	}
+
+	if sr.done {
+		return SnapshotRecord{}, io.EOF
+	}
+
+	return record, nil
+}
+
+func (sr *SnapshotReader) decode(payload []byte) (SnapshotRecord, error) {
+
+	var record SnapshotRecord
+
+	d := &payloadDecoder{buf: payload}
+
+	kind, err := d.byte()
+	if err != nil {
+		return record, err
+	}
+
+	switch kind {
+	case snapshotVertex, snapshotTombstoneVertex:
+		record.Tombstone = kind == snapshotTombstoneVertex
+		if record.Vertex, err = sr.decodeVertex(d); err != nil {
+			return record, err
+		}
+	case snapshotEdge, snapshotTombstoneEdge:
+		record.Tombstone = kind == snapshotTombstoneEdge
+		if record.Row, record.Column, record.Edge, err = sr.decodeEdge(d); err != nil {
+			return record, err
+		}
+	case snapshotProperty:
+		if record.Owner, record.Key, record.Property, err = sr.decodeProperty(d); err != nil {
+			return record, err
+		}
+	case snapshotVersionVector:
+		if record.Owner, record.Version, err = sr.decodeVersion(d); err != nil {
+			return record, err
+		}
+	case snapshotEnd:
+		count, err := d.uvarint()
+		if err != nil {
+			return record, err
+		}
+		if count != sr.count {
+			return record, errFrameCorrupt
+		}
+		sr.done = true
+	default:
+		return record, errFrameCorrupt
+	}
+
+	sr.count++
+
+	return record, d.done()
+}
+
+func (sr *SnapshotReader) decodeVertex(d *payloadDecoder) (LWWVertex, error) {
+
+	value, err := sr.decodeString(d)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := sr.decodeTimestamp(d)
+	if err != nil {
+		return nil, err
+	}
+
+	replica, err := sr.decodeString(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LWWVertexImpl{
+		value:     VertexValue(value),
+		timestamp: timestamp,
+		replica:   ReplicaID(replica),
+	}, nil
+}
+
+func (sr *SnapshotReader) decodeEdge(d *payloadDecoder) (VertexValue, VertexValue, LWWEdge, error) {
+
+	m, err := sr.decodeString(d)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	n, err := sr.decodeString(d)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	timestamp, err := sr.decodeTimestamp(d)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	replica, err := sr.decodeString(d)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	size, err := d.uvarint()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if size != 2 {
+		return "", "", nil, errFrameCorrupt
+	}
+
+	vertices := []LWWVertex{}
+	for i := uint64(0); i < size; i++ {
+		v, err := sr.decodeVertex(d)
+		if err != nil {
+			return "", "", nil, err
+		}
+		vertices = append(vertices, v)
+	}
+
+	weight, err := d.weight()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return VertexValue(m), VertexValue(n), &LWWEdgeImpl{
+		vertices:  &vertices,
+		timestamp: timestamp,
+		replica:   ReplicaID(replica),
+		weight:    weight,
+	}, nil
+}
+
+func (sr *SnapshotReader) decodeOwner(d *payloadDecoder) (PropertyOwner, error) {
+
+	flag, err := d.byte()
+	if err != nil {
+		return PropertyOwner{}, err
+	}
+	if flag > 1 {
+		return PropertyOwner{}, errFrameCorrupt
+	}
+
+	v1, err := sr.decodeString(d)
+	if err != nil {
+		return PropertyOwner{}, err
+	}
+	if flag == 0 {
+		return VertexOwner(VertexValue(v1)), nil
+	}
+
+	v2, err := sr.decodeString(d)
+	if err != nil {
+		return PropertyOwner{}, err
+	}
+	if v1 >= v2 {
+		return PropertyOwner{}, errFrameCorrupt
+	}
+
+	return EdgeOwner(VertexValue(v1), VertexValue(v2)), nil
+}
+
+func (sr *SnapshotReader) decodeProperty(d *payloadDecoder) (PropertyOwner, string, LWWProperty, error) {
+
+	owner, err := sr.decodeOwner(d)
+	if err != nil {
+		return owner, "", nil, err
+	}
+
+	key, err := sr.decodeString(d)
+	if err != nil {
+		return owner, "", nil, err
+	}
+
+	value, err := sr.decodeString(d)
+	if err != nil {
+		return owner, "", nil, err
+	}
+
+	deleted, err := d.byte()
+	if err != nil {
+		return owner, "", nil, err
+	}
+	if deleted > 1 {
+		return owner, "", nil, errFrameCorrupt
+	}
+
+	timestamp, err := sr.decodeTimestamp(d)
+	if err != nil {
+		return owner, "", nil, err
+	}
+
+	replica, err := sr.decodeString(d)
+	if err != nil {
+		return owner, "", nil, err
+	}
+
+	return owner, key, &LWWPropertyImpl{
+		value:     value,
+		deleted:   deleted == 1,
+		timestamp: timestamp,
+		replica:   ReplicaID(replica),
+	}, nil
+}
+
+func (sr *SnapshotReader) decodeVersion(d *payloadDecoder) (PropertyOwner, VersionVector, error) {
+
+	component, err := sr.decodeOwner(d)
+	if err != nil {
+		return component, nil, err
+	}
+
+	size, err := d.uvarint()
+	if err != nil {
+		return component, nil, err
+	}
+
+	version := VersionVector{}
+
+	var last string
+	for i := uint64(0); i < size; i++ {
+		replica, err := sr.decodeString(d)
+		if err != nil {
+			return component, nil, err
+		}
+		if i > 0 && replica <= last {
+			return component, nil, errFrameCorrupt
+		}
+		last = replica
+		n, err := d.uvarint()
+		if err != nil {
+			return component, nil, err
+		}
+		version[ReplicaID(replica)] = n
+	}
+
+	return component, version, nil
+}
+
+func (sr *SnapshotReader) decodeString(d *payloadDecoder) (string, error) {
+
+	index, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+
+	if index == 0 {
+		s, err := d.string()
+		if err != nil {
+			return "", err
+		}
+		sr.strings = append(sr.strings, s)
+		return s, nil
+	}
+
+	if index > uint64(len(sr.strings)) {
+		return "", errFrameCorrupt
+	}
+
+	return sr.strings[index-1], nil
+}
+
+func (sr *SnapshotReader) decodeTimestamp(d *payloadDecoder) (int64, error) {
+
+	delta, err := d.varint()
+	if err != nil {
+		return 0, err
+	}
+
+	sr.last += delta
+
+	return sr.last, nil
+}
+
+// error turn the errors of the frames into the snapshot errors
+func (sr *SnapshotReader) error(offset int64, err error) error {
+	switch err {
+	case io.EOF, errFrameTruncated:
+		err = ErrSnapshotTruncated
+	case errFrameCorrupt:
+		err = ErrSnapshotCorrupt
+	}
+	return &SnapshotError{Offset: offset, Err: err}
+}
+
+// WriteSnapshot write the whole state of the graph as a snapshot, the records are written
+// one by one, so the snapshot is not buffered as a whole
+func WriteSnapshot(w io.Writer, graph LWWGraph) error {
+
+	sw, err := newSnapshotWriter(w, graph.GetBias(), graph.GetEdgeBias(), graph.GetReplica())
+	if err != nil {
+		return err
+	}
+
+	for i, vertices := range []map[VertexValue]LWWVertex{graph.GetVertices(), graph.GetTombstoneVertices()} {
+		for _, v := range vertices {
+			if v == nil {
+				continue
+			}
+			if err := sw.WriteVertex(v, i == 1); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, matrix := range []map[VertexValue]map[VertexValue]LWWEdge{graph.GetEdgesMatrix(), graph.GetTombstoneEdgesMatrix()} {
+		for m := range matrix {
+			for n, e := range matrix[m] {
+				if e == nil {
+					continue
+				}
+				if err := sw.WriteEdge(m, n, e, i == 1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	properties := graph.GetProperties()
+	for owner := range properties {
+		for key, p := range properties[owner] {
+			if p == nil {
+				continue
+			}
+			if err := sw.WriteProperty(owner, key, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	for component, vv := range graph.GetVersions() {
+		if vv == nil {
+			continue
+		}
+		if err := sw.WriteVersion(component, vv); err != nil {
+			return err
+		}
+	}
+
+	return sw.Close()
+}
+
+// ReadSnapshot read the snapshot as a graph with the clock, the physical clock is used
+// when the clock is nil
+func ReadSnapshot(r io.Reader, clock Clock) (LWWGraph, error) {
+
+	sr, err := NewSnapshotReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := NewLWWGraph(sr.Bias(), clock, sr.Replica(), WithEdgeBias(sr.EdgeBias())).(*LWWGraphImpl)
+
+	if err := readSnapshotRecords(sr, graph); err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+// readSnapshotRecords set the records of the snapshot to the graph and build the index of them
+func readSnapshotRecords(sr *SnapshotReader, graph *LWWGraphImpl) error {
+
+	for {
+		record, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case record.Version != nil:
+			graph.versions.Set(record.Owner, record.Version)
+		case record.Property != nil:
+			graph.properties.Set(record.Owner, record.Key, record.Property)
+		case record.Vertex != nil && record.Tombstone:
+			graph.tombstoneVertices.Set(record.Vertex)
+		case record.Vertex != nil:
+			graph.vertices.Set(record.Vertex)
+		case record.Tombstone:
+			graph.tombstoneEdgesMatrix.Set(record.Row, record.Column, record.Edge)
+		default:
+			graph.edgesMatrix.Set(record.Row, record.Column, record.Edge)
+		}
+	}
+
+	graph.buildIndex()
+
+	return nil
+}
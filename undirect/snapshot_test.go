@@ -0,0 +1,292 @@
+package undirect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// newSnapshotGraphs return the graphs of the random operations for the snapshot tests, the
+// graphs write the version vectors
+func newSnapshotGraphs(seeds int64) []LWWGraph {
+
+	values := newRandomValues(6)
+	graphs := []LWWGraph{}
+
+	for _, bias := range []Bias{Adds, Removal} {
+		for seed := int64(1); seed <= seeds; seed++ {
+			r := rand.New(rand.NewSource(seed))
+			replicas := []LWWGraph{}
+			for _, replica := range []ReplicaID{"x", "y"} {
+				graph := NewLWWGraph(bias, &testCkock{}, replica, WithVersionVectors(nil))
+				graph.GetClock().Now()
+				replicas = append(replicas, graph)
+			}
+			for step := 0; step < 100; step++ {
+				applyRandomOperation(r, replicas, values)
+			}
+			graphs = append(graphs, replicas[0])
+		}
+	}
+
+	return graphs
+}
+
+func TestWriteSnapshot(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+
+	tests := []struct {
+		name       string
+		bias       Bias
+		operations []mockOperation
+	}{
+		{
+			name: "empty graph",
+			bias: Adds,
+		},
+		{
+			name: "vertices and edges",
+			bias: Adds,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+				{C, mockGraphAddAction, 2 * time.Minute, nil},
+			},
+		},
+		{
+			name: "tombstones with removal bias",
+			bias: Removal,
+			operations: []mockOperation{
+				{A, mockGraphAddAction, 1 * time.Minute, []VertexValue{B, C}},
+				{A, mockGraphRemoveAction, 2 * time.Minute, []VertexValue{B}},
+				{C, mockGraphRemoveAction, 2 * time.Minute, nil},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			graph := NewLWWGraph(tt.bias, &testCkock{}, "x")
+			applyMockOperations(graph, tt.operations)
+
+			var buf bytes.Buffer
+			if err := WriteSnapshot(&buf, graph); err != nil {
+				t.Fatalf("WriteSnapshot() error = %v", err)
+			}
+
+			decoded, err := ReadSnapshot(&buf, &testCkock{})
+			if err != nil {
+				t.Fatalf("ReadSnapshot() error = %v", err)
+			}
+
+			if got, want := stateOf(decoded), stateOf(graph); !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadSnapshot() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+			}
+		})
+	}
+}
+
+// Check the graphs read are the same as the graphs written, and the snapshot is smaller than the JSON
+func TestWriteSnapshot_Round_Trip(t *testing.T) {
+
+	for i, graph := range newSnapshotGraphs(20) {
+
+		var buf bytes.Buffer
+		if err := WriteSnapshot(&buf, graph); err != nil {
+			t.Fatalf("WriteSnapshot() error = %v", err)
+		}
+
+		data, err := json.Marshal(graph)
+		if err != nil {
+			t.Fatalf("LWWGraphImpl.MarshalJSON() error = %v", err)
+		}
+		if buf.Len() >= len(data) {
+			t.Errorf("graph %v: WriteSnapshot() size = %v, want less than the JSON size %v", i, buf.Len(), len(data))
+		}
+
+		decoded, err := ReadSnapshot(&buf, &testCkock{})
+		if err != nil {
+			t.Fatalf("ReadSnapshot() error = %v", err)
+		}
+
+		if got, want := stateOf(decoded), stateOf(graph); !reflect.DeepEqual(got, want) {
+			t.Errorf("graph %v: ReadSnapshot() = %v, want %v, diff: %v", i, got, want, deep.Equal(got, want))
+		}
+	}
+}
+
+// Check the weights are kept by the encodings of the graph
+func TestLWWGraphImpl_Weight_Round_Trip(t *testing.T) {
+
+	graph, _ := NewWeightedMockGraph(weightedMockFields{bias: Adds, edges: []weightedEdge{
+		{"A", "B", 2.5},
+		{"B", "C", -1},
+		{"C", "D", 0},
+	}}, "x")
+	want := stateOf(graph)
+
+	data, err := json.Marshal(graph)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	decoded := NewLWWGraph(Adds, nil, "")
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got := stateOf(decoded); !reflect.DeepEqual(got, want) {
+		t.Errorf("json.Unmarshal() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, graph); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	read, err := ReadSnapshot(&buf, nil)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	if got := stateOf(read); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadSnapshot() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+}
+
+func TestReadSnapshot_Error(t *testing.T) {
+
+	graph := newSnapshotGraphs(1)[0]
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, graph); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	version := append([]byte{}, data...)
+	version[len(snapshotMagic)] = SnapshotVersion + 1
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "empty", data: []byte{}, want: ErrSnapshotTruncated},
+		{name: "not a snapshot", data: []byte(`{"bias":0}`), want: ErrSnapshotMagic},
+		{name: "unsupported version", data: version, want: ErrSnapshotVersion},
+		{name: "without end", data: data[:len(data)-7], want: ErrSnapshotTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSnapshot(bytes.NewReader(tt.data), &testCkock{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ReadSnapshot() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// the snapshot cut at any offset is truncated
+	for i := 0; i < len(data); i++ {
+		if _, err := ReadSnapshot(bytes.NewReader(data[:i]), &testCkock{}); !errors.Is(err, ErrSnapshotTruncated) {
+			t.Fatalf("ReadSnapshot() of %v bytes error = %v, want %v", i, err, ErrSnapshotTruncated)
+		}
+	}
+
+	// the snapshot with any byte changed is not read as a graph
+	for i := 0; i < len(data); i++ {
+		corrupt := append([]byte{}, data...)
+		corrupt[i] ^= 0xff
+		var snapshotErr *SnapshotError
+		if _, err := ReadSnapshot(bytes.NewReader(corrupt), &testCkock{}); !errors.As(err, &snapshotErr) {
+			t.Fatalf("ReadSnapshot() with byte %v changed error = %v, want SnapshotError", i, err)
+		}
+	}
+}
+
+func TestSnapshotReader_Next(t *testing.T) {
+
+	graph := newSnapshotGraphs(1)[0]
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, graph); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+
+	sr, err := NewSnapshotReader(&buf)
+	if err != nil {
+		t.Fatalf("NewSnapshotReader() error = %v", err)
+	}
+
+	if sr.Bias() != graph.GetBias() || sr.Replica() != graph.GetReplica() {
+		t.Errorf("NewSnapshotReader() header = %v, %v, want %v, %v", sr.Bias(), sr.Replica(), graph.GetBias(), graph.GetReplica())
+	}
+
+	var got int
+	for {
+		_, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("SnapshotReader.Next() error = %v", err)
+		}
+		got++
+	}
+
+	vertices, tombstoneVertices, edges, tombstoneEdges := countRecords(graph)
+	properties := 0
+	for _, p := range graph.GetProperties() {
+		properties += len(p)
+	}
+	if want := vertices + tombstoneVertices + edges + tombstoneEdges + properties + len(graph.GetVersions()); got != want {
+		t.Errorf("SnapshotReader.Next() records = %v, want %v", got, want)
+	}
+
+	if _, err := sr.Next(); err != io.EOF {
+		t.Errorf("SnapshotReader.Next() after the end error = %v, want %v", err, io.EOF)
+	}
+}
+
+func FuzzReadSnapshot(f *testing.F) {
+
+	for _, graph := range newSnapshotGraphs(3) {
+		var buf bytes.Buffer
+		if err := WriteSnapshot(&buf, graph); err != nil {
+			f.Fatalf("WriteSnapshot() error = %v", err)
+		}
+		f.Add(buf.Bytes())
+	}
+	f.Add([]byte{})
+	f.Add([]byte(snapshotMagic))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		graph, err := ReadSnapshot(bytes.NewReader(data), &testCkock{})
+		if err != nil {
+			var snapshotErr *SnapshotError
+			if !errors.As(err, &snapshotErr) {
+				t.Fatalf("ReadSnapshot() error = %v, want SnapshotError", err)
+			}
+			return
+		}
+
+		// the graph read can be written and read again as the same state
+		var buf bytes.Buffer
+		if err := WriteSnapshot(&buf, graph); err != nil {
+			t.Fatalf("WriteSnapshot() error = %v", err)
+		}
+		again, err := ReadSnapshot(&buf, &testCkock{})
+		if err != nil {
+			t.Fatalf("ReadSnapshot() of the graph written again error = %v", err)
+		}
+		if got, want := stateOf(again), stateOf(graph); !reflect.DeepEqual(got, want) {
+			t.Fatalf("ReadSnapshot() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+		}
+	})
+}
@@ -0,0 +1,410 @@
+package undirect
+
+// StorageOf keeps the records of the graph, which are the add and the remove sets of the
+// vertices, the matrices of the edges and the tombstone edges, the registers of the
+// properties and the version vectors. The graph keeps the maps in memory by default, and the storage can be
+// replaced by WithStorage.
+//
+// The records returned by the stores can be updated in place by the graph, and the graph
+// always sets the record again after it is updated, so the stores that keep the copies
+// of the records have the updates as well.
+type StorageOf[ID comparable] interface {
+	Vertices() VertexStoreOf[ID]
+	TombstoneVertices() VertexStoreOf[ID]
+	Edges() EdgeStoreOf[ID]
+	TombstoneEdges() EdgeStoreOf[ID]
+	Properties() PropertyStoreOf[ID]
+	Versions() VersionStoreOf[ID]
+}
+
+// Storage is the storage of the graph of the string values
+type Storage = StorageOf[VertexValue]
+
+type VertexStore = VertexStoreOf[VertexValue]
+
+type EdgeStore = EdgeStoreOf[VertexValue]
+
+type PropertyStore = PropertyStoreOf[VertexValue]
+
+type VersionStore = VersionStoreOf[VertexValue]
+
+// VertexStoreOf is the set of the records of the vertices, keyed by the value of the vertex
+type VertexStoreOf[ID comparable] interface {
+	Get(value ID) (LWWVertexOf[ID], bool)
+	Set(vertex LWWVertexOf[ID])
+	Delete(value ID)
+	// Range call f for every record until f return false, the records can be deleted by f
+	Range(f func(vertex LWWVertexOf[ID]) bool)
+	Len() int
+}
+
+// EdgeStoreOf is the sparse matrix of the records of the edges, the row without cells is not kept
+type EdgeStoreOf[ID comparable] interface {
+	Get(m, n ID) (LWWEdgeOf[ID], bool)
+	Set(m, n ID, edge LWWEdgeOf[ID])
+	Delete(m, n ID)
+	// RangeRow call f for every cell of the row until f return false, the cells can be deleted by f
+	RangeRow(m ID, f func(n ID, edge LWWEdgeOf[ID]) bool)
+	// Range call f for every cell until f return false, the cells can be deleted by f
+	Range(f func(m, n ID, edge LWWEdgeOf[ID]) bool)
+	RowLen(m ID) int
+}
+
+// PropertyStoreOf is the registers of the properties, keyed by the owner then the key of the
+// property, the owner without registers is not kept
+type PropertyStoreOf[ID comparable] interface {
+	Get(owner PropertyOwnerOf[ID], key string) (LWWProperty, bool)
+	Set(owner PropertyOwnerOf[ID], key string, property LWWProperty)
+	Delete(owner PropertyOwnerOf[ID], key string)
+	// RangeOwner call f for every register of the owner until f return false, the registers can be deleted by f
+	RangeOwner(owner PropertyOwnerOf[ID], f func(key string, property LWWProperty) bool)
+	// Range call f for every register until f return false, the registers can be deleted by f
+	Range(f func(owner PropertyOwnerOf[ID], key string, property LWWProperty) bool)
+}
+
+// VersionStoreOf is the version vectors of the vertices and the edges, keyed by the component
+// in the same way as the owner of the properties
+type VersionStoreOf[ID comparable] interface {
+	Get(component PropertyOwnerOf[ID]) (VersionVector, bool)
+	Set(component PropertyOwnerOf[ID], version VersionVector)
+	Delete(component PropertyOwnerOf[ID])
+	// Range call f for every version vector until f return false, the version vectors can be deleted by f
+	Range(f func(component PropertyOwnerOf[ID], version VersionVector) bool)
+}
+
+// OptionOf is the option of the graph
+type OptionOf[ID comparable] func(graph *LWWGraphImplOf[ID])
+
+// Option is the option of the graph of the string values
+type Option = OptionOf[VertexValue]
+
+// WithStorageOf set the storage of the records of the graph, the graph starts with the
+// records that are already in the storage
+func WithStorageOf[ID comparable](storage StorageOf[ID]) OptionOf[ID] {
+	return func(graph *LWWGraphImplOf[ID]) {
+		graph.vertices = storage.Vertices()
+		graph.tombstoneVertices = storage.TombstoneVertices()
+		graph.edgesMatrix = storage.Edges()
+		graph.tombstoneEdgesMatrix = storage.TombstoneEdges()
+		graph.properties = storage.Properties()
+		graph.versions = storage.Versions()
+	}
+}
+
+// WithStorage set the storage of the records of the graph of the string values
+func WithStorage(storage Storage) Option {
+	return WithStorageOf(storage)
+}
+
+// mapStorage is the default storage of the graph that keeps the records in the maps
+type mapStorage[ID comparable] struct {
+	vertices             mapVertexStore[ID]
+	tombstoneVertices    mapVertexStore[ID]
+	edgesMatrix          mapEdgeStore[ID]
+	tombstoneEdgesMatrix mapEdgeStore[ID]
+	properties           mapPropertyStore[ID]
+	versions             mapVersionStore[ID]
+}
+
+// NewMapStorageOf return the storage that keeps the records in memory
+func NewMapStorageOf[ID comparable]() StorageOf[ID] {
+	return &mapStorage[ID]{
+		vertices:             make(mapVertexStore[ID]),
+		tombstoneVertices:    make(mapVertexStore[ID]),
+		edgesMatrix:          make(mapEdgeStore[ID]),
+		tombstoneEdgesMatrix: make(mapEdgeStore[ID]),
+		properties:           make(mapPropertyStore[ID]),
+		versions:             make(mapVersionStore[ID]),
+	}
+}
+
+// NewMapStorage return the storage of the graph of the string values that keeps the
+// records in memory
+func NewMapStorage() Storage {
+	return NewMapStorageOf[VertexValue]()
+}
+
+func (storage *mapStorage[ID]) Vertices() VertexStoreOf[ID] {
+	return storage.vertices
+}
+
+func (storage *mapStorage[ID]) TombstoneVertices() VertexStoreOf[ID] {
+	return storage.tombstoneVertices
+}
+
+func (storage *mapStorage[ID]) Edges() EdgeStoreOf[ID] {
+	return storage.edgesMatrix
+}
+
+func (storage *mapStorage[ID]) TombstoneEdges() EdgeStoreOf[ID] {
+	return storage.tombstoneEdgesMatrix
+}
+
+func (storage *mapStorage[ID]) Properties() PropertyStoreOf[ID] {
+	return storage.properties
+}
+
+func (storage *mapStorage[ID]) Versions() VersionStoreOf[ID] {
+	return storage.versions
+}
+
+type mapVertexStore[ID comparable] map[ID]LWWVertexOf[ID]
+
+func (store mapVertexStore[ID]) Get(value ID) (LWWVertexOf[ID], bool) {
+	v, ok := store[value]
+	return v, ok && v != nil
+}
+
+func (store mapVertexStore[ID]) Set(vertex LWWVertexOf[ID]) {
+	store[vertex.GetValue()] = vertex
+}
+
+func (store mapVertexStore[ID]) Delete(value ID) {
+	delete(store, value)
+}
+
+func (store mapVertexStore[ID]) Range(f func(vertex LWWVertexOf[ID]) bool) {
+	for _, v := range store {
+		if v == nil {
+			continue
+		}
+		if !f(v) {
+			return
+		}
+	}
+}
+
+func (store mapVertexStore[ID]) Len() int {
+	return len(store)
+}
+
+type mapEdgeStore[ID comparable] map[ID]map[ID]LWWEdgeOf[ID]
+
+func (store mapEdgeStore[ID]) Get(m, n ID) (LWWEdgeOf[ID], bool) {
+	e, ok := store[m][n]
+	return e, ok && e != nil
+}
+
+func (store mapEdgeStore[ID]) Set(m, n ID, edge LWWEdgeOf[ID]) {
+	setEdge(store, m, n, edge)
+}
+
+func (store mapEdgeStore[ID]) Delete(m, n ID) {
+	delete(store[m], n)
+	if len(store[m]) == 0 {
+		delete(store, m)
+	}
+}
+
+func (store mapEdgeStore[ID]) RangeRow(m ID, f func(n ID, edge LWWEdgeOf[ID]) bool) {
+	for n, e := range store[m] {
+		if e == nil {
+			continue
+		}
+		if !f(n, e) {
+			return
+		}
+	}
+}
+
+func (store mapEdgeStore[ID]) Range(f func(m, n ID, edge LWWEdgeOf[ID]) bool) {
+	for m := range store {
+		for n, e := range store[m] {
+			if e == nil {
+				continue
+			}
+			if !f(m, n, e) {
+				return
+			}
+		}
+	}
+}
+
+func (store mapEdgeStore[ID]) RowLen(m ID) int {
+	return len(store[m])
+}
+
+type mapPropertyStore[ID comparable] map[PropertyOwnerOf[ID]]map[string]LWWProperty
+
+func (store mapPropertyStore[ID]) Get(owner PropertyOwnerOf[ID], key string) (LWWProperty, bool) {
+	p, ok := store[owner][key]
+	return p, ok && p != nil
+}
+
+func (store mapPropertyStore[ID]) Set(owner PropertyOwnerOf[ID], key string, property LWWProperty) {
+	if _, ok := store[owner]; !ok {
+		store[owner] = make(map[string]LWWProperty)
+	}
+	store[owner][key] = property
+}
+
+func (store mapPropertyStore[ID]) Delete(owner PropertyOwnerOf[ID], key string) {
+	delete(store[owner], key)
+	if len(store[owner]) == 0 {
+		delete(store, owner)
+	}
+}
+
+func (store mapPropertyStore[ID]) RangeOwner(owner PropertyOwnerOf[ID], f func(key string, property LWWProperty) bool) {
+	for key, p := range store[owner] {
+		if p == nil {
+			continue
+		}
+		if !f(key, p) {
+			return
+		}
+	}
+}
+
+func (store mapPropertyStore[ID]) Range(f func(owner PropertyOwnerOf[ID], key string, property LWWProperty) bool) {
+	for owner := range store {
+		for key, p := range store[owner] {
+			if p == nil {
+				continue
+			}
+			if !f(owner, key, p) {
+				return
+			}
+		}
+	}
+}
+
+type mapVersionStore[ID comparable] map[PropertyOwnerOf[ID]]VersionVector
+
+func (store mapVersionStore[ID]) Get(component PropertyOwnerOf[ID]) (VersionVector, bool) {
+	vv, ok := store[component]
+	return vv, ok && vv != nil
+}
+
+func (store mapVersionStore[ID]) Set(component PropertyOwnerOf[ID], version VersionVector) {
+	store[component] = version
+}
+
+func (store mapVersionStore[ID]) Delete(component PropertyOwnerOf[ID]) {
+	delete(store, component)
+}
+
+func (store mapVersionStore[ID]) Range(f func(component PropertyOwnerOf[ID], version VersionVector) bool) {
+	for component, vv := range store {
+		if vv == nil {
+			continue
+		}
+		if !f(component, vv) {
+			return
+		}
+	}
+}
+
+// replaceRecords replace the records of the stores of the graph with the records of the maps
+func (graph *LWWGraphImplOf[ID]) replaceRecords(vertices, tombstoneVertices map[ID]LWWVertexOf[ID], edgesMatrix, tombstoneEdgesMatrix map[ID]map[ID]LWWEdgeOf[ID], properties map[PropertyOwnerOf[ID]]map[string]LWWProperty, versions map[PropertyOwnerOf[ID]]VersionVector) {
+
+	for _, pair := range []struct {
+		store   VertexStoreOf[ID]
+		records map[ID]LWWVertexOf[ID]
+	}{{graph.vertices, vertices}, {graph.tombstoneVertices, tombstoneVertices}} {
+		pair.store.Range(func(v LWWVertexOf[ID]) bool {
+			pair.store.Delete(v.GetValue())
+			return true
+		})
+		for _, v := range pair.records {
+			if v != nil {
+				pair.store.Set(v)
+			}
+		}
+	}
+
+	for _, pair := range []struct {
+		store   EdgeStoreOf[ID]
+		records map[ID]map[ID]LWWEdgeOf[ID]
+	}{{graph.edgesMatrix, edgesMatrix}, {graph.tombstoneEdgesMatrix, tombstoneEdgesMatrix}} {
+		pair.store.Range(func(m, n ID, _ LWWEdgeOf[ID]) bool {
+			pair.store.Delete(m, n)
+			return true
+		})
+		for m := range pair.records {
+			for n, e := range pair.records[m] {
+				if e != nil {
+					pair.store.Set(m, n, e)
+				}
+			}
+		}
+	}
+
+	graph.properties.Range(func(owner PropertyOwnerOf[ID], key string, _ LWWProperty) bool {
+		graph.properties.Delete(owner, key)
+		return true
+	})
+	for owner := range properties {
+		for key, p := range properties[owner] {
+			if p != nil {
+				graph.properties.Set(owner, key, p)
+			}
+		}
+	}
+
+	graph.versions.Range(func(component PropertyOwnerOf[ID], _ VersionVector) bool {
+		graph.versions.Delete(component)
+		return true
+	})
+	for component, vv := range versions {
+		if vv != nil {
+			graph.versions.Set(component, vv)
+		}
+	}
+
+	graph.buildIndex()
+}
+
+// vertexMap return the records of the store as a map, the map of the default
+// storage is returned as it is, so it is not copied for every call
+func vertexMap[ID comparable](store VertexStoreOf[ID]) map[ID]LWWVertexOf[ID] {
+
+	if m, ok := store.(mapVertexStore[ID]); ok {
+		return m
+	}
+
+	vertices := make(map[ID]LWWVertexOf[ID], store.Len())
+	store.Range(func(v LWWVertexOf[ID]) bool {
+		vertices[v.GetValue()] = v
+		return true
+	})
+
+	return vertices
+}
+
+// edgeMatrix return the records of the store as a matrix, the matrix of the
+// default storage is returned as it is, so it is not copied for every call
+func edgeMatrix[ID comparable](store EdgeStoreOf[ID]) map[ID]map[ID]LWWEdgeOf[ID] {
+
+	if m, ok := store.(mapEdgeStore[ID]); ok {
+		return m
+	}
+
+	matrix := make(map[ID]map[ID]LWWEdgeOf[ID])
+	store.Range(func(m, n ID, e LWWEdgeOf[ID]) bool {
+		setEdge(matrix, m, n, e)
+		return true
+	})
+
+	return matrix
+}
+
+// propertyMap return the registers of the store as a map, the map of the default
+// storage is returned as it is, so it is not copied for every call
+func propertyMap[ID comparable](store PropertyStoreOf[ID]) map[PropertyOwnerOf[ID]]map[string]LWWProperty {
+
+	if m, ok := store.(mapPropertyStore[ID]); ok {
+		return m
+	}
+
+	properties := make(map[PropertyOwnerOf[ID]]map[string]LWWProperty)
+	store.Range(func(owner PropertyOwnerOf[ID], key string, p LWWProperty) bool {
+		if _, ok := properties[owner]; !ok {
+			properties[owner] = make(map[string]LWWProperty)
+		}
+		properties[owner][key] = p
+		return true
+	})
+
+	return properties
+}
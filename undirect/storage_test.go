@@ -0,0 +1,241 @@
+package undirect
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// runWithStorages run the test against the map storage, then against the key-value storage,
+// newStorage return the storage of the backend for every graph of the test
+func runWithStorages(t *testing.T, test func(t *testing.T, newStorage func(t *testing.T) Storage)) {
+
+	t.Run("map", func(t *testing.T) {
+		test(t, func(t *testing.T) Storage { return NewMapStorage() })
+	})
+
+	t.Run("kv", func(t *testing.T) {
+		dir := t.TempDir()
+		test(t, func(t *testing.T) Storage {
+			storage := NewTempKVStorage(dir)
+			t.Cleanup(func() { storage.Close() })
+			return storage
+		})
+	})
+}
+
+func newKVTestGraph(t *testing.T, path string, clock Clock) (LWWGraph, *KVStorage) {
+	t.Helper()
+	storage, err := OpenKVStorage(path)
+	if err != nil {
+		t.Fatalf("OpenKVStorage() error = %v", err)
+	}
+	return NewLWWGraph(Adds, clock, "x", WithStorage(storage)), storage
+}
+
+// Check the graph reopened from the key-value file is the same as the graph written
+func TestOpenKVStorage_Reopen(t *testing.T) {
+
+	for _, compact := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compact %v", compact), func(t *testing.T) {
+
+			path := filepath.Join(t.TempDir(), "graph.kv")
+			clock := &testCkock{}
+			graph, storage := newKVTestGraph(t, path, clock)
+			other := NewLWWGraph(Adds, &testCkock{}, "y", WithStorage(NewMapStorage()), WithVersionVectors(nil))
+
+			for _, op := range newWALOperations() {
+				clock.AddDuration(time.Second)
+				op(graph, clock, other)
+			}
+			if err := storage.Err(); err != nil {
+				t.Fatalf("KVStorage.Err() = %v", err)
+			}
+
+			before, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("os.Stat() error = %v", err)
+			}
+			if compact {
+				if err := storage.Compact(); err != nil {
+					t.Fatalf("KVStorage.Compact() error = %v", err)
+				}
+				after, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("os.Stat() error = %v", err)
+				}
+				if after.Size() >= before.Size() {
+					t.Errorf("KVStorage.Compact() size = %v, want less than %v", after.Size(), before.Size())
+				}
+			}
+
+			want := frozenStateOf(graph)
+			if err := storage.Close(); err != nil {
+				t.Fatalf("KVStorage.Close() error = %v", err)
+			}
+
+			reopened, storage := newKVTestGraph(t, path, &testCkock{})
+			defer storage.Close()
+
+			if got := frozenStateOf(reopened); !reflect.DeepEqual(got, want) {
+				t.Errorf("OpenKVStorage() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+			}
+		})
+	}
+}
+
+// Check the frame written partly is truncated, and the records before it are kept
+func TestOpenKVStorage_Torn_Tail(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "graph.kv")
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	graph, storage := newKVTestGraph(t, path, nil)
+	graph.AddVertex(A)
+	storage.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+	size := info.Size()
+
+	graph, storage = newKVTestGraph(t, path, nil)
+	graph.AddVertex(B)
+	storage.Close()
+
+	if err := os.Truncate(path, size+3); err != nil {
+		t.Fatalf("os.Truncate() error = %v", err)
+	}
+
+	graph, storage = newKVTestGraph(t, path, nil)
+	defer storage.Close()
+
+	if !graph.IsVertexExist(A) || graph.IsVertexExist(B) {
+		t.Errorf("OpenKVStorage() vertices = %v, want only %v", graph.GetVertices(), A)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != size {
+		t.Errorf("OpenKVStorage() truncated the file to %v, want %v", info.Size(), size)
+	}
+}
+
+// Check the corrupt frame followed by the other frames is reported, and the file is not
+// truncated
+func TestOpenKVStorage_Corrupt_Middle(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "graph.kv")
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	graph, storage := newKVTestGraph(t, path, nil)
+	graph.AddVertex(A)
+	storage.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+	size := info.Size()
+
+	graph, storage = newKVTestGraph(t, path, nil)
+	graph.AddVertex(B)
+	storage.Close()
+
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+	want := info.Size()
+
+	// flip the last byte of the checksum of the frames written before B
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	data[size-1] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	if _, err := OpenKVStorage(path); err != ErrKVCorrupt {
+		t.Errorf("OpenKVStorage() error = %v, want %v", err, ErrKVCorrupt)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != want {
+		t.Errorf("OpenKVStorage() truncated the file to %v, want %v", info.Size(), want)
+	}
+}
+
+// Check the record that can not be read is not taken as a missing record
+func TestKVStorage_Read_Error(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "graph.kv")
+
+	graph, storage := newKVTestGraph(t, path, nil)
+	graph.AddVertex(NewVertexValue("A"))
+	storage.Close()
+
+	storage, err := OpenKVStorage(path)
+	if err != nil {
+		t.Fatalf("OpenKVStorage() error = %v", err)
+	}
+	defer storage.Close()
+
+	// the records are changed after the file is opened
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	for i := range data {
+		data[i] ^= 0xff
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	defer func() {
+		err, ok := recover().(*KVError)
+		if !ok || !errors.Is(err, ErrKVCorrupt) {
+			t.Errorf("NewLWWGraph() panic = %v, want %v", err, ErrKVCorrupt)
+		}
+		if got := storage.Err(); got != ErrKVCorrupt {
+			t.Errorf("KVStorage.Err() = %v, want %v", got, ErrKVCorrupt)
+		}
+	}()
+
+	NewLWWGraph(Adds, nil, "x", WithStorage(storage))
+}
+
+// Check the records evicted from the cache are read from the file
+func TestKVStorage_Cache_Eviction(t *testing.T) {
+
+	storage := NewTempKVStorage(t.TempDir())
+	defer storage.Close()
+
+	graph := NewLWWGraph(Adds, &testCkock{}, "x", WithStorage(storage))
+	want := NewLWWGraph(Adds, &testCkock{}, "x", WithStorage(NewMapStorage()))
+
+	for _, g := range []LWWGraph{graph, want} {
+		for i := 0; i < kvCacheSize*2; i++ {
+			v1 := NewVertexValue(fmt.Sprint(i))
+			v2 := NewVertexValue(fmt.Sprint(i + 1))
+			g.AddEdge(g.AddVertex(v1), g.AddVertex(v2))
+			if i%3 == 0 {
+				g.RemoveVertex(v1)
+			}
+		}
+	}
+
+	if got := storage.cacheOrder.Len(); got != kvCacheSize {
+		t.Errorf("KVStorage cache size = %v, want %v", got, kvCacheSize)
+	}
+	if got, want := stateOf(graph), stateOf(want); !reflect.DeepEqual(got, want) {
+		t.Errorf("KVStorage records = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+}
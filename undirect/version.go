@@ -0,0 +1,267 @@
+package undirect
+
+import "sort"
+
+// The version vector of the vertex or the edge is the number of the writes of every replica
+// to it that the replica has seen, so Merge can report the components written concurrently,
+// which the timestamps can not tell. Only the graph created with WithVersionVectors writes
+// them, the other graphs keep the ones merged, so they can still relay them.
+
+// VersionVector is the number of the writes of every replica, the version vectors are not
+// updated in place, a new version vector is set for every write
+type VersionVector map[ReplicaID]uint64
+
+// VersionOrder is the order of the version vectors
+type VersionOrder int
+
+const (
+	VersionEqual VersionOrder = iota
+	// VersionBefore is the version vector that is seen by the other
+	VersionBefore
+	// VersionAfter is the version vector that has seen the other
+	VersionAfter
+	// VersionConcurrent is the version vectors that have not seen each other
+	VersionConcurrent
+)
+
+// Compare return the order of the version vector to the other
+func (vv VersionVector) Compare(other VersionVector) VersionOrder {
+
+	var before, after bool
+
+	for replica, n := range vv {
+		if n > other[replica] {
+			after = true
+		}
+	}
+
+	for replica, n := range other {
+		if n > vv[replica] {
+			before = true
+		}
+	}
+
+	switch {
+	case before && after:
+		return VersionConcurrent
+	case before:
+		return VersionBefore
+	case after:
+		return VersionAfter
+	}
+
+	return VersionEqual
+}
+
+// Join return the new version vector of the greater number of every replica of both
+func (vv VersionVector) Join(other VersionVector) VersionVector {
+
+	joined := make(VersionVector, len(vv))
+
+	for replica, n := range vv {
+		joined[replica] = n
+	}
+
+	for replica, n := range other {
+		if n > joined[replica] {
+			joined[replica] = n
+		}
+	}
+
+	return joined
+}
+
+// ConflictOf is the vertex or the edge of which the version vector merged is concurrent
+// with the version vector of the replica
+type ConflictOf[ID comparable] struct {
+	// Component is the vertex or the edge, which is identified as the owner of the properties
+	Component PropertyOwnerOf[ID]
+	// Local and Remote are the version vectors of the replica and of the graph merged
+	Local, Remote VersionVector
+	// LocalRemoved and RemoteRemoved are true when the latest record of the component
+	// of the replica or of the graph merged is the tombstone
+	LocalRemoved, RemoteRemoved bool
+	// Exists is the result of the LWW resolution, which is the component exists after the merge
+	Exists bool
+}
+
+// Conflict is the conflict of the graph of the string values
+type Conflict = ConflictOf[VertexValue]
+
+// WithVersionVectorsOf make the graph write the version vectors of the vertices and the edges,
+// report is called by Merge with the conflicts sorted by the components when there are, and it
+// can be nil. The report is called while the graph is merging, so it should not use the graph
+func WithVersionVectorsOf[ID comparable](report func(conflicts []ConflictOf[ID])) OptionOf[ID] {
+	return func(graph *LWWGraphImplOf[ID]) {
+		graph.versioned = true
+		graph.report = report
+	}
+}
+
+// WithVersionVectors make the graph of the string values write the version vectors
+func WithVersionVectors(report func(conflicts []Conflict)) Option {
+	return WithVersionVectorsOf(report)
+}
+
+// writeVersion increase the number of the replica in the version vector of the component
+// written by the replica, when the graph writes the version vectors
+func (graph *LWWGraphImplOf[ID]) writeVersion(component PropertyOwnerOf[ID]) {
+
+	if !graph.versioned {
+		return
+	}
+
+	vv, _ := graph.versions.Get(component)
+	vv = vv.Join(nil)
+	vv[graph.replica]++
+
+	graph.versions.Set(component, vv)
+}
+
+// mergeVersions join the version vectors of the other graph into the graph, and return the
+// conflicts of the components of which the version vectors are concurrent when collect is
+// true, the records should not be merged yet, as the conflicts have the latest records of
+// the replica
+func (graph *LWWGraphImplOf[ID]) mergeVersions(versions map[PropertyOwnerOf[ID]]VersionVector, other mergedRecords[ID], collect bool) []ConflictOf[ID] {
+
+	conflicts := []ConflictOf[ID]{}
+
+	for component, remote := range versions {
+		if remote == nil {
+			continue
+		}
+
+		local, _ := graph.versions.Get(component)
+		order := local.Compare(remote)
+
+		if order == VersionConcurrent && collect {
+			conflicts = append(conflicts, ConflictOf[ID]{
+				Component:     component,
+				Local:         local,
+				Remote:        remote,
+				LocalRemoved:  graph.isRemoved(graph.localRecords(component)),
+				RemoteRemoved: graph.isRemoved(other.get(component)),
+			})
+		}
+
+		// the version vector of the replica has seen the other already
+		if order == VersionEqual || order == VersionAfter {
+			continue
+		}
+
+		graph.versions.Set(component, local.Join(remote))
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return graph.lessOwner(conflicts[i].Component, conflicts[j].Component)
+	})
+
+	return conflicts
+}
+
+// mergedRecords is the records of the graph merged
+type mergedRecords[ID comparable] struct {
+	vertices, tombstoneVertices map[ID]LWWVertexOf[ID]
+	edges, tombstoneEdges       map[ID]map[ID]LWWEdgeOf[ID]
+}
+
+func newMergedRecords[ID comparable](other LWWGraphOf[ID]) mergedRecords[ID] {
+	return mergedRecords[ID]{
+		vertices:          other.GetVertices(),
+		tombstoneVertices: other.GetTombstoneVertices(),
+		edges:             other.GetEdgesMatrix(),
+		tombstoneEdges:    other.GetTombstoneEdgesMatrix(),
+	}
+}
+
+// get return the add and the remove records of the component, which are nil when
+// there is no record
+func (records mergedRecords[ID]) get(component PropertyOwnerOf[ID]) (Component, Component) {
+
+	m, n := component.Vertices[0], component.Vertices[1]
+
+	var add, remove Component
+	if component.Edge {
+		if e := records.edges[m][n]; e != nil {
+			add = e
+		}
+		if e := records.tombstoneEdges[m][n]; e != nil {
+			remove = e
+		}
+	} else {
+		if v := records.vertices[m]; v != nil {
+			add = v
+		}
+		if v := records.tombstoneVertices[m]; v != nil {
+			remove = v
+		}
+	}
+
+	return add, remove
+}
+
+// localRecords return the add and the remove records of the component of the graph
+func (graph *LWWGraphImplOf[ID]) localRecords(component PropertyOwnerOf[ID]) (Component, Component) {
+
+	m, n := component.Vertices[0], component.Vertices[1]
+
+	var add, remove Component
+	if component.Edge {
+		if e, ok := graph.edgesMatrix.Get(m, n); ok {
+			add = e
+		}
+		if e, ok := graph.tombstoneEdgesMatrix.Get(m, n); ok {
+			remove = e
+		}
+	} else {
+		if v, ok := graph.vertices.Get(m); ok {
+			add = v
+		}
+		if v, ok := graph.tombstoneVertices.Get(m); ok {
+			remove = v
+		}
+	}
+
+	return add, remove
+}
+
+// isRemoved check the remove record is the latest record of the component
+func (graph *LWWGraphImplOf[ID]) isRemoved(add, remove Component) bool {
+	return remove != nil && (add == nil || !graph.IsComponentExist(add, remove))
+}
+
+func (graph *LWWGraphImplOf[ID]) GetVersions() map[PropertyOwnerOf[ID]]VersionVector {
+	return versionMap(graph.versions)
+}
+
+// versionMap return the version vectors of the store as a map, the map of the default
+// storage is returned as it is, so it is not copied for every call
+func versionMap[ID comparable](store VersionStoreOf[ID]) map[PropertyOwnerOf[ID]]VersionVector {
+
+	if m, ok := store.(mapVersionStore[ID]); ok {
+		return m
+	}
+
+	versions := make(map[PropertyOwnerOf[ID]]VersionVector)
+	store.Range(func(component PropertyOwnerOf[ID], vv VersionVector) bool {
+		versions[component] = vv
+		return true
+	})
+
+	return versions
+}
+
+// copyVersions copy the map of the version vectors only, as the version vectors are not
+// updated in place
+func copyVersions[ID comparable](versions map[PropertyOwnerOf[ID]]VersionVector) map[PropertyOwnerOf[ID]]VersionVector {
+
+	arr := make(map[PropertyOwnerOf[ID]]VersionVector, len(versions))
+
+	for component, vv := range versions {
+		if vv != nil {
+			arr[component] = vv
+		}
+	}
+
+	return arr
+}
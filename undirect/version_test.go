@@ -0,0 +1,294 @@
+package undirect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestVersionVector_Compare(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		vv    VersionVector
+		other VersionVector
+		want  VersionOrder
+	}{
+		{name: "test empty", vv: nil, other: VersionVector{}, want: VersionEqual},
+		{name: "test equal", vv: VersionVector{"x": 1, "y": 2}, other: VersionVector{"x": 1, "y": 2}, want: VersionEqual},
+		{name: "test before", vv: VersionVector{"x": 1}, other: VersionVector{"x": 1, "y": 1}, want: VersionBefore},
+		{name: "test after", vv: VersionVector{"x": 2, "y": 1}, other: VersionVector{"x": 1, "y": 1}, want: VersionAfter},
+		{name: "test concurrent", vv: VersionVector{"x": 2}, other: VersionVector{"x": 1, "y": 1}, want: VersionConcurrent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.vv.Compare(tt.other); got != tt.want {
+				t.Errorf("VersionVector.Compare() = %v, want %v", got, tt.want)
+			}
+			if got, want := tt.vv.Join(tt.other), tt.other.Join(tt.vv); !reflect.DeepEqual(got, want) {
+				t.Errorf("VersionVector.Join() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLWWGraphImpl_GetVersions(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	clock := &testCkock{}
+	x := NewLWWGraph(Adds, clock, "x", WithVersionVectors(nil))
+	x.AddEdge(NewLWWVertex(A, clock, "x"), NewLWWVertex(B, clock, "x"))
+
+	want := map[PropertyOwner]VersionVector{
+		VertexOwner(A):  {"x": 1},
+		VertexOwner(B):  {"x": 1},
+		EdgeOwner(A, B): {"x": 1},
+	}
+	if got := copyVersions(x.GetVersions()); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetVersions() = %v, want %v", got, want)
+	}
+
+	// the graph without the version vectors keeps the version vectors merged, but it
+	// does not write them
+	y := NewLWWGraph(Adds, &testCkock{}, "y")
+	y.Merge(x)
+	y.GetClock().(*testCkock).AddDuration(time.Minute)
+	y.RemoveVertex(A)
+	if got := copyVersions(y.GetVersions()); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetVersions() = %v, want %v", got, want)
+	}
+
+	// removing the vertex writes the edges connected as well
+	clock.AddDuration(time.Minute)
+	x.RemoveVertex(A)
+	want[VertexOwner(A)] = VersionVector{"x": 2}
+	want[EdgeOwner(A, B)] = VersionVector{"x": 2}
+	if got := copyVersions(x.GetVersions()); !reflect.DeepEqual(got, want) {
+		t.Errorf("LWWGraphImpl.GetVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestLWWGraphImpl_Merge_Conflicts(t *testing.T) {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+
+	addEdge := func(graph LWWGraph) {
+		clock := graph.GetClock().(*testCkock)
+		graph.AddEdge(NewLWWVertex(A, clock, graph.GetReplica()), NewLWWVertex(B, clock, graph.GetReplica()))
+	}
+
+	tests := []struct {
+		name    string
+		bias    Bias
+		operate func(x, y LWWGraph, xClock, yClock *testCkock)
+		want    []Conflict
+	}{
+		{
+			name: "test concurrent removal and add of the edge",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				addEdge(x)
+				y.Merge(x)
+				xClock.AddDuration(time.Minute)
+				x.RemoveEdgeByVertices(A, B)
+				yClock.SyncWith(xClock)
+				yClock.AddDuration(time.Minute)
+				y.RemoveEdgeByVertices(B, A)
+				addEdge(y)
+			},
+			want: []Conflict{{
+				Component:     EdgeOwner(A, B),
+				Local:         VersionVector{"x": 2},
+				Remote:        VersionVector{"x": 1, "y": 2},
+				LocalRemoved:  true,
+				RemoteRemoved: false,
+				Exists:        true,
+			}},
+		},
+		{
+			name: "test concurrent removal of the vertex and add of the edge",
+			bias: Removal,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				addEdge(x)
+				y.Merge(x)
+				yClock.SyncWith(xClock)
+				yClock.AddDuration(time.Minute)
+				addEdge(y)
+				xClock.SyncWith(yClock)
+				xClock.AddDuration(time.Minute)
+				x.RemoveVertex(B)
+			},
+			want: []Conflict{{
+				Component:     EdgeOwner(A, B),
+				Local:         VersionVector{"x": 2},
+				Remote:        VersionVector{"x": 1, "y": 1},
+				LocalRemoved:  true,
+				RemoteRemoved: false,
+				Exists:        false,
+			}},
+		},
+		{
+			name: "test concurrent adds of the vertices",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				x.AddVertex(B)
+				x.AddVertex(A)
+				y.AddVertex(A)
+			},
+			want: []Conflict{{
+				Component: VertexOwner(A),
+				Local:     VersionVector{"x": 1},
+				Remote:    VersionVector{"y": 1},
+				Exists:    true,
+			}},
+		},
+		{
+			name: "test update seen by the other",
+			bias: Adds,
+			operate: func(x, y LWWGraph, xClock, yClock *testCkock) {
+				addEdge(x)
+				y.Merge(x)
+				yClock.SyncWith(xClock)
+				yClock.AddDuration(time.Minute)
+				y.RemoveEdgeByVertices(A, B)
+				addEdge(y)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var got []Conflict
+			xClock, yClock := &testCkock{}, &testCkock{}
+			xClock.Now()
+			yClock.Now()
+			x := NewLWWGraph(tt.bias, xClock, "x", WithVersionVectors(func(conflicts []Conflict) {
+				got = append(got, conflicts...)
+			}))
+			y := NewLWWGraph(tt.bias, yClock, "y", WithVersionVectors(nil))
+
+			tt.operate(x, y, xClock, yClock)
+			x.Merge(y)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LWWGraphImpl.Merge() conflicts = %v, want %v, diff: %v", got, tt.want, deep.Equal(got, tt.want))
+			}
+
+			// the version vectors are joined, so the conflicts are reported once
+			got = nil
+			x.Merge(y)
+			if got != nil {
+				t.Errorf("LWWGraphImpl.Merge() conflicts = %v, want %v", got, nil)
+			}
+		})
+	}
+}
+
+// Check the version vectors of the replicas converge, and they are kept by the JSON, the
+// snapshot and the operations
+func TestLWWGraphImpl_Versions_Random_Operations(t *testing.T) {
+
+	values := newRandomValues(5)
+
+	for _, bias := range []Bias{Adds, Removal} {
+		for seed := int64(1); seed <= 5; seed++ {
+			t.Run(fmt.Sprintf("bias %v seed %v", bias, seed), func(t *testing.T) {
+
+				r := rand.New(rand.NewSource(seed))
+				conflicts := 0
+				replicas := []LWWGraph{}
+				for _, replica := range []ReplicaID{"x", "y", "z"} {
+					graph := NewLWWGraph(bias, &testCkock{}, replica, WithVersionVectors(func(c []Conflict) {
+						conflicts += len(c)
+					}))
+					graph.GetClock().Now()
+					replicas = append(replicas, graph)
+				}
+
+				for step := 0; step < 300; step++ {
+					applyRandomOperation(r, replicas, values)
+				}
+				if conflicts == 0 {
+					t.Errorf("LWWGraphImpl.Merge() conflicts = %v, want some", conflicts)
+				}
+
+				for _, graph := range replicas {
+					var buf bytes.Buffer
+					if err := WriteSnapshot(&buf, graph); err != nil {
+						t.Fatalf("WriteSnapshot() error = %v", err)
+					}
+					decoded, err := ReadSnapshot(&buf, &testCkock{})
+					if err != nil {
+						t.Fatalf("ReadSnapshot() error = %v", err)
+					}
+					if got, want := copyVersions(decoded.GetVersions()), copyVersions(graph.GetVersions()); !reflect.DeepEqual(got, want) {
+						t.Errorf("ReadSnapshot() versions = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+					}
+
+					data, err := json.Marshal(graph)
+					if err != nil {
+						t.Fatalf("LWWGraphImpl.MarshalJSON() error = %v", err)
+					}
+					unmarshaled := &LWWGraphImpl{}
+					if err := json.Unmarshal(data, unmarshaled); err != nil {
+						t.Fatalf("LWWGraphImpl.UnmarshalJSON() error = %v", err)
+					}
+					if got, want := copyVersions(unmarshaled.GetVersions()), copyVersions(graph.GetVersions()); !reflect.DeepEqual(got, want) {
+						t.Errorf("LWWGraphImpl.UnmarshalJSON() versions = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+					}
+
+					// the op-based replica sends the version vectors merged
+					ops := []Op{}
+					sender := NewOpLWWGraph(bias, &testCkock{}, "s", func(op Op) { ops = append(ops, op) })
+					receiver := NewOpLWWGraph(bias, &testCkock{}, "r", func(op Op) {})
+					sender.Merge(graph)
+					for _, op := range ops {
+						data, err := op.MarshalBinary()
+						if err != nil {
+							t.Fatalf("Op.MarshalBinary() error = %v", err)
+						}
+						var decoded Op
+						if err := decoded.UnmarshalBinary(data); err != nil {
+							t.Fatalf("Op.UnmarshalBinary() error = %v", err)
+						}
+						receiver.Apply(decoded)
+					}
+					if got, want := copyVersions(receiver.GetVersions()), copyVersions(graph.GetVersions()); !reflect.DeepEqual(got, want) {
+						t.Errorf("OpLWWGraph.Apply() versions = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+					}
+				}
+
+				for i := 0; i < 2; i++ {
+					for _, graph := range replicas {
+						for _, other := range replicas {
+							graph.Merge(other)
+						}
+					}
+				}
+
+				// the replicas merged with each other have seen every write
+				conflicts = 0
+				for _, graph := range replicas {
+					if got, want := copyVersions(graph.GetVersions()), copyVersions(replicas[0].GetVersions()); !reflect.DeepEqual(got, want) {
+						t.Errorf("%v: LWWGraphImpl.GetVersions() = %v, want %v, diff: %v", graph.GetReplica(), got, want, deep.Equal(got, want))
+					}
+					for _, other := range replicas {
+						graph.Merge(other)
+					}
+				}
+				if conflicts != 0 {
+					t.Errorf("LWWGraphImpl.Merge() conflicts = %v, want %v", conflicts, 0)
+				}
+			})
+		}
+	}
+}
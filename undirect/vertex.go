@@ -10,33 +10,70 @@ func NewVertexValue(v string) VertexValue {
 	return VertexValue(v)
 }
 
-type LWWVertex interface {
-	GetValue() VertexValue
+// LWWVertexOf is the record of the vertex of which the value is of the ID type
+type LWWVertexOf[ID comparable] interface {
+	GetValue() ID
 	GetTimestamp() int64
 	SetTimestamp(int64) int64
+	GetReplica() ReplicaID
+	SetReplica(ReplicaID) ReplicaID
 }
 
-type LWWVertexImpl struct {
-	value     VertexValue
+type LWWVertexImplOf[ID comparable] struct {
+	value     ID
 	timestamp int64
+	replica   ReplicaID
 }
 
-func NewLWWVertex(value VertexValue, clock Clock) LWWVertex {
-	return &LWWVertexImpl{
+// LWWVertex is the record of the vertex of the string value
+type LWWVertex = LWWVertexOf[VertexValue]
+
+type LWWVertexImpl = LWWVertexImplOf[VertexValue]
+
+func NewLWWVertexOf[ID comparable](value ID, clock Clock, replica ReplicaID) LWWVertexOf[ID] {
+	return &LWWVertexImplOf[ID]{
 		value:     value,
 		timestamp: clock.Now().UnixNano(),
+		replica:   replica,
 	}
 }
 
-func (vertex *LWWVertexImpl) GetValue() VertexValue {
+func NewLWWVertex(value VertexValue, clock Clock, replica ReplicaID) LWWVertex {
+	return NewLWWVertexOf(value, clock, replica)
+}
+
+func (vertex *LWWVertexImplOf[ID]) GetValue() ID {
 	return vertex.value
 }
 
-func (vertex *LWWVertexImpl) GetTimestamp() int64 {
+func (vertex *LWWVertexImplOf[ID]) GetTimestamp() int64 {
 	return vertex.timestamp
 }
 
-func (vertex *LWWVertexImpl) SetTimestamp(t int64) int64 {
+func (vertex *LWWVertexImplOf[ID]) SetTimestamp(t int64) int64 {
 	vertex.timestamp = t
 	return vertex.timestamp
 }
+
+func (vertex *LWWVertexImplOf[ID]) GetReplica() ReplicaID {
+	return vertex.replica
+}
+
+func (vertex *LWWVertexImplOf[ID]) SetReplica(r ReplicaID) ReplicaID {
+	vertex.replica = r
+	return vertex.replica
+}
+
+// copyVertex return a copy of the record of the vertex
+func copyVertex[ID comparable](vertex LWWVertexOf[ID]) LWWVertexOf[ID] {
+	return &LWWVertexImplOf[ID]{
+		value:     vertex.GetValue(),
+		timestamp: vertex.GetTimestamp(),
+		replica:   vertex.GetReplica(),
+	}
+}
+
+// CopyVertex return a copy of the record of the vertex of the string value
+func CopyVertex(vertex LWWVertex) LWWVertex {
+	return copyVertex(vertex)
+}
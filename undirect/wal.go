@@ -0,0 +1,1298 @@
+package undirect
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoggedLWWGraph is the graph that appends every mutation to the write-ahead log before
+// the mutation is applied, so the graph can be recovered after a crash by loading the
+// latest snapshot and replaying the log.
+//
+// The mutations are replayed with the same timestamps, every mutation takes one timestamp
+// from the clock, and the records written by it are stamped with that timestamp. The merge
+// only logs the records of the other graph that change the graph, and it is replayed only
+// when all of the records are in the log.
+//
+// The directory of the graph has the files of the generations, which are the snapshot and
+// the log written after it, Checkpoint writes the snapshot of the next generation and starts
+// a new log, the files of the previous generation are removed afterwards, so the graph is
+// recovered from either of the generations when the checkpoint is interrupted.
+//
+// The graph does not write the version vectors, the version vectors merged into it are
+// logged along with the records, so they are kept for the other replicas.
+//
+// The log is synced on every mutation. When the log can not be written, the mutation is not
+// applied, and the following mutations are ignored, the error is returned by Err.
+//
+// The graph is not safe for the concurrent use, it can be wrapped by NewConcurrentLWWGraph.
+type LoggedLWWGraph struct {
+	graph *LWWGraphImpl
+	clock *pinnedClock
+	dir   string
+	gen   uint64
+	log   *os.File
+	buf   []byte
+	err   error
+}
+
+const (
+	walAddVertex byte = iota + 1
+	walRemoveVertex
+	walAddEdge
+	walRemoveEdge
+	walGarbageCollect
+	walMergeVertex
+	walMergeTombstoneVertex
+	walMergeEdge
+	walMergeTombstoneEdge
+	walMergeCommit
+	walAddWeightedEdge
+	walSetProperty
+	walDeleteProperty
+	walMergeProperty
+	walMergeVersion
+)
+
+// isMergeRecord check the kind is one of the records of the merge before the commit
+func isMergeRecord(kind byte) bool {
+	return (kind >= walMergeVertex && kind < walMergeCommit) || kind == walMergeProperty || kind == walMergeVersion
+}
+
+const (
+	snapshotFile = "snapshot"
+	logFile      = "wal"
+)
+
+// ErrLogCorrupt is returned when the records of the log can not be replayed
+var ErrLogCorrupt = errors.New("undirect: log corrupt")
+
+// pinnedClock is the clock of the logged graph, the time is pinned to the timestamp
+// of the mutation while the mutation is applied
+type pinnedClock struct {
+	clock  Clock
+	pinned *time.Time
+}
+
+func (pc *pinnedClock) Now() time.Time {
+	if pc.pinned != nil {
+		return *pc.pinned
+	}
+	return pc.clock.Now()
+}
+
+func (pc *pinnedClock) Observe(timestamp int64) {
+	if clock, ok := pc.clock.(ObservingClock); ok {
+		clock.Observe(timestamp)
+	}
+}
+
+func (pc *pinnedClock) pin(timestamp int64) {
+	pinned := time.Unix(0, timestamp)
+	pc.pinned = &pinned
+}
+
+func (pc *pinnedClock) unpin() {
+	pc.pinned = nil
+}
+
+// OpenLoggedLWWGraph open the graph of the directory, the graph is recovered from the latest
+// snapshot and the log when there are, and the torn record at the end of the log is truncated,
+// ErrLogCorrupt is returned when the corrupt record is not the last one.
+// The bias, the bias of the edges given by WithEdgeBias and the replica should be the same as
+// the graph recovered, and the options are applied to the graph before it is recovered.
+func OpenLoggedLWWGraph(dir string, bias Bias, clockImpl Clock, replica ReplicaID, options ...Option) (*LoggedLWWGraph, error) {
+
+	if clockImpl == nil {
+		clockImpl = &clock{}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	gen, err := latestGeneration(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := &pinnedClock{clock: clockImpl}
+
+	graph := NewLWWGraph(bias, pinned, replica, options...).(*LWWGraphImpl)
+
+	if f, err := os.Open(generationFile(dir, snapshotFile, gen)); err == nil {
+		err := readLoggedSnapshot(f, graph)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	logged := &LoggedLWWGraph{
+		graph: graph,
+		clock: pinned,
+		dir:   dir,
+		gen:   gen,
+	}
+
+	logged.log, err = os.OpenFile(generationFile(dir, logFile, gen), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := logged.replay(logged.log)
+	if err != nil {
+		logged.log.Close()
+		return nil, err
+	}
+
+	// truncate the torn record, so the following records are appended after the valid ones
+	if err := logged.log.Truncate(valid); err != nil {
+		logged.log.Close()
+		return nil, err
+	}
+	if _, err := logged.log.Seek(valid, io.SeekStart); err != nil {
+		logged.log.Close()
+		return nil, err
+	}
+
+	pinned.Observe(latestTimestamp[VertexValue](graph))
+
+	if err := removeGenerations(dir, gen); err != nil {
+		logged.log.Close()
+		return nil, err
+	}
+
+	return logged, nil
+}
+
+// readLoggedSnapshot read the records of the snapshot into the graph, the biases and the
+// replica of the snapshot should be the same as the graph
+func readLoggedSnapshot(r io.Reader, graph *LWWGraphImpl) error {
+
+	sr, err := NewSnapshotReader(r)
+	if err != nil {
+		return err
+	}
+
+	if sr.Bias() != graph.bias || sr.EdgeBias() != graph.edgeBias || sr.Replica() != graph.replica {
+		return fmt.Errorf("undirect: snapshot of bias %v, edge bias %v and replica %q, want bias %v, edge bias %v and replica %q", sr.Bias(), sr.EdgeBias(), sr.Replica(), graph.bias, graph.edgeBias, graph.replica)
+	}
+
+	return readSnapshotRecords(sr, graph)
+}
+
+// Checkpoint write the snapshot of the graph and start a new log, the error is kept for the
+// following mutations like the error of writing the log
+func (graph *LoggedLWWGraph) Checkpoint() error {
+
+	if graph.err != nil {
+		return graph.err
+	}
+
+	if err := graph.checkpoint(); err != nil {
+		graph.err = err
+		return err
+	}
+
+	// the graph is still usable when the files of the previous generations are not removed,
+	// they are removed again by the next checkpoint
+	return removeGenerations(graph.dir, graph.gen)
+}
+
+func (graph *LoggedLWWGraph) checkpoint() error {
+
+	gen := graph.gen + 1
+	path := generationFile(graph.dir, snapshotFile, gen)
+
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if err := WriteSnapshot(f, graph.graph); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	log, err := os.OpenFile(generationFile(graph.dir, logFile, gen), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// the snapshot of the next generation takes place when it is renamed
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Close()
+		return err
+	}
+
+	// the new snapshot and log should be on the disk before the files of the previous
+	// generations are removed
+	if err := syncDir(graph.dir); err != nil {
+		log.Close()
+		return err
+	}
+
+	graph.log.Close()
+	graph.log = log
+	graph.gen = gen
+
+	return nil
+}
+
+// Close close the log, the graph should not be used afterwards
+func (graph *LoggedLWWGraph) Close() error {
+	return graph.log.Close()
+}
+
+// Err return the error of writing the log
+func (graph *LoggedLWWGraph) Err() error {
+	return graph.err
+}
+
+func (graph *LoggedLWWGraph) AddVertex(value VertexValue) LWWVertex {
+
+	timestamp := graph.clock.Now().UnixNano()
+
+	graph.buf = append(graph.buf[:0], walAddVertex)
+	graph.buf = appendString(graph.buf, string(value))
+	graph.buf = appendVarint(graph.buf, timestamp)
+
+	if !graph.append(graph.buf) {
+		return nil
+	}
+
+	graph.clock.pin(timestamp)
+	defer graph.clock.unpin()
+
+	return graph.graph.AddVertex(value)
+}
+
+func (graph *LoggedLWWGraph) RemoveVertex(value VertexValue) {
+
+	timestamp := graph.clock.Now().UnixNano()
+
+	graph.buf = append(graph.buf[:0], walRemoveVertex)
+	graph.buf = appendString(graph.buf, string(value))
+	graph.buf = appendVarint(graph.buf, timestamp)
+
+	if !graph.append(graph.buf) {
+		return
+	}
+
+	graph.clock.pin(timestamp)
+	defer graph.clock.unpin()
+
+	graph.graph.RemoveVertex(value)
+}
+
+func (graph *LoggedLWWGraph) AddEdge(v1, v2 LWWVertex) LWWEdge {
+
+	timestamp := graph.clock.Now().UnixNano()
+
+	graph.buf = append(graph.buf[:0], walAddEdge)
+	graph.buf = appendLogVertex(graph.buf, v1)
+	graph.buf = appendLogVertex(graph.buf, v2)
+	graph.buf = appendVarint(graph.buf, timestamp)
+
+	if !graph.append(graph.buf) {
+		return nil
+	}
+
+	graph.clock.pin(timestamp)
+	defer graph.clock.unpin()
+
+	return graph.graph.AddEdge(v1, v2)
+}
+
+func (graph *LoggedLWWGraph) AddWeightedEdge(v1, v2 LWWVertex, weight float64) LWWEdge {
+
+	timestamp := graph.clock.Now().UnixNano()
+
+	graph.buf = append(graph.buf[:0], walAddWeightedEdge)
+	graph.buf = appendLogVertex(graph.buf, v1)
+	graph.buf = appendLogVertex(graph.buf, v2)
+	graph.buf = appendFloat(graph.buf, weight)
+	graph.buf = appendVarint(graph.buf, timestamp)
+
+	if !graph.append(graph.buf) {
+		return nil
+	}
+
+	graph.clock.pin(timestamp)
+	defer graph.clock.unpin()
+
+	return graph.graph.AddWeightedEdge(v1, v2, weight)
+}
+
+func (graph *LoggedLWWGraph) RemoveEdgeByVertices(v1, v2 VertexValue) {
+
+	timestamp := graph.clock.Now().UnixNano()
+
+	graph.buf = append(graph.buf[:0], walRemoveEdge)
+	graph.buf = appendString(graph.buf, string(v1))
+	graph.buf = appendString(graph.buf, string(v2))
+	graph.buf = appendVarint(graph.buf, timestamp)
+
+	if !graph.append(graph.buf) {
+		return
+	}
+
+	graph.clock.pin(timestamp)
+	defer graph.clock.unpin()
+
+	graph.graph.RemoveEdgeByVertices(v1, v2)
+}
+
+func (graph *LoggedLWWGraph) GarbageCollect(stable int64) {
+
+	graph.buf = append(graph.buf[:0], walGarbageCollect)
+	graph.buf = appendVarint(graph.buf, stable)
+
+	if !graph.append(graph.buf) {
+		return
+	}
+
+	graph.graph.GarbageCollect(stable)
+}
+
+func (graph *LoggedLWWGraph) SetVertexProperty(value VertexValue, key, property string) LWWProperty {
+
+	if !graph.appendProperty(walSetProperty, VertexOwner(value), key, property) {
+		return nil
+	}
+	defer graph.clock.unpin()
+
+	return graph.graph.SetVertexProperty(value, key, property)
+}
+
+func (graph *LoggedLWWGraph) DeleteVertexProperty(value VertexValue, key string) {
+
+	if !graph.appendProperty(walDeleteProperty, VertexOwner(value), key, "") {
+		return
+	}
+	defer graph.clock.unpin()
+
+	graph.graph.DeleteVertexProperty(value, key)
+}
+
+func (graph *LoggedLWWGraph) SetEdgeProperty(v1, v2 VertexValue, key, property string) LWWProperty {
+
+	if !graph.appendProperty(walSetProperty, EdgeOwner(v1, v2), key, property) {
+		return nil
+	}
+	defer graph.clock.unpin()
+
+	return graph.graph.SetEdgeProperty(v1, v2, key, property)
+}
+
+func (graph *LoggedLWWGraph) DeleteEdgeProperty(v1, v2 VertexValue, key string) {
+
+	if !graph.appendProperty(walDeleteProperty, EdgeOwner(v1, v2), key, "") {
+		return
+	}
+	defer graph.clock.unpin()
+
+	graph.graph.DeleteEdgeProperty(v1, v2, key)
+}
+
+// appendProperty write the record of setting or deleting the property, the value is left
+// out for the deletion, and the clock is pinned to the timestamp of the record when it
+// return true, which should be unpinned after the mutation is applied
+func (graph *LoggedLWWGraph) appendProperty(kind byte, owner PropertyOwner, key, value string) bool {
+
+	timestamp := graph.clock.Now().UnixNano()
+
+	graph.buf = append(graph.buf[:0], kind)
+	graph.buf = appendPropertyOwner(graph.buf, owner)
+	graph.buf = appendString(graph.buf, key)
+	if kind == walSetProperty {
+		graph.buf = appendString(graph.buf, value)
+	}
+	graph.buf = appendVarint(graph.buf, timestamp)
+
+	if !graph.append(graph.buf) {
+		return false
+	}
+
+	graph.clock.pin(timestamp)
+
+	return true
+}
+
+// Merge log the records of the other graph that change the graph, followed by the commit
+// of the merge, the merge is not replayed when the commit is not in the log
+func (graph *LoggedLWWGraph) Merge(other LWWGraph) {
+	if changes, ok := graph.logMerge(other); ok {
+		graph.graph.Merge(changes)
+	}
+}
+
+// MergeWithReport log the merge like Merge, and return the changes, the remote records
+// of the report are of the other graph, not only of the records logged
+func (graph *LoggedLWWGraph) MergeWithReport(other LWWGraph) MergeReport {
+
+	changes, ok := graph.logMerge(other)
+	if !ok {
+		return MergeReport{}
+	}
+
+	report := graph.graph.MergeWithReport(changes)
+	records := newMergedRecords(other)
+	for i := range report.Changes {
+		report.Changes[i].RemoteTimestamp = latestRecordTimestamp(records.get(report.Changes[i].Component))
+	}
+	for i := range report.Conflicts {
+		report.Conflicts[i].RemoteRemoved = graph.graph.isRemoved(records.get(report.Conflicts[i].Component))
+	}
+
+	return report
+}
+
+// logMerge log the records of the other graph that change the graph, and return them when
+// the commit of the merge is logged
+func (graph *LoggedLWWGraph) logMerge(other LWWGraph) (*LWWGraphImpl, bool) {
+
+	if graph.err != nil {
+		return nil, false
+	}
+
+	graph.clock.Observe(latestTimestamp(other))
+
+	changes := mergeChanges(graph.graph, other)
+
+	var count uint64
+
+	for i, vertices := range []map[VertexValue]LWWVertex{changes.GetVertices(), changes.GetTombstoneVertices()} {
+		for _, v := range vertices {
+			graph.buf = append(graph.buf[:0], []byte{walMergeVertex, walMergeTombstoneVertex}[i])
+			graph.buf = appendLogVertex(graph.buf, v)
+			if !graph.append(graph.buf) {
+				return nil, false
+			}
+			count++
+		}
+	}
+
+	for i, matrix := range []map[VertexValue]map[VertexValue]LWWEdge{changes.GetEdgesMatrix(), changes.GetTombstoneEdgesMatrix()} {
+		for m := range matrix {
+			for n, e := range matrix[m] {
+				graph.buf = append(graph.buf[:0], []byte{walMergeEdge, walMergeTombstoneEdge}[i])
+				graph.buf = appendString(graph.buf, string(m))
+				graph.buf = appendString(graph.buf, string(n))
+				graph.buf = appendLogEdge(graph.buf, e)
+				if !graph.append(graph.buf) {
+					return nil, false
+				}
+				count++
+			}
+		}
+	}
+
+	for owner, properties := range changes.GetProperties() {
+		for key, p := range properties {
+			graph.buf = append(graph.buf[:0], walMergeProperty)
+			graph.buf = appendPropertyOwner(graph.buf, owner)
+			graph.buf = appendString(graph.buf, key)
+			graph.buf = appendLogProperty(graph.buf, p)
+			if !graph.append(graph.buf) {
+				return nil, false
+			}
+			count++
+		}
+	}
+
+	for component, vv := range changes.GetVersions() {
+		graph.buf = append(graph.buf[:0], walMergeVersion)
+		graph.buf = appendPropertyOwner(graph.buf, component)
+		graph.buf = appendVersionVector(graph.buf, vv)
+		if !graph.append(graph.buf) {
+			return nil, false
+		}
+		count++
+	}
+
+	if count == 0 {
+		return nil, false
+	}
+
+	graph.buf = append(graph.buf[:0], walMergeCommit)
+	graph.buf = appendUvarint(graph.buf, count)
+	if !graph.append(graph.buf) {
+		return nil, false
+	}
+
+	return changes, true
+}
+
+// mergeChanges return the records of the other graph that are after the records of the graph,
+// merging them is the same as merging the other graph
+func mergeChanges(graph *LWWGraphImpl, other LWWGraph) *LWWGraphImpl {
+
+	changes := NewLWWGraph(graph.bias, graph.clock, graph.replica, WithEdgeBias(graph.edgeBias), WithStorage(NewMapStorage())).(*LWWGraphImpl)
+
+	for _, pair := range []struct {
+		source, changes VertexStore
+		mergeWith       map[VertexValue]LWWVertex
+	}{
+		{graph.vertices, changes.vertices, other.GetVertices()},
+		{graph.tombstoneVertices, changes.tombstoneVertices, other.GetTombstoneVertices()},
+	} {
+		for k, v := range pair.mergeWith {
+			if v == nil {
+				continue
+			}
+			if s, ok := pair.source.Get(k); !ok || CompareComponents(s, v) < 0 {
+				pair.changes.Set(copyVertex(v))
+			}
+		}
+	}
+
+	for _, pair := range []struct {
+		source, changes EdgeStore
+		mergeWith       map[VertexValue]map[VertexValue]LWWEdge
+	}{
+		{graph.edgesMatrix, changes.edgesMatrix, other.GetEdgesMatrix()},
+		{graph.tombstoneEdgesMatrix, changes.tombstoneEdgesMatrix, other.GetTombstoneEdgesMatrix()},
+	} {
+		for m := range pair.mergeWith {
+			for n, e := range pair.mergeWith[m] {
+				if e == nil {
+					continue
+				}
+				if s, ok := pair.source.Get(m, n); !ok || CompareComponents(s, e) < 0 {
+					pair.changes.Set(m, n, copyEdge(e))
+				}
+			}
+		}
+	}
+
+	properties := other.GetProperties()
+	for owner := range properties {
+		for key, p := range properties[owner] {
+			if p == nil {
+				continue
+			}
+			if s, ok := graph.properties.Get(owner, key); !ok || compareProperties(s, p, graph.propertyBias(owner)) < 0 {
+				changes.properties.Set(owner, key, p)
+			}
+		}
+	}
+
+	for component, vv := range other.GetVersions() {
+		if vv == nil {
+			continue
+		}
+		local, _ := graph.versions.Get(component)
+		if order := local.Compare(vv); order == VersionBefore || order == VersionConcurrent {
+			changes.versions.Set(component, vv)
+		}
+	}
+
+	return changes
+}
+
+// append write the record to the log and sync it, it return false when the record is not
+// written, and the error is kept for the following mutations
+func (graph *LoggedLWWGraph) append(payload []byte) bool {
+
+	if graph.err != nil {
+		return false
+	}
+
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, payload); err != nil {
+		graph.err = err
+		return false
+	}
+
+	if _, err := graph.log.Write(buf.Bytes()); err != nil {
+		graph.err = err
+		return false
+	}
+
+	if err := graph.log.Sync(); err != nil {
+		graph.err = err
+		return false
+	}
+
+	return true
+}
+
+// replay apply the records of the log to the graph, and return the offset after the
+// last record that is applied, the records after it are the torn records of the crash,
+// ErrLogCorrupt is returned when the corrupt record is followed by the other records
+func (graph *LoggedLWWGraph) replay(r io.Reader) (int64, error) {
+
+	var (
+		frames  = newFrameReader(r, 0)
+		valid   int64
+		changes *LWWGraphImpl
+		count   uint64
+	)
+
+	for {
+		payload, err := frames.next()
+		if err == io.EOF || err == errFrameTruncated || err == errFrameCorrupt && frames.tail() {
+			return valid, nil
+		}
+		if err == errFrameCorrupt {
+			// the records after it are committed, so they are not dropped as the torn records
+			return 0, ErrLogCorrupt
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if changes == nil {
+			changes = NewLWWGraph(graph.graph.bias, graph.clock, graph.graph.replica, WithEdgeBias(graph.graph.edgeBias), WithStorage(NewMapStorage())).(*LWWGraphImpl)
+		}
+
+		merging, err := graph.apply(payload, changes, &count)
+		if err != nil {
+			return 0, err
+		}
+
+		if !merging {
+			valid = frames.offset
+			changes, count = nil, 0
+		}
+	}
+}
+
+// apply apply the record of the log to the graph, the records of merge are collected into
+// changes until the commit, and it return true when the merge is not committed yet
+func (graph *LoggedLWWGraph) apply(payload []byte, changes *LWWGraphImpl, count *uint64) (bool, error) {
+
+	d := &payloadDecoder{buf: payload}
+
+	kind, err := d.byte()
+	if err != nil {
+		return false, ErrLogCorrupt
+	}
+
+	if *count > 0 && !isMergeRecord(kind) && kind != walMergeCommit {
+		return false, ErrLogCorrupt
+	}
+
+	var timestamp int64
+
+	switch kind {
+	case walAddVertex, walRemoveVertex:
+		value, err := d.string()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		if timestamp, err = d.varint(); err != nil {
+			return false, ErrLogCorrupt
+		}
+		graph.clock.pin(timestamp)
+		defer graph.clock.unpin()
+		if kind == walAddVertex {
+			graph.graph.AddVertex(VertexValue(value))
+		} else {
+			graph.graph.RemoveVertex(VertexValue(value))
+		}
+	case walAddEdge:
+		v1, err := decodeLogVertex(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		v2, err := decodeLogVertex(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		if timestamp, err = d.varint(); err != nil {
+			return false, ErrLogCorrupt
+		}
+		graph.clock.pin(timestamp)
+		defer graph.clock.unpin()
+		graph.graph.AddEdge(v1, v2)
+	case walAddWeightedEdge:
+		v1, err := decodeLogVertex(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		v2, err := decodeLogVertex(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		weight, err := d.float()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		if timestamp, err = d.varint(); err != nil {
+			return false, ErrLogCorrupt
+		}
+		graph.clock.pin(timestamp)
+		defer graph.clock.unpin()
+		graph.graph.AddWeightedEdge(v1, v2, weight)
+	case walRemoveEdge:
+		v1, err := d.string()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		v2, err := d.string()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		if timestamp, err = d.varint(); err != nil {
+			return false, ErrLogCorrupt
+		}
+		graph.clock.pin(timestamp)
+		defer graph.clock.unpin()
+		graph.graph.RemoveEdgeByVertices(VertexValue(v1), VertexValue(v2))
+	case walGarbageCollect:
+		stable, err := d.varint()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		graph.graph.GarbageCollect(stable)
+	case walMergeVertex, walMergeTombstoneVertex:
+		v, err := decodeLogVertex(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		if kind == walMergeVertex {
+			changes.vertices.Set(v)
+		} else {
+			changes.tombstoneVertices.Set(v)
+		}
+		*count++
+		return true, d.done()
+	case walMergeEdge, walMergeTombstoneEdge:
+		m, err := d.string()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		n, err := d.string()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		e, err := decodeLogEdge(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		if kind == walMergeEdge {
+			changes.edgesMatrix.Set(VertexValue(m), VertexValue(n), e)
+		} else {
+			changes.tombstoneEdgesMatrix.Set(VertexValue(m), VertexValue(n), e)
+		}
+		*count++
+		return true, d.done()
+	case walSetProperty, walDeleteProperty:
+		owner, err := decodePropertyOwner(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		key, err := d.string()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		var value string
+		if kind == walSetProperty {
+			if value, err = d.string(); err != nil {
+				return false, ErrLogCorrupt
+			}
+		}
+		if timestamp, err = d.varint(); err != nil {
+			return false, ErrLogCorrupt
+		}
+		graph.clock.pin(timestamp)
+		defer graph.clock.unpin()
+		if kind == walSetProperty {
+			graph.graph.setProperty(owner, key, NewLWWProperty(value, false, graph.clock, graph.graph.replica))
+		} else {
+			graph.graph.deleteProperty(owner, key)
+		}
+	case walMergeProperty:
+		owner, err := decodePropertyOwner(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		key, err := d.string()
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		p, err := decodeLogProperty(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		changes.properties.Set(owner, key, p)
+		*count++
+		return true, d.done()
+	case walMergeVersion:
+		component, err := decodePropertyOwner(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		vv, err := decodeVersionVector(d)
+		if err != nil {
+			return false, ErrLogCorrupt
+		}
+		changes.versions.Set(component, vv)
+		*count++
+		return true, d.done()
+	case walMergeCommit:
+		committed, err := d.uvarint()
+		if err != nil || committed != *count {
+			return false, ErrLogCorrupt
+		}
+		graph.graph.Merge(changes)
+	default:
+		return false, ErrLogCorrupt
+	}
+
+	if err := d.done(); err != nil {
+		return false, ErrLogCorrupt
+	}
+
+	return false, nil
+}
+
+func appendLogVertex(buf []byte, vertex LWWVertex) []byte {
+	buf = appendString(buf, string(vertex.GetValue()))
+	buf = appendVarint(buf, vertex.GetTimestamp())
+	return appendString(buf, string(vertex.GetReplica()))
+}
+
+func appendLogEdge(buf []byte, edge LWWEdge) []byte {
+	vertices := edge.GetVertices()
+	buf = appendLogVertex(buf, vertices[0])
+	buf = appendLogVertex(buf, vertices[1])
+	buf = appendVarint(buf, edge.GetTimestamp())
+	buf = appendString(buf, string(edge.GetReplica()))
+	return appendWeight(buf, edge)
+}
+
+func decodeLogVertex(d *payloadDecoder) (LWWVertex, error) {
+
+	value, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+
+	replica, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LWWVertexImpl{
+		value:     VertexValue(value),
+		timestamp: timestamp,
+		replica:   ReplicaID(replica),
+	}, nil
+}
+
+func decodeLogEdge(d *payloadDecoder) (LWWEdge, error) {
+
+	v1, err := decodeLogVertex(d)
+	if err != nil {
+		return nil, err
+	}
+
+	v2, err := decodeLogVertex(d)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+
+	replica, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+
+	weight, err := d.weight()
+	if err != nil {
+		return nil, err
+	}
+
+	vertices := []LWWVertex{v1, v2}
+
+	return &LWWEdgeImpl{
+		vertices:  &vertices,
+		timestamp: timestamp,
+		replica:   ReplicaID(replica),
+		weight:    weight,
+	}, nil
+}
+
+// appendPropertyOwner append the flag of the edge followed by the values of the owner
+func appendPropertyOwner(buf []byte, owner PropertyOwner) []byte {
+	if !owner.Edge {
+		return appendString(append(buf, 0), string(owner.Vertices[0]))
+	}
+	buf = appendString(append(buf, 1), string(owner.Vertices[0]))
+	return appendString(buf, string(owner.Vertices[1]))
+}
+
+func appendLogProperty(buf []byte, property LWWProperty) []byte {
+	buf = appendString(buf, property.GetValue())
+	if property.IsDeleted() {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = appendVarint(buf, property.GetTimestamp())
+	return appendString(buf, string(property.GetReplica()))
+}
+
+func decodePropertyOwner(d *payloadDecoder) (PropertyOwner, error) {
+
+	flag, err := d.byte()
+	if err != nil {
+		return PropertyOwner{}, err
+	}
+	if flag > 1 {
+		return PropertyOwner{}, errFrameCorrupt
+	}
+
+	v1, err := d.string()
+	if err != nil {
+		return PropertyOwner{}, err
+	}
+	if flag == 0 {
+		return VertexOwner(VertexValue(v1)), nil
+	}
+
+	v2, err := d.string()
+	if err != nil {
+		return PropertyOwner{}, err
+	}
+	// the vertices of the edge are written sorted
+	if v1 >= v2 {
+		return PropertyOwner{}, errFrameCorrupt
+	}
+
+	return EdgeOwner(VertexValue(v1), VertexValue(v2)), nil
+}
+
+func decodeLogProperty(d *payloadDecoder) (LWWProperty, error) {
+
+	value, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if deleted > 1 {
+		return nil, errFrameCorrupt
+	}
+
+	timestamp, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+
+	replica, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LWWPropertyImpl{
+		value:     value,
+		deleted:   deleted == 1,
+		timestamp: timestamp,
+		replica:   ReplicaID(replica),
+	}, nil
+}
+
+// appendVersionVector append the numbers of the replicas sorted by the replicas
+func appendVersionVector(buf []byte, vv VersionVector) []byte {
+
+	replicas := make([]ReplicaID, 0, len(vv))
+	for replica := range vv {
+		replicas = append(replicas, replica)
+	}
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i] < replicas[j]
+	})
+
+	buf = appendUvarint(buf, uint64(len(replicas)))
+	for _, replica := range replicas {
+		buf = appendString(buf, string(replica))
+		buf = appendUvarint(buf, vv[replica])
+	}
+
+	return buf
+}
+
+func decodeVersionVector(d *payloadDecoder) (VersionVector, error) {
+
+	size, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	vv := VersionVector{}
+
+	var last string
+	for i := uint64(0); i < size; i++ {
+		replica, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		// the replicas are written sorted, so every replica is written once
+		if i > 0 && replica <= last {
+			return nil, errFrameCorrupt
+		}
+		last = replica
+		n, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		vv[ReplicaID(replica)] = n
+	}
+
+	return vv, nil
+}
+
+func generationFile(dir, name string, gen uint64) string {
+	return filepath.Join(dir, name+"."+strconv.FormatUint(gen, 10))
+}
+
+// latestGeneration return the generation of the latest snapshot, or zero when there is no snapshot
+func latestGeneration(dir string) (uint64, error) {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint64
+
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), snapshotFile+".") {
+			continue
+		}
+		gen, err := strconv.ParseUint(strings.TrimPrefix(entry.Name(), snapshotFile+"."), 10, 64)
+		if err != nil {
+			continue
+		}
+		if gen > latest {
+			latest = gen
+		}
+	}
+
+	return latest, nil
+}
+
+// removeGenerations remove the files of the generations before gen and the snapshots not
+// renamed, the first error is returned after the other files are removed
+func removeGenerations(dir string, gen uint64) error {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	var first error
+
+	for _, name := range names {
+		for _, prefix := range []string{snapshotFile + ".", logFile + "."} {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			suffix := strings.TrimPrefix(name, prefix)
+			if g, err := strconv.ParseUint(suffix, 10, 64); !strings.HasSuffix(suffix, ".tmp") && (err != nil || g >= gen) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) && first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
+
+// syncDir sync the directory, so the files created, renamed or removed in it are on the disk
+func syncDir(dir string) error {
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (graph *LoggedLWWGraph) IsVertexExist(value VertexValue) bool {
+	return graph.graph.IsVertexExist(value)
+}
+
+func (graph *LoggedLWWGraph) GetVertex(value VertexValue) LWWVertex {
+	return graph.graph.GetVertex(value)
+}
+
+func (graph *LoggedLWWGraph) GetEdge(v1, v2 VertexValue) LWWEdge {
+	return graph.graph.GetEdge(v1, v2)
+}
+
+func (graph *LoggedLWWGraph) GetEdges(value VertexValue) []LWWEdge {
+	return graph.graph.GetEdges(value)
+}
+
+func (graph *LoggedLWWGraph) GetPaths(start, end VertexValue) [][]VertexValue {
+	return graph.graph.GetPaths(start, end)
+}
+
+func (graph *LoggedLWWGraph) WalkPaths(ctx context.Context, start, end VertexValue, maxLength, maxResults int, f func(path []VertexValue) bool) error {
+	return graph.graph.WalkPaths(ctx, start, end, maxLength, maxResults, f)
+}
+
+func (graph *LoggedLWWGraph) ShortestPath(start, end VertexValue) ([]VertexValue, float64) {
+	return graph.graph.ShortestPath(start, end)
+}
+
+func (graph *LoggedLWWGraph) ShortestPathHops(start, end VertexValue) []VertexValue {
+	return graph.graph.ShortestPathHops(start, end)
+}
+
+func (graph *LoggedLWWGraph) Distances(from VertexValue) map[VertexValue]int {
+	return graph.graph.Distances(from)
+}
+
+func (graph *LoggedLWWGraph) ConnectedComponents() [][]VertexValue {
+	return graph.graph.ConnectedComponents()
+}
+
+func (graph *LoggedLWWGraph) ComponentOf(value VertexValue) []VertexValue {
+	return graph.graph.ComponentOf(value)
+}
+
+func (graph *LoggedLWWGraph) IsReachable(v1, v2 VertexValue) bool {
+	return graph.graph.IsReachable(v1, v2)
+}
+
+func (graph *LoggedLWWGraph) HasCycle() bool {
+	return graph.graph.HasCycle()
+}
+
+func (graph *LoggedLWWGraph) FindCycle() []VertexValue {
+	return graph.graph.FindCycle()
+}
+
+func (graph *LoggedLWWGraph) SpanningForest(mode SpanningMode) [][2]VertexValue {
+	return graph.graph.SpanningForest(mode)
+}
+
+func (graph *LoggedLWWGraph) Bridges() [][2]VertexValue {
+	return graph.graph.Bridges()
+}
+
+func (graph *LoggedLWWGraph) ArticulationPoints() []VertexValue {
+	return graph.graph.ArticulationPoints()
+}
+
+func (graph *LoggedLWWGraph) GetAdjacencyVerticesList() map[VertexValue][]VertexValue {
+	return graph.graph.GetAdjacencyVerticesList()
+}
+
+func (graph *LoggedLWWGraph) IsComponentExist(add, remove Component) bool {
+	return graph.graph.IsComponentExist(add, remove)
+}
+
+func (graph *LoggedLWWGraph) GetConnectedVertices(value VertexValue) []LWWVertex {
+	return graph.graph.GetConnectedVertices(value)
+}
+
+func (graph *LoggedLWWGraph) Delta(since int64) LWWGraph {
+	return graph.graph.Delta(since)
+}
+
+func (graph *LoggedLWWGraph) GetBias() Bias {
+	return graph.graph.GetBias()
+}
+
+func (graph *LoggedLWWGraph) GetEdgeBias() Bias {
+	return graph.graph.GetEdgeBias()
+}
+
+func (graph *LoggedLWWGraph) GetClock() Clock {
+	return graph.graph.GetClock()
+}
+
+func (graph *LoggedLWWGraph) GetReplica() ReplicaID {
+	return graph.graph.GetReplica()
+}
+
+func (graph *LoggedLWWGraph) GetVertices() map[VertexValue]LWWVertex {
+	return graph.graph.GetVertices()
+}
+
+func (graph *LoggedLWWGraph) GetTombstoneVertices() map[VertexValue]LWWVertex {
+	return graph.graph.GetTombstoneVertices()
+}
+
+func (graph *LoggedLWWGraph) GetEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
+	return graph.graph.GetEdgesMatrix()
+}
+
+func (graph *LoggedLWWGraph) GetTombstoneEdgesMatrix() map[VertexValue]map[VertexValue]LWWEdge {
+	return graph.graph.GetTombstoneEdgesMatrix()
+}
+
+func (graph *LoggedLWWGraph) GetProperties() map[PropertyOwner]map[string]LWWProperty {
+	return graph.graph.GetProperties()
+}
+
+func (graph *LoggedLWWGraph) GetVersions() map[PropertyOwner]VersionVector {
+	return graph.graph.GetVersions()
+}
+
+func (graph *LoggedLWWGraph) GetVertexProperty(value VertexValue, key string) (string, bool) {
+	return graph.graph.GetVertexProperty(value, key)
+}
+
+func (graph *LoggedLWWGraph) GetVertexProperties(value VertexValue) map[string]string {
+	return graph.graph.GetVertexProperties(value)
+}
+
+func (graph *LoggedLWWGraph) GetEdgeProperty(v1, v2 VertexValue, key string) (string, bool) {
+	return graph.graph.GetEdgeProperty(v1, v2, key)
+}
+
+func (graph *LoggedLWWGraph) GetEdgeProperties(v1, v2 VertexValue) map[string]string {
+	return graph.graph.GetEdgeProperties(v1, v2)
+}
@@ -0,0 +1,431 @@
+package undirect
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+// walOperation is the mutation of the logged graph, the clock is the clock of the logged
+// graph, and other is the replica for the merges
+type walOperation func(graph LWWGraph, clock *testCkock, other LWWGraph)
+
+func newWALOperations() []walOperation {
+
+	A := NewVertexValue("A")
+	B := NewVertexValue("B")
+	C := NewVertexValue("C")
+	D := NewVertexValue("D")
+
+	addEdge := func(v1, v2 VertexValue) walOperation {
+		return func(graph LWWGraph, clock *testCkock, other LWWGraph) {
+			graph.AddEdge(NewLWWVertex(v1, clock, graph.GetReplica()), NewLWWVertex(v2, clock, graph.GetReplica()))
+		}
+	}
+	otherAddEdge := func(v1, v2 VertexValue) walOperation {
+		return func(graph LWWGraph, clock *testCkock, other LWWGraph) {
+			otherClock := other.GetClock().(*testCkock)
+			otherClock.AddDuration(time.Second)
+			other.AddEdge(NewLWWVertex(v1, otherClock, other.GetReplica()), NewLWWVertex(v2, otherClock, other.GetReplica()))
+		}
+	}
+
+	return []walOperation{
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.AddVertex(A) },
+		addEdge(A, B),
+		addEdge(B, C),
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.AddVertex(A) },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.RemoveEdgeByVertices(A, B) },
+		otherAddEdge(C, D),
+		otherAddEdge(D, A),
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.Merge(other) },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.RemoveVertex(C) },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.Merge(other) },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) {
+			graph.GarbageCollect(clock.Now().Add(-2 * time.Second).UnixNano())
+		},
+		addEdge(A, C),
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) {
+			graph.AddWeightedEdge(NewLWWVertex(A, clock, graph.GetReplica()), NewLWWVertex(B, clock, graph.GetReplica()), 2.5)
+		},
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.SetVertexProperty(A, "name", "a") },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.SetEdgeProperty(B, A, "kind", "link") },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { other.SetVertexProperty(D, "name", "d") },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.DeleteVertexProperty(A, "name") },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { other.RemoveVertex(D) },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.Merge(other) },
+		func(graph LWWGraph, clock *testCkock, other LWWGraph) { graph.RemoveVertex(D) },
+	}
+}
+
+// frozenStateOf return the state of the copy of the graph, so the state is not changed by
+// the following mutations
+func frozenStateOf(graph LWWGraph) map[string]interface{} {
+	return stateOf(graph.Delta(math.MinInt64))
+}
+
+func openTestLoggedGraph(t *testing.T, dir string, clock *testCkock) *LoggedLWWGraph {
+	t.Helper()
+	graph, err := OpenLoggedLWWGraph(dir, Adds, clock, "x")
+	if err != nil {
+		t.Fatalf("OpenLoggedLWWGraph() error = %v", err)
+	}
+	return graph
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir() error = %v", err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOpenLoggedLWWGraph_Recover(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		checkpoint int
+		wantFiles  []string
+	}{
+		{name: "log only", checkpoint: -1, wantFiles: []string{"wal.0"}},
+		{name: "snapshot and log", checkpoint: 6, wantFiles: []string{"snapshot.1", "wal.1"}},
+		{name: "snapshot only", checkpoint: len(newWALOperations()), wantFiles: []string{"snapshot.1", "wal.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			dir := t.TempDir()
+			clock := &testCkock{}
+			graph := openTestLoggedGraph(t, dir, clock)
+			other := NewLWWGraph(Adds, &testCkock{}, "y", WithVersionVectors(nil))
+
+			for i, op := range newWALOperations() {
+				if i == tt.checkpoint {
+					if err := graph.Checkpoint(); err != nil {
+						t.Fatalf("LoggedLWWGraph.Checkpoint() error = %v", err)
+					}
+				}
+				clock.AddDuration(time.Second)
+				op(graph, clock, other)
+			}
+			if tt.checkpoint == len(newWALOperations()) {
+				if err := graph.Checkpoint(); err != nil {
+					t.Fatalf("LoggedLWWGraph.Checkpoint() error = %v", err)
+				}
+			}
+			if err := graph.Err(); err != nil {
+				t.Fatalf("LoggedLWWGraph.Err() = %v", err)
+			}
+
+			want := frozenStateOf(graph)
+			graph.Close()
+
+			if got := listDir(t, dir); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("LoggedLWWGraph files = %v, want %v", got, tt.wantFiles)
+			}
+
+			// the clock observing the timestamps is advanced to the records recovered
+			recovered, err := OpenLoggedLWWGraph(dir, Adds, NewHLC(&testCkock{}), "x")
+			if err != nil {
+				t.Fatalf("OpenLoggedLWWGraph() error = %v", err)
+			}
+			defer recovered.Close()
+
+			if got := frozenStateOf(recovered); !reflect.DeepEqual(got, want) {
+				t.Errorf("OpenLoggedLWWGraph() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+			}
+
+			if got, latest := recovered.GetClock().Now().UnixNano(), latestTimestamp[VertexValue](recovered); got < latest {
+				t.Errorf("OpenLoggedLWWGraph() clock = %v, want at least %v", got, latest)
+			}
+		})
+	}
+}
+
+// Check the graph of the separate bias of the edges is reopened with the same bias, and the
+// snapshot of the other bias of the edges is rejected
+func TestOpenLoggedLWWGraph_Edge_Bias(t *testing.T) {
+
+	for _, checkpoint := range []bool{false, true} {
+		t.Run(fmt.Sprintf("checkpoint %v", checkpoint), func(t *testing.T) {
+
+			dir := t.TempDir()
+			clock := &testCkock{}
+			graph, err := OpenLoggedLWWGraph(dir, Adds, clock, "x", WithEdgeBias(Removal))
+			if err != nil {
+				t.Fatalf("OpenLoggedLWWGraph() error = %v", err)
+			}
+			other := NewLWWGraph(Adds, &testCkock{}, "y", WithEdgeBias(Removal), WithVersionVectors(nil))
+
+			for _, op := range newWALOperations() {
+				clock.AddDuration(time.Second)
+				op(graph, clock, other)
+			}
+			if checkpoint {
+				if err := graph.Checkpoint(); err != nil {
+					t.Fatalf("LoggedLWWGraph.Checkpoint() error = %v", err)
+				}
+			}
+			want := frozenStateOf(graph)
+			graph.Close()
+
+			recovered, err := OpenLoggedLWWGraph(dir, Adds, &testCkock{}, "x", WithEdgeBias(Removal))
+			if err != nil {
+				t.Fatalf("OpenLoggedLWWGraph() error = %v", err)
+			}
+			defer recovered.Close()
+
+			if got := recovered.GetEdgeBias(); got != Removal {
+				t.Errorf("LoggedLWWGraph.GetEdgeBias() = %v, want %v", got, Removal)
+			}
+			if got := frozenStateOf(recovered); !reflect.DeepEqual(got, want) {
+				t.Errorf("OpenLoggedLWWGraph() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+			}
+
+			if !checkpoint {
+				return
+			}
+			if _, err := OpenLoggedLWWGraph(dir, Adds, &testCkock{}, "x"); err == nil {
+				t.Errorf("OpenLoggedLWWGraph() of the other bias of the edges error = nil, want the error")
+			}
+		})
+	}
+}
+
+// Check the graph recovered from the log cut at every offset is the graph of a prefix of the
+// operations, and the torn record is truncated
+func TestOpenLoggedLWWGraph_Crash_At_Every_Offset(t *testing.T) {
+
+	dir := t.TempDir()
+	clock := &testCkock{}
+	graph := openTestLoggedGraph(t, dir, clock)
+	other := NewLWWGraph(Adds, &testCkock{}, "y", WithVersionVectors(nil))
+
+	path := generationFile(dir, logFile, 0)
+
+	// the size of the log and the state of the graph after every prefix of the operations
+	sizes := []int64{0}
+	states := []map[string]interface{}{frozenStateOf(graph)}
+
+	for _, op := range newWALOperations() {
+		clock.AddDuration(time.Second)
+		op(graph, clock, other)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("os.Stat() error = %v", err)
+		}
+		if info.Size() == sizes[len(sizes)-1] {
+			// the operation changes nothing, so nothing is logged
+			continue
+		}
+		sizes = append(sizes, info.Size())
+		states = append(states, frozenStateOf(graph))
+	}
+	graph.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	for offset := 0; offset <= len(data); offset++ {
+
+		crashed := filepath.Join(t.TempDir(), "graph")
+		if err := os.MkdirAll(crashed, 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(generationFile(crashed, logFile, 0), data[:offset], 0o644); err != nil {
+			t.Fatalf("os.WriteFile() error = %v", err)
+		}
+
+		recovered := openTestLoggedGraph(t, crashed, &testCkock{})
+		got := frozenStateOf(recovered)
+		recovered.Close()
+
+		// the longest prefix of the operations in the log
+		prefix := 0
+		for i, size := range sizes {
+			if size <= int64(offset) {
+				prefix = i
+			}
+		}
+
+		if want := states[prefix]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("OpenLoggedLWWGraph() of log cut at %v = %v, want %v operations %v, diff: %v", offset, got, prefix, want, deep.Equal(got, want))
+		}
+
+		info, err := os.Stat(generationFile(crashed, logFile, 0))
+		if err != nil {
+			t.Fatalf("os.Stat() error = %v", err)
+		}
+		if info.Size() != sizes[prefix] {
+			t.Fatalf("OpenLoggedLWWGraph() of log cut at %v truncated the log to %v, want %v", offset, info.Size(), sizes[prefix])
+		}
+	}
+}
+
+// Check the corrupt record followed by the other records is reported, and the log is not
+// truncated
+func TestOpenLoggedLWWGraph_Corrupt_Middle(t *testing.T) {
+
+	dir := t.TempDir()
+	clock := &testCkock{}
+	graph := openTestLoggedGraph(t, dir, clock)
+	other := NewLWWGraph(Adds, &testCkock{}, "y", WithVersionVectors(nil))
+
+	path := generationFile(dir, logFile, 0)
+
+	clock.AddDuration(time.Second)
+	newWALOperations()[0](graph, clock, other)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+	size := info.Size()
+
+	for _, op := range newWALOperations()[1:] {
+		clock.AddDuration(time.Second)
+		op(graph, clock, other)
+	}
+	graph.Close()
+
+	// flip the last byte of the checksum of the first record
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	data[size-1] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	if _, err := OpenLoggedLWWGraph(dir, Adds, &testCkock{}, "x"); err != ErrLogCorrupt {
+		t.Errorf("OpenLoggedLWWGraph() error = %v, want %v", err, ErrLogCorrupt)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != int64(len(data)) {
+		t.Errorf("OpenLoggedLWWGraph() truncated the log to %v, want %v", info.Size(), len(data))
+	}
+}
+
+// Check the error of the checkpoint is kept, so the following mutations are not written
+func TestLoggedLWWGraph_Checkpoint_Error(t *testing.T) {
+
+	dir := t.TempDir()
+	clock := &testCkock{}
+	graph := openTestLoggedGraph(t, dir, clock)
+	defer graph.Close()
+
+	clock.AddDuration(time.Second)
+	graph.AddVertex(NewVertexValue("A"))
+
+	// the snapshot of the next generation can not be created
+	if err := os.Mkdir(generationFile(dir, snapshotFile, 1)+".tmp", 0o755); err != nil {
+		t.Fatalf("os.Mkdir() error = %v", err)
+	}
+
+	err := graph.Checkpoint()
+	if err == nil {
+		t.Fatalf("LoggedLWWGraph.Checkpoint() error = nil, want the error")
+	}
+	if got := graph.Err(); got != err {
+		t.Errorf("LoggedLWWGraph.Err() = %v, want %v", got, err)
+	}
+
+	clock.AddDuration(time.Second)
+	if got := graph.AddVertex(NewVertexValue("B")); got != nil {
+		t.Errorf("LoggedLWWGraph.AddVertex() = %v, want nil", got)
+	}
+}
+
+// Check the error of removing the files of the previous generations is returned, and the graph
+// is still usable after it
+func TestLoggedLWWGraph_Checkpoint_Remove_Error(t *testing.T) {
+
+	dir := t.TempDir()
+	clock := &testCkock{}
+	graph := openTestLoggedGraph(t, dir, clock)
+	defer graph.Close()
+
+	// the snapshot not renamed can not be removed, as it is the directory not empty
+	if err := os.MkdirAll(filepath.Join(generationFile(dir, snapshotFile, 0)+".tmp", "x"), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() error = %v", err)
+	}
+
+	clock.AddDuration(time.Second)
+	graph.AddVertex(NewVertexValue("A"))
+
+	if err := graph.Checkpoint(); err == nil {
+		t.Errorf("LoggedLWWGraph.Checkpoint() error = nil, want the error")
+	}
+	if err := graph.Err(); err != nil {
+		t.Errorf("LoggedLWWGraph.Err() = %v, want nil", err)
+	}
+
+	clock.AddDuration(time.Second)
+	if got := graph.AddVertex(NewVertexValue("B")); got == nil {
+		t.Errorf("LoggedLWWGraph.AddVertex() = nil, want the vertex")
+	}
+	if got, want := listDir(t, dir), []string{"snapshot.0.tmp", "snapshot.1", "wal.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoggedLWWGraph files = %v, want %v", got, want)
+	}
+}
+
+// Check the graph recovered from the checkpoint interrupted is the same as the graph
+func TestOpenLoggedLWWGraph_Interrupted_Checkpoint(t *testing.T) {
+
+	dir := t.TempDir()
+	clock := &testCkock{}
+	graph := openTestLoggedGraph(t, dir, clock)
+	other := NewLWWGraph(Adds, &testCkock{}, "y")
+
+	for _, op := range newWALOperations() {
+		clock.AddDuration(time.Second)
+		op(graph, clock, other)
+	}
+	want := frozenStateOf(graph)
+	graph.Close()
+
+	// the snapshot of the next generation is not renamed
+	if err := os.WriteFile(generationFile(dir, snapshotFile, 1)+".tmp", []byte(snapshotMagic), 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	recovered := openTestLoggedGraph(t, dir, &testCkock{})
+	if got := frozenStateOf(recovered); !reflect.DeepEqual(got, want) {
+		t.Errorf("OpenLoggedLWWGraph() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+
+	// the snapshot is renamed, but the files of the previous generation are not removed
+	if err := recovered.Checkpoint(); err != nil {
+		t.Fatalf("LoggedLWWGraph.Checkpoint() error = %v", err)
+	}
+	recovered.Close()
+	if err := os.WriteFile(generationFile(dir, logFile, 0), []byte{}, 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	recovered = openTestLoggedGraph(t, dir, &testCkock{})
+	defer recovered.Close()
+	if got := frozenStateOf(recovered); !reflect.DeepEqual(got, want) {
+		t.Errorf("OpenLoggedLWWGraph() = %v, want %v, diff: %v", got, want, deep.Equal(got, want))
+	}
+	if got, wantFiles := listDir(t, dir), []string{"snapshot.1", "wal.1"}; !reflect.DeepEqual(got, wantFiles) {
+		t.Errorf("LoggedLWWGraph files = %v, want %v", got, wantFiles)
+	}
+}